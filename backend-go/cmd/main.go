@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,15 +14,23 @@ import (
 	"time"
 
 	"github.com/banking-audit-ledger/backend/internal/api"
+	"github.com/banking-audit-ledger/backend/internal/auth"
 	"github.com/banking-audit-ledger/backend/internal/config"
 	"github.com/banking-audit-ledger/backend/internal/database"
 	"github.com/banking-audit-ledger/backend/internal/fabric"
+	"github.com/banking-audit-ledger/backend/internal/grpcapi"
+	"github.com/banking-audit-ledger/backend/internal/otlp"
+	"github.com/banking-audit-ledger/backend/internal/receipt"
 	"github.com/banking-audit-ledger/backend/internal/services"
+	"github.com/banking-audit-ledger/backend/internal/syslog"
+	"github.com/banking-audit-ledger/backend/internal/tsa"
 	"github.com/banking-audit-ledger/backend/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 func main() {
@@ -54,39 +65,166 @@ func main() {
 	logger.WithFields(logrus.Fields{"component": "fabric"}).Info("Successfully connected to Hyperledger Fabric network via Gateway")
 
 	// Initialize services
-	logService := services.NewLogService(db, fabricClient, logger)
-	verificationService := services.NewVerificationService(db, fabricClient, logger)
+	outbox := services.NewOutbox(db, fabricClient, cfg.Outbox, logger)
+	var batcher *services.Batcher
+	if cfg.Anchor.Mode == config.AnchorModeBatch {
+		batcher = services.NewBatcher(db, outbox, cfg.Anchor, logger)
+	}
+	// Receipts are signed with the newest key in the keys directory
+	var receiptSigner *receipt.Signer
+	var receipts services.ReceiptIssuer
+	if cfg.Receipt.Enabled {
+		receiptSigner, err = receipt.NewSigner(cfg.Receipt, logger)
+		if err != nil {
+			logger.Fatal("Failed to initialize receipt signer", "error", err)
+		}
+		receipts = receiptSigner
+	}
+	logService := services.NewLogService(db, fabricClient, outbox, batcher, receipts, cfg.Ingest, logger)
+
+	// Trusted timestamps from an RFC 3161 TSA
+	var tsaClient *tsa.Client
+	if cfg.TSA.Enabled {
+		tsaClient, err = tsa.NewClient(cfg.TSA)
+		if err != nil {
+			logger.Fatal("Failed to initialize TSA client", "error", err)
+		}
+	}
+	verificationService := services.NewVerificationService(db, fabricClient, tsaClient, logger)
+	reconciliationService := services.NewReconciliationService(db, fabricClient, outbox, cfg.Reconcile, cfg.Anchor, logger)
+	integrityScanner := services.NewIntegrityScanner(db, verificationService, cfg.Scanner, logger)
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	verificationJobService := services.NewVerificationJobService(workerCtx, db, verificationService, cfg.VerificationJob, logger)
+	if err := verificationJobService.RecoverInterrupted(); err != nil {
+		logger.WithError(err).Error("Failed to recover interrupted verification jobs")
+	}
+
+	webhookService := services.NewWebhookService(db, logService, cfg.Webhook, logger)
+	signingKeyService := services.NewSigningKeyService(db, logger)
+
+	// Initialize authentication
+	var authenticator auth.Authenticator
+	if cfg.Auth.Enabled {
+		chain, err := auth.New(cfg.Auth)
+		if err != nil {
+			logger.Fatal("Failed to initialize authentication", "error", err)
+		}
+		authenticator = chain
+	} else {
+		logger.Warning("Authentication is disabled - any caller can write logs under any source")
+	}
 
 	// Initialize API handlers
-	handlers := api.NewHandlers(logService, verificationService, logger)
+	handlers := api.NewHandlers(logService, verificationService, reconciliationService, verificationJobService, webhookService, signingKeyService, receiptSigner, logger)
 
 	// Setup Gin router
-	router := setupRouter(handlers, cfg)
+	router := setupRouter(handlers, cfg, authenticator, logger)
 
 	// Setup metrics endpoint
 	if cfg.MetricsEnabled {
 		router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	}
 
+	// Setup OTLP/HTTP logs endpoint at the path exporters post to by default
+	if cfg.OTLP.Enabled {
+		otlpHandlers := []gin.HandlerFunc{otlp.NewReceiver(cfg.OTLP, logService, logger).Export}
+		if authenticator != nil {
+			otlpHandlers = append([]gin.HandlerFunc{auth.Middleware(authenticator, logger), handlers.RequireRole(auth.RoleIngester)}, otlpHandlers...)
+		}
+		router.POST("/v1/logs", otlpHandlers...)
+	}
+
+	tlsConfig, err := serverTLSConfig(cfg.Server)
+	if err != nil {
+		logger.Fatal("Failed to load server TLS configuration", "error", err)
+	}
+
+	// Start background workers
+	go logService.RunIdempotencyKeyPurge(workerCtx)
+	if cfg.Outbox.Enabled {
+		go outbox.Run(workerCtx)
+	}
+	if batcher != nil {
+		go batcher.Run(workerCtx)
+	}
+	if cfg.Reconcile.Enabled {
+		go reconciliationService.Run(workerCtx)
+	}
+	if cfg.Scanner.Enabled {
+		go integrityScanner.Run(workerCtx)
+	}
+	if tsaClient != nil {
+		go services.NewTimestamper(db, tsaClient, cfg.TSA, logger).Run(workerCtx)
+	}
+
 	// Create HTTP server
 	server := &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
-		Handler: router,
+		Addr:      fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
+		Handler:   router,
+		TLSConfig: tlsConfig,
 	}
 
 	// Start server in a goroutine
 	go func() {
-		logger.Info("Starting server", "addr", server.Addr)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logger.Info("Starting server", "addr", server.Addr, "tls", tlsConfig != nil)
+		var err error
+		if tlsConfig != nil {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.Fatal("Failed to start server", "error", err)
 		}
 	}()
 
+	// Start gRPC server on its own port
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Enabled {
+		var options []grpc.ServerOption
+		if tlsConfig != nil {
+			options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+		if authenticator != nil {
+			options = append(options,
+				grpc.UnaryInterceptor(grpcapi.UnaryAuthInterceptor(authenticator)),
+				grpc.StreamInterceptor(grpcapi.StreamAuthInterceptor(authenticator)))
+		}
+		grpcServer = grpc.NewServer(options...)
+		grpcapi.NewServer(logService, verificationService, logger).Register(grpcServer)
+
+		grpcAddr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.GRPC.Port)
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			logger.Fatal("Failed to listen for gRPC", "error", err)
+		}
+		go func() {
+			logger.Info("Starting gRPC server", "addr", grpcAddr)
+			if err := grpcServer.Serve(listener); err != nil {
+				logger.Fatal("Failed to start gRPC server", "error", err)
+			}
+		}()
+	}
+
+	// Start syslog listener; it stops with the background workers
+	if cfg.Syslog.Enabled {
+		if err := syslog.NewListener(cfg.Syslog, logService, authenticator, logger).Start(workerCtx); err != nil {
+			logger.Fatal("Failed to start syslog listener", "error", err)
+		}
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	logger.Info("Shutting down server...")
+	stopWorkers()
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
 
 	// Give outstanding requests 30 seconds to complete
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -99,7 +237,7 @@ func main() {
 	logger.Info("Server exited")
 }
 
-func setupRouter(handlers *api.Handlers, cfg *config.Config) *gin.Engine {
+func setupRouter(handlers *api.Handlers, cfg *config.Config, authenticator auth.Authenticator, logger *logrus.Logger) *gin.Engine {
 	// Set Gin mode
 	if cfg.LogLevel == "debug" {
 		gin.SetMode(gin.DebugMode)
@@ -117,7 +255,7 @@ func setupRouter(handlers *api.Handlers, cfg *config.Config) *gin.Engine {
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key, ce-specversion, ce-id, ce-source, ce-type, ce-time")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -130,17 +268,84 @@ func setupRouter(handlers *api.Handlers, cfg *config.Config) *gin.Engine {
 	// Health check endpoint
 	router.GET("/healthz", handlers.HealthCheck)
 
+	// Public keys of ingestion receipts, readable by anyone holding a receipt
+	router.GET("/.well-known/jwks.json", handlers.ReceiptKeys)
+
+	// Inbound webhooks authenticate each delivery with their own HMAC secret
+	router.POST("/api/v1/webhooks/:name", handlers.ReceiveWebhook)
+
 	// API routes
 	api := router.Group("/api/v1")
+	if authenticator != nil {
+		api.Use(auth.Middleware(authenticator, logger))
+	}
+	ingester := handlers.RequireRole(auth.RoleIngester)
+	auditor := handlers.RequireRole(auth.RoleAuditor)
 	{
 		// Log management
-		api.POST("/logs", handlers.CreateLog)
-		api.GET("/logs/:id", handlers.GetLog)
-		api.GET("/logs", handlers.ListLogs)
+		api.POST("/logs", ingester, handlers.CreateLog)
+		api.POST("/logs/batch", ingester, handlers.CreateLogs)
+		api.POST("/logs/stream", ingester, handlers.IngestLogStream)
+		api.GET("/logs/:id", auditor, handlers.GetLog)
+		api.GET("/logs/:id/proof", auditor, handlers.GetLogProof)
+		api.GET("/logs/:id/timestamp", auditor, handlers.GetLogTimestamp)
+		api.GET("/logs", auditor, handlers.ListLogs)
 
 		// Verification
-		api.GET("/verify/:id", handlers.VerifyLog)
+		api.GET("/verify/:id", auditor, handlers.VerifyLog)
+		api.POST("/verify/by-content", auditor, handlers.VerifyByContent)
+		api.POST("/verify/:id", auditor, handlers.VerifyLogContent)
+		api.GET("/sources/:source/chain/verify", auditor, handlers.VerifyChain)
+		api.POST("/verifications", auditor, handlers.CreateVerificationJob)
+		api.GET("/verifications/:jobId", auditor, handlers.GetVerificationJob)
+
+		// Administration
+		admin := api.Group("/admin", handlers.RequireRole(auth.RoleAdmin))
+		{
+			admin.POST("/reconcile", handlers.Reconcile)
+			admin.GET("/reconcile", handlers.GetReconciliationReport)
+			admin.POST("/webhooks", handlers.RegisterWebhook)
+			admin.GET("/webhooks", handlers.ListWebhooks)
+			admin.DELETE("/webhooks/:name", handlers.DeleteWebhook)
+			admin.POST("/sources/:source/keys", handlers.RegisterSigningKey)
+			admin.GET("/sources/:source/keys", handlers.ListSigningKeys)
+			admin.DELETE("/sources/:source/keys/:keyId", handlers.RevokeSigningKey)
+			admin.POST("/receipt-keys/rotate", handlers.RotateReceiptKey)
+		}
 	}
 
 	return router
 }
+
+// serverTLSConfig builds the TLS configuration of the API servers, or nil
+// when no certificate is configured. Client certificates are verified when
+// presented but not required, so other credentials keep working.
+func serverTLSConfig(cfg config.ServerConfig) (*tls.Config, error) {
+	if cfg.TLSCertFile == "" {
+		return nil, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if cfg.TLSClientCAFile != "" {
+		caPEM, err := os.ReadFile(cfg.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in client CA %s", cfg.TLSClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/banking-audit-ledger/backend/internal/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RequireRole lets a request through only if its principal holds one of
+// roles. Without authentication there is no principal and every request is
+// let through.
+func (h *Handlers) RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := auth.PrincipalFrom(c)
+		if principal == nil || principal.HasRole(roles...) {
+			c.Next()
+			return
+		}
+
+		h.logger.WithFields(logrus.Fields{
+			"component": "auth",
+			"subject":   principal.Subject,
+			"path":      c.FullPath(),
+		}).Warn("Rejected request without the required role")
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden", "details": "credential lacks the required role"})
+	}
+}
+
+// allowedSources returns the sources the authenticated caller may write or
+// read, or nil when it is not restricted
+func allowedSources(c *gin.Context) []string {
+	if principal := auth.PrincipalFrom(c); principal != nil {
+		return principal.AllowedSources()
+	}
+	return nil
+}
+
+// sourceVisible reports whether the caller may see logs of source
+func sourceVisible(c *gin.Context, source string) bool {
+	allowed := allowedSources(c)
+	return allowed == nil || slices.Contains(allowed, source)
+}
+
+// logVisible reports whether the caller may see the log with id, answering
+// 404 if not so that logs outside its scope are indistinguishable from
+// missing ones. Lookup errors are left to the handler.
+func (h *Handlers) logVisible(c *gin.Context, id string) bool {
+	if allowedSources(c) == nil {
+		return true
+	}
+
+	log, err := h.logService.GetLog(id)
+	if err != nil || sourceVisible(c, log.Source) {
+		return true
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": "Log not found"})
+	return false
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/banking-audit-ledger/backend/internal/auth"
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/banking-audit-ledger/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// staticAuthenticator authenticates every request with an API key as principal
+type staticAuthenticator struct {
+	principal *auth.Principal
+}
+
+func (a staticAuthenticator) Authenticate(creds auth.Credentials) (*auth.Principal, error) {
+	if creds.APIKey == "" {
+		return nil, auth.ErrNoCredentials
+	}
+	return a.principal, nil
+}
+
+func newTestHandlers(t *testing.T) (*Handlers, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm: %v", err)
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	logService := services.NewLogService(db, nil, nil, nil, nil, config.IngestConfig{}, log)
+	return NewHandlers(logService, nil, nil, nil, nil, nil, nil, log), mock
+}
+
+// newTestRouter serves handlers behind authentication as principal, or
+// without authentication when principal is nil
+func newTestRouter(principal *auth.Principal, handlers ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	router := gin.New()
+	group := router.Group("/")
+	if principal != nil {
+		group.Use(auth.Middleware(staticAuthenticator{principal}, log))
+	}
+	group.GET("/logs/:id", handlers...)
+	return router
+}
+
+func serve(router *gin.Engine, apiKey string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, "/logs/"+uuid.NewString(), nil)
+	if apiKey != "" {
+		request.Header.Set("X-API-Key", apiKey)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestRequireRole(t *testing.T) {
+	handlers, _ := newTestHandlers(t)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		required  []string
+		want      int
+	}{
+		{"authentication disabled", nil, []string{auth.RoleAdmin}, http.StatusOK},
+		{"holds the role", &auth.Principal{Roles: []string{auth.RoleAuditor}}, []string{auth.RoleAuditor}, http.StatusOK},
+		{"holds one of the roles", &auth.Principal{Roles: []string{auth.RoleIngester}}, []string{auth.RoleAuditor, auth.RoleIngester}, http.StatusOK},
+		{"ingester on an auditor route", &auth.Principal{Roles: []string{auth.RoleIngester}}, []string{auth.RoleAuditor}, http.StatusForbidden},
+		{"auditor on an ingester route", &auth.Principal{Roles: []string{auth.RoleAuditor}}, []string{auth.RoleIngester}, http.StatusForbidden},
+		{"auditor on an admin route", &auth.Principal{Roles: []string{auth.RoleAuditor}}, []string{auth.RoleAdmin}, http.StatusForbidden},
+		{"no roles", &auth.Principal{}, []string{auth.RoleAuditor}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(tt.principal, handlers.RequireRole(tt.required...), ok)
+			if got := serve(router, "key").Code; got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// Without credentials the request never reaches the role check
+	router := newTestRouter(&auth.Principal{Roles: []string{auth.RoleAdmin}}, handlers.RequireRole(auth.RoleAdmin), ok)
+	if got := serve(router, "").Code; got != http.StatusUnauthorized {
+		t.Errorf("status without credentials = %d, want 401", got)
+	}
+}
+
+func TestLogVisible(t *testing.T) {
+	visible := func(h *Handlers) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if h.logVisible(c, c.Param("id")) {
+				c.Status(http.StatusOK)
+			}
+		}
+	}
+	logRows := func(source string) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "source", "payload"}).AddRow(uuid.New(), source, `{}`)
+	}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		rows      *sqlmock.Rows
+		want      int
+	}{
+		{"authentication disabled", nil, nil, http.StatusOK},
+		{"unrestricted principal", &auth.Principal{Sources: []string{auth.AnySource}}, nil, http.StatusOK},
+		{"source in scope", &auth.Principal{Sources: []string{"core-banking"}}, logRows("core-banking"), http.StatusOK},
+		{"source out of scope", &auth.Principal{Sources: []string{"cards"}}, logRows("core-banking"), http.StatusNotFound},
+		{"no sources", &auth.Principal{}, logRows("core-banking"), http.StatusNotFound},
+		{"missing log is left to the handler", &auth.Principal{Sources: []string{"cards"}}, sqlmock.NewRows([]string{"id"}), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers, mock := newTestHandlers(t)
+			// Unrestricted callers are let through without loading the log
+			if tt.rows != nil {
+				mock.ExpectQuery(`SELECT \* FROM "logs" WHERE id = \$1`).WillReturnRows(tt.rows)
+			}
+
+			router := newTestRouter(tt.principal, visible(handlers))
+			if got := serve(router, "key").Code; got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestGetLogHidesOtherSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []string
+		want    int
+	}{
+		{"in scope", []string{"core-banking"}, http.StatusOK},
+		{"out of scope", []string{"cards"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers, mock := newTestHandlers(t)
+			mock.ExpectQuery(`SELECT \* FROM "logs" WHERE id = \$1`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "source", "raw_payload"}).AddRow(uuid.New(), "core-banking", `{}`))
+
+			principal := &auth.Principal{Roles: []string{auth.RoleAuditor}, Sources: tt.sources}
+			router := newTestRouter(principal, handlers.RequireRole(auth.RoleAuditor), handlers.GetLog)
+			if got := serve(router, "key").Code; got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,21 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
+	"github.com/banking-audit-ledger/backend/internal/cloudevents"
 	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/banking-audit-ledger/backend/internal/receipt"
 	"github.com/banking-audit-ledger/backend/internal/services"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
@@ -15,20 +23,36 @@ import (
 type Handlers struct {
 	logService         *services.LogService
 	verificationService *services.VerificationService
+	reconciliationService *services.ReconciliationService
+	verificationJobService *services.VerificationJobService
+	webhookService     *services.WebhookService
+	signingKeyService  *services.SigningKeyService
+	receiptSigner      *receipt.Signer
 	logger             *logrus.Logger
 }
 
 // NewHandlers creates new HTTP handlers
-func NewHandlers(logService *services.LogService, verificationService *services.VerificationService, logger *logrus.Logger) *Handlers {
+func NewHandlers(logService *services.LogService, verificationService *services.VerificationService, reconciliationService *services.ReconciliationService, verificationJobService *services.VerificationJobService, webhookService *services.WebhookService, signingKeyService *services.SigningKeyService, receiptSigner *receipt.Signer, logger *logrus.Logger) *Handlers {
 	return &Handlers{
 		logService:         logService,
 		verificationService: verificationService,
+		reconciliationService: reconciliationService,
+		verificationJobService: verificationJobService,
+		webhookService:     webhookService,
+		signingKeyService:  signingKeyService,
+		receiptSigner:      receiptSigner,
 		logger:             logger,
 	}
 }
 
-// CreateLog handles POST /logs
+// CreateLog handles POST /logs. Besides CreateLogRequest bodies it accepts
+// CloudEvents in structured, binary and batch content mode.
 func (h *Handlers) CreateLog(c *gin.Context) {
+	if mode := cloudevents.Mode(c.Request); mode != "" {
+		h.createFromCloudEvents(c, mode)
+		return
+	}
+
 	var req models.CreateLogRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.WithError(err).Error("Invalid request payload")
@@ -36,16 +60,194 @@ func (h *Handlers) CreateLog(c *gin.Context) {
 		return
 	}
 
-	log, err := h.logService.CreateLog(&req)
+	h.createLog(c, &req)
+}
+
+// createLog creates one log and writes the response
+func (h *Handlers) createLog(c *gin.Context, req *models.CreateLogRequest) {
+	req.IdempotencyKey = c.GetHeader("Idempotency-Key")
+	req.AllowedSources = allowedSources(c)
+
+	log, err := h.logService.CreateLog(req)
 	if err != nil {
+		if errors.Is(err, services.ErrSourceNotAllowed) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Source not allowed", "details": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrInvalidPayload) || errors.Is(err, services.ErrInvalidLogID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrSignatureRequired) || errors.Is(err, services.ErrInvalidLogSignature) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid log signature", "details": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrIdempotencyConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Conflicting reuse of log ID or idempotency key", "details": err.Error()})
+			return
+		}
 		h.logger.WithError(err).Error("Failed to create log")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create log", "details": err.Error()})
 		return
 	}
 
+	if log.Replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
 	c.JSON(http.StatusCreated, log)
 }
 
+// CreateLogs handles POST /logs/batch. A CloudEvents batch is accepted too.
+func (h *Handlers) CreateLogs(c *gin.Context) {
+	if cloudevents.Mode(c.Request) == "batch" {
+		h.createFromCloudEvents(c, "batch")
+		return
+	}
+
+	// Items are validated one by one so that a bad item does not reject the batch
+	reqs, err := h.logService.DecodeBatch(c.Request.Body)
+	if err != nil {
+		if errors.Is(err, services.ErrBatchTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Batch too large", "details": err.Error()})
+			return
+		}
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	h.createLogs(c, reqs)
+}
+
+// createLogs creates a batch of logs and writes the response
+func (h *Handlers) createLogs(c *gin.Context, reqs []models.CreateLogRequest) {
+	if len(reqs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": "batch is empty"})
+		return
+	}
+
+	sources := allowedSources(c)
+	for i := range reqs {
+		reqs[i].AllowedSources = sources
+	}
+
+	result, err := h.logService.CreateLogs(reqs)
+	if err != nil {
+		if errors.Is(err, services.ErrBatchTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Batch too large", "details": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrIdempotencyConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Conflicting reuse of log ID", "details": err.Error()})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to create logs")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create logs", "details": err.Error()})
+		return
+	}
+
+	status := http.StatusCreated
+	if result.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, result)
+}
+
+// createFromCloudEvents maps the CloudEvents of a request onto logs. The
+// batch mode fails as a whole if any event is malformed; events that are
+// well formed but rejected by validation are reported per item.
+func (h *Handlers) createFromCloudEvents(c *gin.Context, mode string) {
+	body, err := io.ReadAll(h.logService.BatchBody(c.Request.Body))
+	if err != nil {
+		if errors.Is(err, services.ErrBatchTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Batch too large", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	var events []cloudevents.Event
+	switch mode {
+	case "batch":
+		events, err = cloudevents.ParseBatch(body)
+	case "structured":
+		var event *cloudevents.Event
+		if event, err = cloudevents.ParseStructured(body); err == nil {
+			events = append(events, *event)
+		}
+	default:
+		var event *cloudevents.Event
+		if event, err = cloudevents.FromBinary(c.Request.Header, body); err == nil {
+			events = append(events, *event)
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CloudEvent", "details": err.Error()})
+		return
+	}
+
+	reqs := make([]models.CreateLogRequest, 0, len(events))
+	for i := range events {
+		req, err := events[i].Request()
+		if err != nil {
+			if mode == "batch" {
+				err = fmt.Errorf("event %d: %w", i, err)
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CloudEvent", "details": err.Error()})
+			return
+		}
+		reqs = append(reqs, *req)
+	}
+
+	if mode == "batch" {
+		h.createLogs(c, reqs)
+		return
+	}
+	h.createLog(c, &reqs[0])
+}
+
+// IngestLogStream handles POST /logs/stream. The body is newline-delimited
+// JSON with one log per line. The response is newline-delimited JSON too: a
+// result per line as each chunk is stored, unless ?results=summary is given,
+// followed by a final summary line.
+func (h *Handlers) IngestLogStream(c *gin.Context) {
+	mode := c.DefaultQuery("results", "lines")
+	if mode != "lines" && mode != "summary" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid results mode", "details": "results must be lines or summary"})
+		return
+	}
+
+	// Keep reading the request body after the response has started
+	if err := http.NewResponseController(c.Writer).EnableFullDuplex(); err != nil {
+		h.logger.WithError(err).Debug("Full duplex not available for log stream")
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	encoder := json.NewEncoder(c.Writer)
+
+	summary, err := h.logService.IngestStream(c.Request.Body, allowedSources(c), func(result models.StreamIngestResult) error {
+		if mode != "lines" {
+			return nil
+		}
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to ingest log stream")
+		summary.Error = err.Error()
+	}
+
+	if err := encoder.Encode(gin.H{"summary": summary}); err != nil {
+		h.logger.WithError(err).Error("Failed to write log stream summary")
+	}
+	c.Writer.Flush()
+}
+
 // GetLog handles GET /logs/:id
 func (h *Handlers) GetLog(c *gin.Context) {
 	id := c.Param("id")
@@ -64,6 +266,10 @@ func (h *Handlers) GetLog(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get log", "details": err.Error()})
 		return
 	}
+	if !sourceVisible(c, log.Source) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Log not found"})
+		return
+	}
 
 	c.JSON(http.StatusOK, log)
 }
@@ -86,7 +292,8 @@ func (h *Handlers) ListLogs(c *gin.Context) {
 	source := c.Query("source")
 	eventType := c.Query("event_type")
 
-	logs, err := h.logService.ListLogs(page, pageSize, source, eventType)
+	// Auditors scoped to a business unit only see its sources
+	logs, err := h.logService.ListLogs(page, pageSize, source, eventType, allowedSources(c))
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list logs")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list logs", "details": err.Error()})
@@ -103,6 +310,9 @@ func (h *Handlers) VerifyLog(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Log ID is required"})
 		return
 	}
+	if !h.logVisible(c, id) {
+		return
+	}
 
 	verification, err := h.verificationService.VerifyLog(id)
 	if err != nil {
@@ -118,6 +328,435 @@ func (h *Handlers) VerifyLog(c *gin.Context) {
 	c.JSON(http.StatusOK, verification)
 }
 
+// VerifyLogContent handles POST /verify/:id
+func (h *Handlers) VerifyLogContent(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Log ID is required"})
+		return
+	}
+
+	var req models.VerifyLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+	if (req.Hash == "") == (len(req.Payload) == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": "exactly one of hash or payload is required"})
+		return
+	}
+	if !h.logVisible(c, id) {
+		return
+	}
+
+	var verification *models.VerificationResponse
+	var err error
+	if req.Hash != "" {
+		verification, err = h.verificationService.VerifyLogWithHash(id, req.Hash)
+	} else {
+		verification, err = h.verificationService.VerifyLogWithPayload(id, req.Payload)
+	}
+	if err != nil {
+		if err.Error() == "log not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Log not found"})
+			return
+		}
+		if errors.Is(err, services.ErrInvalidPayload) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to verify log")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify log", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// VerifyByContent handles POST /verify/by-content
+func (h *Handlers) VerifyByContent(c *gin.Context) {
+	var req models.ContentLookupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	lookup, err := h.logService.FindByContent(req.Payload)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPayload) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to look up content")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up content", "details": err.Error()})
+		return
+	}
+
+	if allowedSources(c) != nil {
+		lookup.Logs = slices.DeleteFunc(lookup.Logs, func(log models.LogResponse) bool {
+			return !sourceVisible(c, log.Source)
+		})
+		lookup.LedgerEntries = slices.DeleteFunc(lookup.LedgerEntries, func(entry models.LedgerEntry) bool {
+			return !sourceVisible(c, entry.Metadata["source"])
+		})
+		lookup.Anchored = len(lookup.LedgerEntries) > 0
+	}
+
+	c.JSON(http.StatusOK, lookup)
+}
+
+// Reconcile handles POST /admin/reconcile
+func (h *Handlers) Reconcile(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "0"))
+
+	report, err := h.reconciliationService.Reconcile(limit)
+	if err != nil {
+		if errors.Is(err, services.ErrReconciliationInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Reconciliation already in progress"})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to reconcile logs")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile logs", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetReconciliationReport handles GET /admin/reconcile
+func (h *Handlers) GetReconciliationReport(c *gin.Context) {
+	report := h.reconciliationService.LastReport()
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No reconciliation has run yet"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetLogProof handles GET /logs/:id/proof
+func (h *Handlers) GetLogProof(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Log ID is required"})
+		return
+	}
+	if !h.logVisible(c, id) {
+		return
+	}
+
+	proof, err := h.verificationService.GetInclusionProof(id)
+	if err != nil {
+		if err.Error() == "log not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Log not found"})
+			return
+		}
+		if errors.Is(err, services.ErrLogNotBatched) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Log is not anchored in a batch"})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to get inclusion proof")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get inclusion proof", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, proof)
+}
+
+// GetLogTimestamp handles GET /logs/:id/timestamp, returning the DER RFC
+// 3161 token covering the log hash or its batch root
+func (h *Handlers) GetLogTimestamp(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Log ID is required"})
+		return
+	}
+	if !h.logVisible(c, id) {
+		return
+	}
+
+	token, err := h.verificationService.GetTimestampToken(id)
+	if err != nil {
+		if err.Error() == "log not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Log not found"})
+			return
+		}
+		if errors.Is(err, services.ErrTimestampNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Log has no timestamp token yet"})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to get timestamp token")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timestamp token", "details": err.Error()})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/timestamp-token", token)
+}
+
+// VerifyChain handles GET /sources/:source/chain/verify
+func (h *Handlers) VerifyChain(c *gin.Context) {
+	source := c.Param("source")
+	if source == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Source is required"})
+		return
+	}
+	if !sourceVisible(c, source) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Source not found"})
+		return
+	}
+
+	verification, err := h.verificationService.VerifyChain(source)
+	if err != nil {
+		if err.Error() == "source not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Source not found"})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to verify chain")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify chain", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// CreateVerificationJob handles POST /verifications
+func (h *Handlers) CreateVerificationJob(c *gin.Context) {
+	var req models.CreateVerificationJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	// A scoped auditor must name one of its sources, since a job reports on every log it matches
+	if allowedSources(c) != nil && (req.Source == "" || !sourceVisible(c, req.Source)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Source not allowed", "details": "verification jobs must be filtered to a source the credential may read"})
+		return
+	}
+
+	job, err := h.verificationJobService.StartJob(&req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to start verification job")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start verification job", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetVerificationJob handles GET /verifications/:jobId. With ?report=csv or
+// ?report=json it returns the failures report of the job instead.
+func (h *Handlers) GetVerificationJob(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("jobId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID", "details": err.Error()})
+		return
+	}
+
+	job, err := h.verificationJobService.GetJob(jobID.String())
+	if err != nil {
+		if err.Error() == "verification job not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Verification job not found"})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to get verification job")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get verification job", "details": err.Error()})
+		return
+	}
+	if allowedSources(c) != nil && (job.Source == "" || !sourceVisible(c, job.Source)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Verification job not found"})
+		return
+	}
+
+	var contentType string
+	var write func(uuid.UUID, io.Writer) error
+	switch c.Query("report") {
+	case "":
+		c.JSON(http.StatusOK, job)
+		return
+	case "csv":
+		contentType, write = "text/csv", h.verificationJobService.WriteReportCSV
+	case "json":
+		contentType, write = "application/json", h.verificationJobService.WriteReportJSON
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report format", "details": "report must be csv or json"})
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=verification-%s.%s", job.ID, c.Query("report")))
+	c.Status(http.StatusOK)
+	if err := write(job.ID, c.Writer); err != nil {
+		// Headers are already sent, so the truncated report is all the client gets
+		h.logger.WithError(err).WithField("jobID", job.ID).Error("Failed to write verification report")
+	}
+}
+
+// ReceiveWebhook handles POST /webhooks/:name
+func (h *Handlers) ReceiveWebhook(c *gin.Context) {
+	log, err := h.webhookService.Receive(c.Param("name"), c.Request.Header, c.Request.Body)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrWebhookNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		case errors.Is(err, services.ErrInvalidSignature), errors.Is(err, services.ErrStaleDelivery):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Webhook delivery rejected", "details": err.Error()})
+		case errors.Is(err, services.ErrDeliveryTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Webhook delivery too large", "details": err.Error()})
+		case errors.Is(err, services.ErrSourceNotAllowed):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Source not allowed", "details": err.Error()})
+		case errors.Is(err, services.ErrInvalidPayload), errors.Is(err, services.ErrInvalidLogID):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		case errors.Is(err, services.ErrSignatureRequired), errors.Is(err, services.ErrInvalidLogSignature):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid log signature", "details": err.Error()})
+		case errors.Is(err, services.ErrIdempotencyConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Conflicting reuse of log ID", "details": err.Error()})
+		default:
+			h.logger.WithError(err).WithField("webhook", c.Param("name")).Error("Failed to receive webhook")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to receive webhook", "details": err.Error()})
+		}
+		return
+	}
+
+	if log.Replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	c.JSON(http.StatusCreated, log)
+}
+
+// RegisterWebhook handles POST /admin/webhooks
+func (h *Handlers) RegisterWebhook(c *gin.Context) {
+	var req models.RegisterWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	webhook, err := h.webhookService.RegisterWebhook(&req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidWebhook) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook", "details": err.Error()})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to register webhook")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register webhook", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// ListWebhooks handles GET /admin/webhooks
+func (h *Handlers) ListWebhooks(c *gin.Context) {
+	webhooks, err := h.webhookService.ListWebhooks()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list webhooks")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list webhooks", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// DeleteWebhook handles DELETE /admin/webhooks/:name
+func (h *Handlers) DeleteWebhook(c *gin.Context) {
+	if err := h.webhookService.DeleteWebhook(c.Param("name")); err != nil {
+		if errors.Is(err, services.ErrWebhookNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to delete webhook")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook", "details": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RegisterSigningKey handles POST /admin/sources/:source/keys
+func (h *Handlers) RegisterSigningKey(c *gin.Context) {
+	var req models.RegisterSigningKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	key, err := h.signingKeyService.RegisterKey(c.Param("source"), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSigningKey) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid signing key", "details": err.Error()})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to register signing key")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register signing key", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// ListSigningKeys handles GET /admin/sources/:source/keys
+func (h *Handlers) ListSigningKeys(c *gin.Context) {
+	keys, err := h.signingKeyService.ListKeys(c.Param("source"))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list signing keys")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list signing keys", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeSigningKey handles DELETE /admin/sources/:source/keys/:keyId
+func (h *Handlers) RevokeSigningKey(c *gin.Context) {
+	if err := h.signingKeyService.RevokeKey(c.Param("source"), c.Param("keyId")); err != nil {
+		if errors.Is(err, services.ErrSigningKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Signing key not found"})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to revoke signing key")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke signing key", "details": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ReceiptKeys handles GET /.well-known/jwks.json, publishing the public keys
+// that ingestion receipts are signed with
+func (h *Handlers) ReceiptKeys(c *gin.Context) {
+	if h.receiptSigner == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Receipts are disabled"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.receiptSigner.JWKS())
+}
+
+// RotateReceiptKey handles POST /admin/receipt-keys/rotate
+func (h *Handlers) RotateReceiptKey(c *gin.Context) {
+	if h.receiptSigner == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Receipts are disabled"})
+		return
+	}
+
+	keyID, err := h.receiptSigner.Rotate()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to rotate receipt key")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate receipt key", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"key_id": keyID})
+}
+
 // HealthCheck handles GET /healthz
 func (h *Handlers) HealthCheck(c *gin.Context) {
 	// Check database connection
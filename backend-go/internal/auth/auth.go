@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/banking-audit-ledger/backend/internal/config"
+)
+
+// AnySource in a credential's sources allows it to write under every source
+const AnySource = "*"
+
+// Roles a credential can hold. Ingesters create logs, auditors read and
+// verify them, and admins run reconciliation and other operations.
+const (
+	RoleIngester = "ingester"
+	RoleAuditor  = "auditor"
+	RoleAdmin    = "admin"
+)
+
+// Authentication methods recorded on a principal
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+	MethodMTLS   = "mtls"
+)
+
+var (
+	// ErrNoCredentials is returned when a request carries no credentials
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned when a request's credentials are not valid
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrUnknownClient is returned along with ErrInvalidCredentials for a
+	// verified client certificate no credential is registered for. The
+	// certificate may only secure the transport, so other credentials of the
+	// request are still tried.
+	ErrUnknownClient = errors.New("unknown client certificate")
+)
+
+// Principal is an authenticated caller. Its sources bound both the logs it
+// may write and, for auditors, the logs it may see.
+type Principal struct {
+	Subject string   `json:"subject"`
+	Method  string   `json:"method"`
+	Roles   []string `json:"roles"`
+	Sources []string `json:"sources"`
+}
+
+// HasRole reports whether the principal holds any of roles
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(p.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedSources returns the sources the principal may write or read, or
+// nil if it is not restricted
+func (p *Principal) AllowedSources() []string {
+	allowed := []string{}
+	for _, source := range p.Sources {
+		if source == AnySource {
+			return nil
+		}
+		allowed = append(allowed, source)
+	}
+	return allowed
+}
+
+// Credentials are the credentials a request presents, whatever its transport
+type Credentials struct {
+	APIKey            string
+	BearerToken       string
+	ClientCertificate *x509.Certificate
+}
+
+// Authenticator verifies one kind of credential. It returns ErrNoCredentials
+// when the request does not present that kind, so the next one can be tried.
+type Authenticator interface {
+	Authenticate(creds Credentials) (*Principal, error)
+}
+
+// Chain tries authenticators in order. The first that finds its kind of
+// credential decides, except that an unregistered client certificate defers
+// to the other credentials; a request with none of them is rejected.
+type Chain []Authenticator
+
+// Authenticate implements Authenticator
+func (c Chain) Authenticate(creds Credentials) (*Principal, error) {
+	var unknownClient error
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(creds)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		if errors.Is(err, ErrUnknownClient) {
+			unknownClient = err
+			continue
+		}
+		return principal, err
+	}
+	if unknownClient != nil {
+		return nil, unknownClient
+	}
+	return nil, ErrNoCredentials
+}
+
+// credentialsFile is the file listing API keys and client certificates, and
+// the sources of each business unit credentials can be scoped to
+type credentialsFile struct {
+	BusinessUnits      map[string][]string `json:"business_units"`
+	APIKeys            []apiKey            `json:"api_keys"`
+	ClientCertificates []clientCertificate `json:"client_certificates"`
+}
+
+// grant is the part of a credential that decides what it may do
+type grant struct {
+	Roles         []string `json:"roles"`
+	Sources       []string `json:"sources"`
+	BusinessUnits []string `json:"business_units"`
+}
+
+// resolve returns the roles of a grant and its sources, with business units
+// expanded into their sources
+func (g grant) resolve(businessUnits map[string][]string) (roles, sources []string, err error) {
+	for _, role := range g.Roles {
+		if role != RoleIngester && role != RoleAuditor && role != RoleAdmin {
+			return nil, nil, fmt.Errorf("unknown role %q", role)
+		}
+	}
+
+	sources = append(sources, g.Sources...)
+	for _, unit := range g.BusinessUnits {
+		unitSources, ok := businessUnits[unit]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown business unit %q", unit)
+		}
+		sources = append(sources, unitSources...)
+	}
+	return g.Roles, sources, nil
+}
+
+// New builds the authenticators enabled in cfg
+func New(cfg config.AuthConfig) (Chain, error) {
+	var chain Chain
+
+	var file credentialsFile
+	if cfg.CredentialsFile != "" {
+		data, err := os.ReadFile(cfg.CredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credentials file: %w", err)
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse credentials file: %w", err)
+		}
+
+		apiKeys, err := NewAPIKeyAuthenticator(file.APIKeys, file.BusinessUnits)
+		if err != nil {
+			return nil, err
+		}
+		clients, err := NewMTLSAuthenticator(file.ClientCertificates, file.BusinessUnits)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, apiKeys, clients)
+	}
+
+	if cfg.JWKSFile != "" {
+		jwtAuthenticator, err := NewJWTAuthenticator(cfg, file.BusinessUnits)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, jwtAuthenticator)
+	}
+
+	if len(chain) == 0 {
+		return nil, errors.New("authentication is enabled but neither AUTH_CREDENTIALS_FILE nor AUTH_JWKS_FILE is set")
+	}
+	return chain, nil
+}
@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/banking-audit-ledger/backend/internal/config"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func certificate(commonName string) *x509.Certificate {
+	return &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+}
+
+var testBusinessUnits = map[string][]string{"retail": {"core-banking", "cards"}}
+
+func newTestAPIKeys(t *testing.T) *APIKeyAuthenticator {
+	t.Helper()
+	authenticator, err := NewAPIKeyAuthenticator([]apiKey{
+		{ID: "ingest", SHA256: strings.ToUpper(sha256Hex("ingest-key")), grant: grant{Roles: []string{RoleIngester}, Sources: []string{"core-banking"}}},
+		{ID: "audit", SHA256: sha256Hex("audit-key"), grant: grant{Roles: []string{RoleAuditor}, BusinessUnits: []string{"retail"}}},
+	}, testBusinessUnits)
+	if err != nil {
+		t.Fatalf("NewAPIKeyAuthenticator: %v", err)
+	}
+	return authenticator
+}
+
+func newTestClients(t *testing.T) *MTLSAuthenticator {
+	t.Helper()
+	authenticator, err := NewMTLSAuthenticator([]clientCertificate{
+		{CommonName: "payments-gateway", grant: grant{Roles: []string{RoleIngester}, Sources: []string{AnySource}}},
+	}, testBusinessUnits)
+	if err != nil {
+		t.Fatalf("NewMTLSAuthenticator: %v", err)
+	}
+	return authenticator
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	authenticator := newTestAPIKeys(t)
+
+	principal, err := authenticator.Authenticate(Credentials{APIKey: "ingest-key"})
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	want := &Principal{Subject: "ingest", Method: MethodAPIKey, Roles: []string{RoleIngester}, Sources: []string{"core-banking"}}
+	if !reflect.DeepEqual(principal, want) {
+		t.Errorf("principal = %+v, want %+v", principal, want)
+	}
+
+	// Business units expand into their sources
+	principal, err = authenticator.Authenticate(Credentials{APIKey: "audit-key"})
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if !reflect.DeepEqual(principal.Sources, []string{"core-banking", "cards"}) {
+		t.Errorf("Sources = %v, want the retail sources", principal.Sources)
+	}
+
+	if _, err := authenticator.Authenticate(Credentials{APIKey: "wrong-key"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("unknown key: err = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := authenticator.Authenticate(Credentials{BearerToken: "token"}); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("no key: err = %v, want ErrNoCredentials", err)
+	}
+}
+
+func TestNewAPIKeyAuthenticatorRejectsInvalidKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		key  apiKey
+		want string
+	}{
+		{"missing id", apiKey{SHA256: sha256Hex("key")}, "needs an id"},
+		{"short hash", apiKey{ID: "k", SHA256: "abcd"}, "needs an id and a hex sha256"},
+		{"non-hex hash", apiKey{ID: "k", SHA256: strings.Repeat("zz", sha256.Size)}, "invalid sha256"},
+		{"unknown role", apiKey{ID: "k", SHA256: sha256Hex("key"), grant: grant{Roles: []string{"root"}}}, `unknown role "root"`},
+		{"unknown business unit", apiKey{ID: "k", SHA256: sha256Hex("key"), grant: grant{BusinessUnits: []string{"treasury"}}}, `unknown business unit "treasury"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAPIKeyAuthenticator([]apiKey{tt.key}, testBusinessUnits)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMTLSAuthenticator(t *testing.T) {
+	authenticator := newTestClients(t)
+
+	principal, err := authenticator.Authenticate(Credentials{ClientCertificate: certificate("payments-gateway")})
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if principal.Subject != "payments-gateway" || principal.Method != MethodMTLS || principal.AllowedSources() != nil {
+		t.Errorf("principal = %+v, want the unrestricted gateway", principal)
+	}
+
+	_, err = authenticator.Authenticate(Credentials{ClientCertificate: certificate("intruder")})
+	if !errors.Is(err, ErrInvalidCredentials) || !errors.Is(err, ErrUnknownClient) {
+		t.Errorf("unknown certificate: err = %v, want ErrInvalidCredentials and ErrUnknownClient", err)
+	}
+	if _, err := authenticator.Authenticate(Credentials{APIKey: "key"}); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("no certificate: err = %v, want ErrNoCredentials", err)
+	}
+}
+
+func TestChainAuthenticate(t *testing.T) {
+	chain := Chain{newTestAPIKeys(t), newTestClients(t)}
+
+	tests := []struct {
+		name    string
+		creds   Credentials
+		subject string
+		wantErr error
+	}{
+		{"api key", Credentials{APIKey: "ingest-key"}, "ingest", nil},
+		{"client certificate", Credentials{ClientCertificate: certificate("payments-gateway")}, "payments-gateway", nil},
+		{"unregistered certificate falls through to the api key", Credentials{APIKey: "audit-key", ClientCertificate: certificate("intruder")}, "audit", nil},
+		{"unregistered certificate alone", Credentials{ClientCertificate: certificate("intruder")}, "", ErrUnknownClient},
+		{"invalid api key decides", Credentials{APIKey: "wrong-key", ClientCertificate: certificate("payments-gateway")}, "", ErrInvalidCredentials},
+		{"no credentials", Credentials{}, "", ErrNoCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := chain.Authenticate(tt.creds)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate: %v", err)
+			}
+			if principal.Subject != tt.subject {
+				t.Errorf("Subject = %s, want %s", principal.Subject, tt.subject)
+			}
+		})
+	}
+}
+
+func TestPrincipal(t *testing.T) {
+	principal := &Principal{Roles: []string{RoleAuditor}, Sources: []string{"core-banking", "cards"}}
+	if !principal.HasRole(RoleIngester, RoleAuditor) || principal.HasRole(RoleAdmin) {
+		t.Errorf("HasRole does not match roles %v", principal.Roles)
+	}
+	if got := principal.AllowedSources(); !reflect.DeepEqual(got, []string{"core-banking", "cards"}) {
+		t.Errorf("AllowedSources = %v", got)
+	}
+
+	// No sources allows none, the wildcard allows all
+	if got := (&Principal{}).AllowedSources(); got == nil || len(got) != 0 {
+		t.Errorf("AllowedSources without sources = %#v, want an empty list", got)
+	}
+	if got := (&Principal{Sources: []string{"cards", AnySource}}).AllowedSources(); got != nil {
+		t.Errorf("AllowedSources with %q = %v, want nil", AnySource, got)
+	}
+}
+
+func TestNewFromCredentialsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	file := `{
+		"business_units": {"retail": ["core-banking", "cards"]},
+		"api_keys": [{"id": "ingest", "sha256": "` + sha256Hex("ingest-key") + `", "roles": ["ingester"], "business_units": ["retail"]}],
+		"client_certificates": [{"common_name": "payments-gateway", "roles": ["ingester"], "sources": ["*"]}]
+	}`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatalf("write credentials: %v", err)
+	}
+
+	chain, err := New(config.AuthConfig{CredentialsFile: path})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	principal, err := chain.Authenticate(Credentials{APIKey: "ingest-key"})
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if !reflect.DeepEqual(principal.Sources, []string{"core-banking", "cards"}) {
+		t.Errorf("Sources = %v, want the retail sources", principal.Sources)
+	}
+	if _, err := chain.Authenticate(Credentials{ClientCertificate: certificate("payments-gateway")}); err != nil {
+		t.Errorf("Authenticate certificate: %v", err)
+	}
+
+	if _, err := New(config.AuthConfig{}); err == nil {
+		t.Error("New without credentials file or JWKS succeeded")
+	}
+}
+
+func TestFromHTTP(t *testing.T) {
+	client := certificate("payments-gateway")
+	request := httptest.NewRequest("GET", "/logs", nil)
+	request.Header.Set("X-API-Key", "key")
+	request.Header.Set("Authorization", "Bearer  token ")
+	request.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{client}}}
+
+	creds := FromHTTP(request)
+	if creds.APIKey != "key" || creds.BearerToken != "token" || creds.ClientCertificate != client {
+		t.Errorf("FromHTTP = %+v", creds)
+	}
+
+	// Other schemes and unverified certificates are not credentials
+	request = httptest.NewRequest("GET", "/logs", nil)
+	request.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	request.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{client}}
+	if creds := FromHTTP(request); creds != (Credentials{}) {
+		t.Errorf("FromHTTP = %+v, want no credentials", creds)
+	}
+}
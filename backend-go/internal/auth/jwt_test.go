@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testJWTKeys are the signing keys of the test JWKS
+type testJWTKeys struct {
+	ec      *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+}
+
+func newTestJWTAuthenticator(t *testing.T) (*JWTAuthenticator, *testJWTKeys) {
+	t.Helper()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate EC key: %v", err)
+	}
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate Ed25519 key: %v", err)
+	}
+
+	encode := base64.RawURLEncoding.EncodeToString
+	jwks, err := json.Marshal(map[string][]jwk{"keys": {
+		{Kty: "EC", Kid: "ec", Use: "sig", Crv: "P-256", X: encode(ecKey.X.FillBytes(make([]byte, 32))), Y: encode(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{Kty: "OKP", Kid: "ed", Crv: "Ed25519", X: encode(edPublic)},
+		{Kty: "RSA", Kid: "enc", Use: "enc", N: "invalid", E: "invalid"},
+	}})
+	if err != nil {
+		t.Fatalf("marshal JWKS: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatalf("write JWKS: %v", err)
+	}
+
+	authenticator, err := NewJWTAuthenticator(config.AuthConfig{
+		JWKSFile:              path,
+		JWTIssuer:             "https://idp.example",
+		JWTAudience:           "audit-ledger",
+		JWTRolesClaim:         "roles",
+		JWTSourcesClaim:       "sources",
+		JWTBusinessUnitsClaim: "units",
+	}, testBusinessUnits)
+	if err != nil {
+		t.Fatalf("NewJWTAuthenticator: %v", err)
+	}
+	return authenticator, &testJWTKeys{ec: ecKey, ed25519: edKey}
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":     "analyst",
+		"iss":     "https://idp.example",
+		"aud":     "audit-ledger",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+		"roles":   []string{RoleAuditor},
+		"sources": "core-banking ledger",
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	authenticator, keys := newTestJWTAuthenticator(t)
+
+	principal, err := authenticator.Authenticate(Credentials{BearerToken: sign(t, jwt.SigningMethodES256, "ec", keys.ec, validClaims())})
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	want := &Principal{Subject: "analyst", Method: MethodJWT, Roles: []string{RoleAuditor}, Sources: []string{"core-banking", "ledger"}}
+	if !reflect.DeepEqual(principal, want) {
+		t.Errorf("principal = %+v, want %+v", principal, want)
+	}
+
+	// Business units in the token expand into their sources
+	claims := validClaims()
+	claims["sources"] = []string{"ledger"}
+	claims["units"] = []string{"retail"}
+	principal, err = authenticator.Authenticate(Credentials{BearerToken: sign(t, jwt.SigningMethodEdDSA, "ed", keys.ed25519, claims)})
+	if err != nil {
+		t.Fatalf("Authenticate EdDSA: %v", err)
+	}
+	if !reflect.DeepEqual(principal.Sources, []string{"ledger", "core-banking", "cards"}) {
+		t.Errorf("Sources = %v, want the token sources and the retail sources", principal.Sources)
+	}
+
+	if _, err := authenticator.Authenticate(Credentials{APIKey: "key"}); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("no token: err = %v, want ErrNoCredentials", err)
+	}
+}
+
+func TestJWTAuthenticatorRejectsInvalidTokens(t *testing.T) {
+	authenticator, keys := newTestJWTAuthenticator(t)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate EC key: %v", err)
+	}
+	with := func(name string, value interface{}) jwt.MapClaims {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"expired", sign(t, jwt.SigningMethodES256, "ec", keys.ec, with("exp", time.Now().Add(-time.Minute).Unix()))},
+		{"no expiry", sign(t, jwt.SigningMethodES256, "ec", keys.ec, with("exp", nil))},
+		{"wrong issuer", sign(t, jwt.SigningMethodES256, "ec", keys.ec, with("iss", "https://other.example"))},
+		{"wrong audience", sign(t, jwt.SigningMethodES256, "ec", keys.ec, with("aud", "other"))},
+		{"no subject", sign(t, jwt.SigningMethodES256, "ec", keys.ec, with("sub", nil))},
+		{"unknown role", sign(t, jwt.SigningMethodES256, "ec", keys.ec, with("roles", "root"))},
+		{"unknown business unit", sign(t, jwt.SigningMethodES256, "ec", keys.ec, with("units", "treasury"))},
+		{"signed by another key", sign(t, jwt.SigningMethodES256, "ec", otherKey, validClaims())},
+		{"unknown kid", sign(t, jwt.SigningMethodES256, "other", keys.ec, validClaims())},
+		{"no kid with several keys", sign(t, jwt.SigningMethodES256, "", keys.ec, validClaims())},
+		{"key named for another algorithm", sign(t, jwt.SigningMethodEdDSA, "ec", keys.ed25519, validClaims())},
+		{"HMAC", sign(t, jwt.SigningMethodHS256, "ec", []byte("secret"), validClaims())},
+		{"malformed", "not.a.token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := authenticator.Authenticate(Credentials{BearerToken: tt.token}); !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("err = %v, want ErrInvalidCredentials", err)
+			}
+		})
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	tests := []struct {
+		name string
+		jwks string
+	}{
+		{"not JSON", `{`},
+		{"no signing keys", `{"keys": [{"kty": "RSA", "use": "enc", "n": "AQAB", "e": "AQAB"}]}`},
+		{"unsupported key type", `{"keys": [{"kty": "oct", "kid": "k"}]}`},
+		{"unsupported curve", `{"keys": [{"kty": "EC", "crv": "P-192", "x": "AQ", "y": "AQ"}]}`},
+		{"point not on curve", `{"keys": [{"kty": "EC", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`},
+		{"short Ed25519 key", `{"keys": [{"kty": "OKP", "crv": "Ed25519", "x": "AQ"}]}`},
+		{"invalid RSA modulus", `{"keys": [{"kty": "RSA", "n": "!", "e": "AQAB"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseJWKS([]byte(tt.jwks)); err == nil {
+				t.Error("ParseJWKS succeeded")
+			}
+		})
+	}
+}
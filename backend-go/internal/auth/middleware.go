@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// principalKey is the gin key of the authenticated principal
+const principalKey = "auth.principal"
+
+// contextKey is the context key of the authenticated principal
+type contextKey struct{}
+
+// Middleware authenticates every request with authenticator and rejects
+// those without valid credentials
+func Middleware(authenticator Authenticator, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := authenticator.Authenticate(FromHTTP(c.Request))
+		if err != nil {
+			if !errors.Is(err, ErrNoCredentials) {
+				logger.WithError(err).WithFields(logrus.Fields{
+					"component": "auth",
+					"path":      c.FullPath(),
+					"remote":    c.ClientIP(),
+				}).Warn("Rejected request with invalid credentials")
+			}
+			c.Header("WWW-Authenticate", `Bearer realm="audit-ledger"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+			return
+		}
+
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// FromHTTP extracts the credentials of an HTTP request: an X-API-Key header,
+// an Authorization bearer token and a verified TLS client certificate
+func FromHTTP(r *http.Request) Credentials {
+	creds := Credentials{APIKey: r.Header.Get("X-API-Key")}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		creds.BearerToken = strings.TrimSpace(token)
+	}
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		creds.ClientCertificate = r.TLS.VerifiedChains[0][0]
+	}
+	return creds
+}
+
+// PrincipalFrom returns the principal authenticated for a request, or nil
+// when authentication is disabled
+func PrincipalFrom(c *gin.Context) *Principal {
+	if value, ok := c.Get(principalKey); ok {
+		return value.(*Principal)
+	}
+	return nil
+}
+
+// WithPrincipal returns a context carrying principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal carried by ctx, or nil
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(contextKey{}).(*Principal)
+	return principal
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"fmt"
+)
+
+// clientCertificate is a client certificate entry of the credentials file,
+// matched on the subject common name of a verified certificate
+type clientCertificate struct {
+	CommonName string `json:"common_name"`
+	grant
+}
+
+// MTLSAuthenticator authenticates verified TLS client certificates
+type MTLSAuthenticator struct {
+	clients map[string]*Principal
+}
+
+// NewMTLSAuthenticator creates a client certificate authenticator
+func NewMTLSAuthenticator(clients []clientCertificate, businessUnits map[string][]string) (*MTLSAuthenticator, error) {
+	byName := make(map[string]*Principal, len(clients))
+	for _, client := range clients {
+		roles, sources, err := client.resolve(businessUnits)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate %q: %w", client.CommonName, err)
+		}
+		byName[client.CommonName] = &Principal{Subject: client.CommonName, Method: MethodMTLS, Roles: roles, Sources: sources}
+	}
+	return &MTLSAuthenticator{clients: byName}, nil
+}
+
+// Authenticate implements Authenticator. The certificate must already have
+// been verified against the client CA by the TLS handshake.
+func (a *MTLSAuthenticator) Authenticate(creds Credentials) (*Principal, error) {
+	if creds.ClientCertificate == nil {
+		return nil, ErrNoCredentials
+	}
+
+	commonName := creds.ClientCertificate.Subject.CommonName
+	principal, ok := a.clients[commonName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %w %q", ErrInvalidCredentials, ErrUnknownClient, commonName)
+	}
+
+	return principal, nil
+}
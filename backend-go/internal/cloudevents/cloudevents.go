@@ -0,0 +1,170 @@
+package cloudevents
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// Content types of the structured and batch content modes
+const (
+	ContentTypeStructured = "application/cloudevents+json"
+	ContentTypeBatch      = "application/cloudevents-batch+json"
+)
+
+// SpecVersion is the only CloudEvents version accepted
+const SpecVersion = "1.0"
+
+// ErrInvalidEvent is returned for an event that does not follow the spec
+var ErrInvalidEvent = errors.New("invalid CloudEvent")
+
+// logIDNamespace derives log IDs from events whose id is not a UUID
+var logIDNamespace = uuid.MustParse("9b3c1f0e-5d2a-4c8b-8e71-2f6a4d9c0b37")
+
+// Event is a CloudEvent in its JSON format. Extension attributes are ignored.
+type Event struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            *time.Time      `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	DataBase64      string          `json:"data_base64,omitempty"`
+}
+
+// Mode returns the content mode of an HTTP request: "structured", "batch" or
+// "binary", or "" if the request does not carry a CloudEvent
+func Mode(r *http.Request) string {
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case contentType == ContentTypeStructured:
+		return "structured"
+	case contentType == ContentTypeBatch:
+		return "batch"
+	case r.Header.Get("ce-specversion") != "":
+		return "binary"
+	default:
+		return ""
+	}
+}
+
+// FromBinary reads an event from the ce- headers of a binary mode request,
+// with body as its data
+func FromBinary(header http.Header, body []byte) (*Event, error) {
+	event := &Event{
+		SpecVersion:     header.Get("ce-specversion"),
+		ID:              header.Get("ce-id"),
+		Source:          header.Get("ce-source"),
+		Type:            header.Get("ce-type"),
+		DataContentType: header.Get("Content-Type"),
+	}
+	if value := header.Get("ce-time"); value != "" {
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: time: %v", ErrInvalidEvent, err)
+		}
+		event.Time = &t
+	}
+
+	if len(body) > 0 {
+		if isJSON(event.DataContentType) && json.Valid(body) {
+			event.Data = body
+		} else {
+			event.DataBase64 = base64.StdEncoding.EncodeToString(body)
+		}
+	}
+
+	return event, nil
+}
+
+// ParseStructured decodes a structured mode body
+func ParseStructured(body []byte) (*Event, error) {
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	return &event, nil
+}
+
+// ParseBatch decodes a batch mode body
+func ParseBatch(body []byte) ([]Event, error) {
+	var events []Event
+	if err := json.Unmarshal(body, &events); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	return events, nil
+}
+
+// Request maps an event onto a create request. The event's source and type
+// become the log's source and event type, its time the event time and its
+// data the payload. Its id becomes the log ID when it is a UUID; otherwise
+// the log ID is derived from the source and id, which together identify an
+// event, so redelivered events replay the log created the first time.
+func (e *Event) Request() (*models.CreateLogRequest, error) {
+	if e.SpecVersion != SpecVersion {
+		return nil, fmt.Errorf("%w: unsupported specversion %q", ErrInvalidEvent, e.SpecVersion)
+	}
+	if e.ID == "" || e.Source == "" || e.Type == "" {
+		return nil, fmt.Errorf("%w: id, source and type are required", ErrInvalidEvent)
+	}
+
+	payload, err := e.payload()
+	if err != nil {
+		return nil, err
+	}
+
+	logID, err := uuid.Parse(e.ID)
+	if err != nil {
+		logID = uuid.NewSHA1(logIDNamespace, []byte(e.Source+"\x00"+e.ID))
+	}
+
+	return &models.CreateLogRequest{
+		LogID:     logID.String(),
+		Source:    e.Source,
+		EventType: e.Type,
+		EventTime: e.Time,
+		Payload:   payload,
+	}, nil
+}
+
+// payload returns the event data as JSON. JSON data is kept as is, text is
+// stored as a string and any other data as base64 with its content type.
+func (e *Event) payload() (json.RawMessage, error) {
+	switch {
+	case len(e.Data) > 0 && e.DataBase64 != "":
+		return nil, fmt.Errorf("%w: data and data_base64 are mutually exclusive", ErrInvalidEvent)
+	case len(e.Data) > 0:
+		return e.Data, nil
+	case e.DataBase64 == "":
+		return nil, fmt.Errorf("%w: data is required", ErrInvalidEvent)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(e.DataBase64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: data_base64: %v", ErrInvalidEvent, err)
+	}
+	mediaType, _, _ := mime.ParseMediaType(e.DataContentType)
+	if strings.HasPrefix(mediaType, "text/") && utf8.Valid(data) {
+		return json.Marshal(string(data))
+	}
+	return json.Marshal(map[string]string{
+		"datacontenttype": e.DataContentType,
+		"data_base64":     e.DataBase64,
+	})
+}
+
+// isJSON reports whether a content type is JSON; an absent one defaults to JSON
+func isJSON(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return contentType == "" || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
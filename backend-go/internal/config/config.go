@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 // Config holds all configuration for the application
@@ -10,6 +11,19 @@ type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	Fabric   FabricConfig
+	Outbox   OutboxConfig
+	Reconcile ReconcileConfig
+	Anchor   AnchorConfig
+	VerificationJob VerificationJobConfig
+	Scanner  ScannerConfig
+	Ingest   IngestConfig
+	GRPC     GRPCConfig
+	Syslog   SyslogConfig
+	OTLP     OTLPConfig
+	Webhook  WebhookConfig
+	Auth     AuthConfig
+	Receipt  ReceiptConfig
+	TSA      TSAConfig
 	LogLevel string
 	LogFormat string
 	MetricsEnabled bool
@@ -20,6 +34,11 @@ type Config struct {
 type ServerConfig struct {
 	Host string
 	Port int
+	// TLS is served when a certificate is set. Client certificates signed by
+	// the client CA are requested and, if presented, used for authentication.
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSClientCAFile string
 }
 
 // DatabaseConfig holds database configuration
@@ -41,12 +60,166 @@ type FabricConfig struct {
 	OrgName           string
 }
 
+// OutboxConfig holds configuration for the blockchain commit outbox worker
+type OutboxConfig struct {
+	Enabled      bool
+	PollInterval time.Duration
+	BatchSize    int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	// Lease is how long a claimed entry is reserved for one commit attempt;
+	// it must exceed the Fabric gateway's endorse, submit and commit timeouts
+	Lease time.Duration
+}
+
+// ReconcileConfig holds configuration for the periodic reconciliation job
+type ReconcileConfig struct {
+	Enabled   bool
+	Interval  time.Duration
+	BatchSize int
+	MinAge    time.Duration
+}
+
+// Anchoring modes
+const (
+	AnchorModeSingle = "single"
+	AnchorModeBatch  = "batch"
+)
+
+// AnchorConfig holds configuration for how log hashes are anchored on-chain
+type AnchorConfig struct {
+	Mode         string
+	BatchWindow  time.Duration
+	BatchMaxSize int
+}
+
+// VerificationJobConfig holds configuration for bulk verification jobs
+type VerificationJobConfig struct {
+	Concurrency int
+	PageSize    int
+	// MaxRunning bounds the jobs verifying at once; later jobs stay pending
+	MaxRunning int
+}
+
+// Integrity scanner modes
+const (
+	ScannerModeSweep  = "sweep"
+	ScannerModeSample = "sample"
+)
+
+// ScannerConfig holds configuration for the background integrity scanner
+type ScannerConfig struct {
+	Enabled     bool
+	Mode        string
+	Interval    time.Duration
+	BatchSize   int
+	Concurrency int
+	MinAge      time.Duration
+}
+
+// IngestConfig holds configuration for bulk log ingestion
+type IngestConfig struct {
+	BatchMaxItems      int
+	BatchMaxBytes      int64
+	StreamChunkSize    int
+	StreamMaxLineBytes int
+	// IdempotencyKeyTTL is how long an Idempotency-Key replays its response
+	IdempotencyKeyTTL time.Duration
+}
+
+// GRPCConfig holds configuration for the gRPC API server
+type GRPCConfig struct {
+	Enabled bool
+	Port    int
+}
+
+// SyslogConfig holds configuration for the syslog listener. An empty address
+// disables that transport; the templates map message fields to the log's
+// source and event type.
+type SyslogConfig struct {
+	Enabled           bool
+	UDPAddr           string
+	TCPAddr           string
+	TLSAddr           string
+	TLSCertFile       string
+	TLSKeyFile        string
+	TLSClientCAFile   string
+	SourceTemplate    string
+	EventTypeTemplate string
+	DefaultSource     string
+	DefaultEventType  string
+	MaxMessageBytes   int
+}
+
+// OTLPConfig holds configuration for the OTLP/HTTP logs receiver. The source
+// is read from a resource attribute and the event type from the record's
+// event name or a record attribute.
+type OTLPConfig struct {
+	Enabled            bool
+	SourceAttribute    string
+	EventTypeAttribute string
+	DefaultSource      string
+	DefaultEventType   string
+	MaxBodyBytes       int64
+}
+
+// WebhookConfig holds configuration for inbound webhooks
+type WebhookConfig struct {
+	ReplayWindow time.Duration
+	MaxBodyBytes int64
+}
+
+// AuthConfig holds configuration for API authentication. API keys and
+// client certificates are listed in the credentials file; bearer tokens are
+// JWTs verified against the JWKS file.
+type AuthConfig struct {
+	Enabled         bool
+	CredentialsFile string
+	JWKSFile        string
+	JWTIssuer       string
+	JWTAudience     string
+	JWTSourcesClaim string
+	JWTRolesClaim   string
+	// JWTBusinessUnitsClaim names the claim listing business units, which
+	// expand into the sources the credentials file assigns to them
+	JWTBusinessUnitsClaim string
+}
+
+// ReceiptConfig holds configuration for signed ingestion receipts. Signing
+// keys are PEM files in KeysDir; the most recently written one signs new
+// receipts and all of them are published in the JWKS.
+type ReceiptConfig struct {
+	Enabled bool
+	KeysDir string
+	Issuer  string
+}
+
+// TSAConfig holds configuration for RFC 3161 trusted timestamps. Tokens are
+// requested for anchored log hashes and batch roots and verified against the
+// certificates in CAFile.
+type TSAConfig struct {
+	Enabled   bool
+	URL       string
+	CAFile    string
+	PolicyOID string
+	Timeout   time.Duration
+	Interval  time.Duration
+	BatchSize int
+	// A hash the TSA refuses is retried after a delay doubling from
+	// BaseBackoff up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
 // Load loads configuration from environment variables
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Host: getEnv("SERVER_HOST", "0.0.0.0"),
-			Port: getEnvAsInt("SERVER_PORT", 8080),
+			Host:            getEnv("SERVER_HOST", "0.0.0.0"),
+			Port:            getEnvAsInt("SERVER_PORT", 8080),
+			TLSCertFile:     getEnv("SERVER_TLS_CERT_FILE", ""),
+			TLSKeyFile:      getEnv("SERVER_TLS_KEY_FILE", ""),
+			TLSClientCAFile: getEnv("SERVER_TLS_CLIENT_CA_FILE", ""),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -63,6 +236,101 @@ func Load() *Config {
 		UserName:          getEnv("FABRIC_USER_NAME", "Admin"),
 		OrgName:           getEnv("FABRIC_ORG_NAME", "Org1MSP"),
 	},
+		Outbox: OutboxConfig{
+			Enabled:      getEnvAsBool("OUTBOX_ENABLED", true),
+			PollInterval: getEnvAsDuration("OUTBOX_POLL_INTERVAL", 5*time.Second),
+			BatchSize:    getEnvAsInt("OUTBOX_BATCH_SIZE", 50),
+			BaseBackoff:  getEnvAsDuration("OUTBOX_BASE_BACKOFF", 2*time.Second),
+			MaxBackoff:   getEnvAsDuration("OUTBOX_MAX_BACKOFF", 10*time.Minute),
+			Lease:        getEnvAsDuration("OUTBOX_LEASE", 5*time.Minute),
+		},
+		Reconcile: ReconcileConfig{
+			Enabled:   getEnvAsBool("RECONCILE_ENABLED", true),
+			Interval:  getEnvAsDuration("RECONCILE_INTERVAL", 15*time.Minute),
+			BatchSize: getEnvAsInt("RECONCILE_BATCH_SIZE", 500),
+			MinAge:    getEnvAsDuration("RECONCILE_MIN_AGE", 5*time.Minute),
+		},
+		Anchor: AnchorConfig{
+			Mode:         getEnv("ANCHOR_MODE", AnchorModeSingle),
+			BatchWindow:  getEnvAsDuration("ANCHOR_BATCH_WINDOW", 10*time.Second),
+			BatchMaxSize: getEnvAsInt("ANCHOR_BATCH_MAX_SIZE", 1000),
+		},
+		VerificationJob: VerificationJobConfig{
+			Concurrency: getEnvAsInt("VERIFICATION_JOB_CONCURRENCY", 8),
+			PageSize:    getEnvAsInt("VERIFICATION_JOB_PAGE_SIZE", 500),
+			MaxRunning:  getEnvAsInt("VERIFICATION_JOB_MAX_RUNNING", 2),
+		},
+		Scanner: ScannerConfig{
+			Enabled:     getEnvAsBool("SCANNER_ENABLED", true),
+			Mode:        getEnv("SCANNER_MODE", ScannerModeSweep),
+			Interval:    getEnvAsDuration("SCANNER_INTERVAL", time.Minute),
+			BatchSize:   getEnvAsInt("SCANNER_BATCH_SIZE", 200),
+			Concurrency: getEnvAsInt("SCANNER_CONCURRENCY", 4),
+			MinAge:      getEnvAsDuration("SCANNER_MIN_AGE", 5*time.Minute),
+		},
+		Ingest: IngestConfig{
+			BatchMaxItems:      getEnvAsInt("INGEST_BATCH_MAX_ITEMS", 1000),
+			BatchMaxBytes:      int64(getEnvAsInt("INGEST_BATCH_MAX_BYTES", 32<<20)),
+			StreamChunkSize:    getEnvAsInt("INGEST_STREAM_CHUNK_SIZE", 500),
+			StreamMaxLineBytes: getEnvAsInt("INGEST_STREAM_MAX_LINE_BYTES", 1<<20),
+			IdempotencyKeyTTL:  getEnvAsDuration("INGEST_IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+		},
+		GRPC: GRPCConfig{
+			Enabled: getEnvAsBool("GRPC_ENABLED", true),
+			Port:    getEnvAsInt("GRPC_PORT", 9091),
+		},
+		Syslog: SyslogConfig{
+			Enabled:           getEnvAsBool("SYSLOG_ENABLED", false),
+			UDPAddr:           getEnv("SYSLOG_UDP_ADDR", ":5514"),
+			TCPAddr:           getEnv("SYSLOG_TCP_ADDR", ":5514"),
+			TLSAddr:           getEnv("SYSLOG_TLS_ADDR", ""),
+			TLSCertFile:       getEnv("SYSLOG_TLS_CERT_FILE", ""),
+			TLSKeyFile:        getEnv("SYSLOG_TLS_KEY_FILE", ""),
+			TLSClientCAFile:   getEnv("SYSLOG_TLS_CLIENT_CA_FILE", ""),
+			SourceTemplate:    getEnv("SYSLOG_SOURCE_TEMPLATE", "{app_name}"),
+			EventTypeTemplate: getEnv("SYSLOG_EVENT_TYPE_TEMPLATE", "{msg_id}"),
+			DefaultSource:     getEnv("SYSLOG_DEFAULT_SOURCE", "syslog"),
+			DefaultEventType:  getEnv("SYSLOG_DEFAULT_EVENT_TYPE", "syslog"),
+			MaxMessageBytes:   getEnvAsInt("SYSLOG_MAX_MESSAGE_BYTES", 64*1024),
+		},
+		OTLP: OTLPConfig{
+			Enabled:            getEnvAsBool("OTLP_ENABLED", false),
+			SourceAttribute:    getEnv("OTLP_SOURCE_ATTRIBUTE", "service.name"),
+			EventTypeAttribute: getEnv("OTLP_EVENT_TYPE_ATTRIBUTE", "event.name"),
+			DefaultSource:      getEnv("OTLP_DEFAULT_SOURCE", "otel"),
+			DefaultEventType:   getEnv("OTLP_DEFAULT_EVENT_TYPE", "otel.log"),
+			MaxBodyBytes:       int64(getEnvAsInt("OTLP_MAX_BODY_BYTES", 4<<20)),
+		},
+		Webhook: WebhookConfig{
+			ReplayWindow: getEnvAsDuration("WEBHOOK_REPLAY_WINDOW", 5*time.Minute),
+			MaxBodyBytes: int64(getEnvAsInt("WEBHOOK_MAX_BODY_BYTES", 1<<20)),
+		},
+		Auth: AuthConfig{
+			Enabled:               getEnvAsBool("AUTH_ENABLED", false),
+			CredentialsFile:       getEnv("AUTH_CREDENTIALS_FILE", ""),
+			JWKSFile:              getEnv("AUTH_JWKS_FILE", ""),
+			JWTIssuer:             getEnv("AUTH_JWT_ISSUER", ""),
+			JWTAudience:           getEnv("AUTH_JWT_AUDIENCE", ""),
+			JWTSourcesClaim:       getEnv("AUTH_JWT_SOURCES_CLAIM", "sources"),
+			JWTRolesClaim:         getEnv("AUTH_JWT_ROLES_CLAIM", "roles"),
+			JWTBusinessUnitsClaim: getEnv("AUTH_JWT_BUSINESS_UNITS_CLAIM", "business_units"),
+		},
+		Receipt: ReceiptConfig{
+			Enabled: getEnvAsBool("RECEIPT_ENABLED", false),
+			KeysDir: getEnv("RECEIPT_KEYS_DIR", "./receipt-keys"),
+			Issuer:  getEnv("RECEIPT_ISSUER", "banking-audit-ledger"),
+		},
+		TSA: TSAConfig{
+			Enabled:     getEnvAsBool("TSA_ENABLED", false),
+			URL:         getEnv("TSA_URL", ""),
+			CAFile:      getEnv("TSA_CA_FILE", ""),
+			PolicyOID:   getEnv("TSA_POLICY_OID", ""),
+			Timeout:     getEnvAsDuration("TSA_TIMEOUT", 10*time.Second),
+			Interval:    getEnvAsDuration("TSA_INTERVAL", time.Minute),
+			BatchSize:   getEnvAsInt("TSA_BATCH_SIZE", 100),
+			BaseBackoff: getEnvAsDuration("TSA_BASE_BACKOFF", time.Minute),
+			MaxBackoff:  getEnvAsDuration("TSA_MAX_BACKOFF", 6*time.Hour),
+		},
 		LogLevel:       getEnv("LOG_LEVEL", "info"),
 		LogFormat:      getEnv("LOG_FORMAT", "json"),
 		MetricsEnabled: getEnvAsBool("METRICS_ENABLED", true),
@@ -97,3 +365,13 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+// getEnvAsDuration gets an environment variable as duration with a default value
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if durationValue, err := time.ParseDuration(value); err == nil {
+			return durationValue
+		}
+	}
+	return defaultValue
+}
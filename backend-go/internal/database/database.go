@@ -1,10 +1,12 @@
 package database
 
 import (
+	"crypto/sha256"
 	"fmt"
 
 	"github.com/banking-audit-ledger/backend/internal/config"
 	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/banking-audit-ledger/backend/pkg/jcs"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -25,9 +27,91 @@ func New(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	return db, nil
 }
 
+// contentHashBackfillPage is the number of logs given a content hash per query
+const contentHashBackfillPage = 500
+
 // Migrate runs database migrations
 func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := scopeIdempotencyKeys(db); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(
 		&models.Log{},
-	)
+		&models.OutboxEntry{},
+		&models.AnchorBatch{},
+		&models.SourceChainHead{},
+		&models.VerificationJob{},
+		&models.VerificationJobFailure{},
+		&models.IdempotencyKey{},
+		&models.Webhook{},
+		&models.SigningKey{},
+		&models.TimestampToken{},
+		&models.TimestampAttempt{},
+	); err != nil {
+		return err
+	}
+	return backfillContentHashes(db)
+}
+
+// scopeIdempotencyKeys moves idempotency keys created when keys were global
+// under the source of the log they created, since AutoMigrate cannot change
+// a primary key
+func scopeIdempotencyKeys(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.IdempotencyKey{}) || migrator.HasColumn(&models.IdempotencyKey{}, "source") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range []string{
+			`ALTER TABLE idempotency_keys ADD COLUMN source varchar(255) NOT NULL DEFAULT ''`,
+			`UPDATE idempotency_keys k SET source = l.source FROM logs l WHERE l.id = k.log_id`,
+			`ALTER TABLE idempotency_keys ALTER COLUMN source DROP DEFAULT`,
+			`ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey`,
+			`ALTER TABLE idempotency_keys ADD PRIMARY KEY (source, key)`,
+		} {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("failed to scope idempotency keys to sources: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// backfillContentHashes records the content hash of JCS and envelope logs
+// stored before logs carried one, so that lookups by content find them. The
+// hash is computed from the original payload bytes as CreateLog does.
+func backfillContentHashes(db *gorm.DB) error {
+	var lastID string
+	for {
+		var logs []models.Log
+		query := db.Unscoped().
+			Select("id", "raw_payload").
+			Where("(content_hash IS NULL OR content_hash = '') AND hash_version >= ? AND raw_payload <> ''", models.HashVersionJCS)
+		if lastID != "" {
+			query = query.Where("id > ?", lastID)
+		}
+		if err := query.Order("id").Limit(contentHashBackfillPage).Find(&logs).Error; err != nil {
+			return fmt.Errorf("failed to load logs without a content hash: %w", err)
+		}
+
+		for _, log := range logs {
+			// A payload that no longer canonicalizes was altered after it was
+			// stored; verification reports it, so it is left without a hash
+			canonical, err := jcs.Canonicalize([]byte(log.RawPayload))
+			if err != nil {
+				continue
+			}
+			if err := db.Unscoped().Model(&models.Log{}).
+				Where("id = ?", log.ID).
+				UpdateColumn("content_hash", fmt.Sprintf("%x", sha256.Sum256(canonical))).Error; err != nil {
+				return fmt.Errorf("failed to backfill content hash: %w", err)
+			}
+		}
+
+		if len(logs) < contentHashBackfillPage {
+			return nil
+		}
+		lastID = logs[len(logs)-1].ID.String()
+	}
 }
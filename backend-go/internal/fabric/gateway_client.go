@@ -5,19 +5,30 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/banking-audit-ledger/backend/internal/config"
 	"github.com/hyperledger/fabric-gateway/pkg/client"
 	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 )
 
+// ErrLogHashNotFound is returned when the ledger holds no entry for a log ID
+var ErrLogHashNotFound = errors.New("log hash not found on blockchain")
+
+// ErrBatchRootNotFound is returned when the ledger holds no entry for a batch ID
+var ErrBatchRootNotFound = errors.New("batch root not found on blockchain")
+
 // LogHash represents a log hash entry on the blockchain
 type LogHash struct {
 	LogID     string            `json:"logID"`
@@ -27,6 +38,16 @@ type LogHash struct {
 	Metadata  map[string]string `json:"metadata"`
 }
 
+// BatchRoot represents a Merkle root anchoring a batch of log hashes on the blockchain
+type BatchRoot struct {
+	BatchID   string            `json:"batchID"`
+	Root      string            `json:"root"`
+	LeafCount int               `json:"leafCount"`
+	TxID      string            `json:"txID"`
+	Timestamp string            `json:"timestamp"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
 // GatewayClient represents a Fabric Gateway client
 type GatewayClient struct {
 	gateway *client.Gateway
@@ -173,6 +194,9 @@ func (c *GatewayClient) GetLogHash(logID string) (*LogHash, error) {
 	// Evaluate transaction
 	result, err := contract.EvaluateTransaction("GetLogHash", logID)
 	if err != nil {
+		if isNotFoundError(err) {
+			return nil, fmt.Errorf("%w: %s", ErrLogHashNotFound, logID)
+		}
 		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
 	}
 
@@ -203,6 +227,9 @@ func (c *GatewayClient) VerifyLogHash(logID, hash string) (bool, error) {
 	// Evaluate transaction
 	result, err := contract.EvaluateTransaction("VerifyLogHash", logID, hash)
 	if err != nil {
+		if isNotFoundError(err) {
+			return false, fmt.Errorf("%w: %s", ErrLogHashNotFound, logID)
+		}
 		return false, fmt.Errorf("failed to evaluate transaction: %w", err)
 	}
 
@@ -220,11 +247,119 @@ func (c *GatewayClient) VerifyLogHash(logID, hash string) (bool, error) {
 	return verified, nil
 }
 
+// GetLogHashesByHash retrieves every log hash entry anchored with the given
+// hash or payload hash from the blockchain
+func (c *GatewayClient) GetLogHashesByHash(hash string) ([]LogHash, error) {
+	c.Logger.WithFields(logrus.Fields{
+		"hash": hash,
+	}).Info("Looking up log hashes by hash from blockchain via Gateway")
+
+	// Get contract
+	contract := c.network.GetContract(c.Config.ChaincodeName)
+
+	// Evaluate transaction
+	result, err := contract.EvaluateTransaction("GetLogHashesByHash", hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	// Parse result
+	var logHashes []LogHash
+	if err := json.Unmarshal(result, &logHashes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	c.Logger.WithFields(logrus.Fields{
+		"hash":  hash,
+		"count": len(logHashes),
+	}).Info("Retrieved log hashes by hash from blockchain")
+
+	return logHashes, nil
+}
+
+// CommitBatchRoot commits the Merkle root of a batch of log hashes to the blockchain
+func (c *GatewayClient) CommitBatchRoot(batchID, root string, leafCount int, metadata map[string]string) (string, error) {
+	c.Logger.WithFields(logrus.Fields{
+		"batchID":   batchID,
+		"root":      root,
+		"leafCount": leafCount,
+	}).Info("Committing batch root to blockchain via Gateway")
+
+	// Convert metadata to JSON
+	metadataJSON := "{}"
+	if len(metadata) > 0 {
+		metadataBytes, err := json.Marshal(metadata)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		metadataJSON = string(metadataBytes)
+	}
+
+	// Get contract
+	contract := c.network.GetContract(c.Config.ChaincodeName)
+
+	// Submit transaction with batchID, root, leaf count and metadata
+	result, err := contract.SubmitTransaction("CommitBatchRoot", batchID, root, strconv.Itoa(leafCount), metadataJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to submit transaction: %w", err)
+	}
+
+	txID := string(result)
+	c.Logger.WithFields(logrus.Fields{
+		"txID":    txID,
+		"batchID": batchID,
+	}).Info("Batch root committed successfully via Gateway")
+
+	return txID, nil
+}
+
+// GetBatchRoot retrieves a batch Merkle root from the blockchain
+func (c *GatewayClient) GetBatchRoot(batchID string) (*BatchRoot, error) {
+	c.Logger.WithFields(logrus.Fields{
+		"batchID": batchID,
+	}).Info("Getting batch root from blockchain via Gateway")
+
+	// Get contract
+	contract := c.network.GetContract(c.Config.ChaincodeName)
+
+	// Evaluate transaction
+	result, err := contract.EvaluateTransaction("GetBatchRoot", batchID)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, fmt.Errorf("%w: %s", ErrBatchRootNotFound, batchID)
+		}
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	// Parse result
+	var batchRoot BatchRoot
+	if err := json.Unmarshal(result, &batchRoot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return &batchRoot, nil
+}
+
 // Close closes the Gateway client
 func (c *GatewayClient) Close() {
 	_ = c.gateway.Close()
 }
 
+// isNotFoundError reports whether a chaincode error means the requested key does not exist
+func isNotFoundError(err error) bool {
+	if strings.Contains(err.Error(), "does not exist") {
+		return true
+	}
+
+	// Endorser messages are carried in the gRPC status details rather than the error text
+	for _, detail := range status.Convert(err).Details() {
+		if errorDetail, ok := detail.(*gateway.ErrorDetail); ok && strings.Contains(errorDetail.GetMessage(), "does not exist") {
+			return true
+		}
+	}
+	return false
+}
+
 // loadTLSCertificate loads a TLS certificate from file
 func loadTLSCertificate(path string) (*x509.Certificate, error) {
 	certPEM, err := ioutil.ReadFile(path)
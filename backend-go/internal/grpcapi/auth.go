@@ -0,0 +1,112 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/banking-audit-ledger/backend/internal/auth"
+	pb "github.com/banking-audit-ledger/backend/proto/auditledger/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// methodRoles are the roles allowed to call each method, as for the
+// matching REST routes
+var methodRoles = map[string][]string{
+	pb.AuditLedgerService_CreateLog_FullMethodName:  {auth.RoleIngester},
+	pb.AuditLedgerService_IngestLogs_FullMethodName: {auth.RoleIngester},
+	pb.AuditLedgerService_GetLog_FullMethodName:     {auth.RoleAuditor},
+	pb.AuditLedgerService_ListLogs_FullMethodName:   {auth.RoleAuditor},
+	pb.AuditLedgerService_VerifyLog_FullMethodName:  {auth.RoleAuditor},
+}
+
+// UnaryAuthInterceptor authenticates and authorizes unary calls with authenticator
+func UnaryAuthInterceptor(authenticator auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, authenticator, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor authenticates and authorizes streaming calls with authenticator
+func StreamAuthInterceptor(authenticator auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), authenticator, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authenticatedStream carries the authenticated principal in its context
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context carrying the principal
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate verifies the credentials of a call, read from the x-api-key
+// and authorization metadata and the peer's TLS client certificate, and
+// checks that the caller holds a role allowed to call the method
+func authenticate(ctx context.Context, authenticator auth.Authenticator, method string) (context.Context, error) {
+	var creds auth.Credentials
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("x-api-key"); len(values) > 0 {
+			creds.APIKey = values[0]
+		}
+		if values := md.Get("authorization"); len(values) > 0 {
+			if token, ok := strings.CutPrefix(values[0], "Bearer "); ok {
+				creds.BearerToken = strings.TrimSpace(token)
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			chains := tlsInfo.State.VerifiedChains
+			if len(chains) > 0 && len(chains[0]) > 0 {
+				creds.ClientCertificate = chains[0][0]
+			}
+		}
+	}
+
+	principal, err := authenticator.Authenticate(creds)
+	if err != nil {
+		if errors.Is(err, auth.ErrNoCredentials) {
+			return nil, status.Error(codes.Unauthenticated, "no credentials")
+		}
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if !principal.HasRole(methodRoles[method]...) {
+		return nil, status.Error(codes.PermissionDenied, "credential lacks the required role")
+	}
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+// allowedSources returns the sources the caller may write or read, or nil
+// when it is not restricted
+func allowedSources(ctx context.Context) []string {
+	if principal := auth.PrincipalFromContext(ctx); principal != nil {
+		return principal.AllowedSources()
+	}
+	return nil
+}
+
+// sourceVisible reports whether the caller may see logs of source
+func sourceVisible(ctx context.Context, source string) bool {
+	allowed := allowedSources(ctx)
+	return allowed == nil || slices.Contains(allowed, source)
+}
@@ -0,0 +1,158 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/banking-audit-ledger/backend/internal/auth"
+	pb "github.com/banking-audit-ledger/backend/proto/auditledger/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// recordingAuthenticator records the credentials it is given and answers
+// with principal, or ErrNoCredentials when there is none
+type recordingAuthenticator struct {
+	principal *auth.Principal
+	err       error
+	creds     auth.Credentials
+}
+
+func (a *recordingAuthenticator) Authenticate(creds auth.Credentials) (*auth.Principal, error) {
+	a.creds = creds
+	if a.err != nil {
+		return nil, a.err
+	}
+	return a.principal, nil
+}
+
+func TestAuthenticateReadsCredentials(t *testing.T) {
+	client := &x509.Certificate{Subject: pkix.Name{CommonName: "payments-gateway"}}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"x-api-key", "key",
+		"authorization", "Bearer token",
+	))
+	ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{
+		State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{client}}},
+	}})
+
+	principal := &auth.Principal{Subject: "ingest", Roles: []string{auth.RoleIngester}}
+	authenticator := &recordingAuthenticator{principal: principal}
+	ctx, err := authenticate(ctx, authenticator, pb.AuditLedgerService_CreateLog_FullMethodName)
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+
+	if creds := authenticator.creds; creds.APIKey != "key" || creds.BearerToken != "token" || creds.ClientCertificate != client {
+		t.Errorf("credentials = %+v", creds)
+	}
+	if got := auth.PrincipalFromContext(ctx); got != principal {
+		t.Errorf("context principal = %+v, want %+v", got, principal)
+	}
+}
+
+func TestAuthenticateChecksMethodRoles(t *testing.T) {
+	tests := []struct {
+		name   string
+		roles  []string
+		method string
+		want   codes.Code
+	}{
+		{"ingester creates", []string{auth.RoleIngester}, pb.AuditLedgerService_CreateLog_FullMethodName, codes.OK},
+		{"ingester streams", []string{auth.RoleIngester}, pb.AuditLedgerService_IngestLogs_FullMethodName, codes.OK},
+		{"auditor reads", []string{auth.RoleAuditor}, pb.AuditLedgerService_GetLog_FullMethodName, codes.OK},
+		{"auditor lists", []string{auth.RoleAuditor}, pb.AuditLedgerService_ListLogs_FullMethodName, codes.OK},
+		{"auditor verifies", []string{auth.RoleAuditor}, pb.AuditLedgerService_VerifyLog_FullMethodName, codes.OK},
+		{"auditor may not create", []string{auth.RoleAuditor}, pb.AuditLedgerService_CreateLog_FullMethodName, codes.PermissionDenied},
+		{"ingester may not read", []string{auth.RoleIngester}, pb.AuditLedgerService_GetLog_FullMethodName, codes.PermissionDenied},
+		{"admin holds neither role", []string{auth.RoleAdmin}, pb.AuditLedgerService_VerifyLog_FullMethodName, codes.PermissionDenied},
+		{"unlisted method", []string{auth.RoleAdmin, auth.RoleAuditor, auth.RoleIngester}, "/auditledger.v1.AuditLedgerService/Unknown", codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := &recordingAuthenticator{principal: &auth.Principal{Roles: tt.roles}}
+			_, err := authenticate(context.Background(), authenticator, tt.method)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("code = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateRejectsMissingAndInvalidCredentials(t *testing.T) {
+	for _, err := range []error{auth.ErrNoCredentials, auth.ErrInvalidCredentials} {
+		_, got := authenticate(context.Background(), &recordingAuthenticator{err: err}, pb.AuditLedgerService_GetLog_FullMethodName)
+		if status.Code(got) != codes.Unauthenticated {
+			t.Errorf("%v: code = %s, want Unauthenticated", err, status.Code(got))
+		}
+	}
+}
+
+func TestUnaryAuthInterceptor(t *testing.T) {
+	principal := &auth.Principal{Roles: []string{auth.RoleAuditor}, Sources: []string{"core-banking"}}
+	interceptor := UnaryAuthInterceptor(&recordingAuthenticator{principal: principal})
+
+	var visible, hidden bool
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		visible, hidden = sourceVisible(ctx, "core-banking"), sourceVisible(ctx, "cards")
+		return "ok", nil
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuditLedgerService_GetLog_FullMethodName}
+	if _, err := interceptor(context.Background(), nil, info, handler); err != nil {
+		t.Fatalf("interceptor: %v", err)
+	}
+	if !visible || hidden {
+		t.Errorf("sourceVisible = %t for core-banking and %t for cards, want true and false", visible, hidden)
+	}
+
+	// The handler is not called for a caller without the method's role
+	info = &grpc.UnaryServerInfo{FullMethod: pb.AuditLedgerService_CreateLog_FullMethodName}
+	called := false
+	_, err := interceptor(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	})
+	if status.Code(err) != codes.PermissionDenied || called {
+		t.Errorf("interceptor = %v, handler called %t; want PermissionDenied without calling it", err, called)
+	}
+}
+
+// testServerStream is a server stream with only a context
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamAuthInterceptor(t *testing.T) {
+	principal := &auth.Principal{Roles: []string{auth.RoleIngester}}
+	interceptor := StreamAuthInterceptor(&recordingAuthenticator{principal: principal})
+	info := &grpc.StreamServerInfo{FullMethod: pb.AuditLedgerService_IngestLogs_FullMethodName}
+
+	var got *auth.Principal
+	err := interceptor(nil, &testServerStream{ctx: context.Background()}, info, func(srv interface{}, stream grpc.ServerStream) error {
+		got = auth.PrincipalFromContext(stream.Context())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("interceptor: %v", err)
+	}
+	if got != principal {
+		t.Errorf("stream principal = %+v, want %+v", got, principal)
+	}
+
+	if allowed := allowedSources(context.Background()); allowed != nil {
+		t.Errorf("allowedSources without a principal = %v, want nil", allowed)
+	}
+}
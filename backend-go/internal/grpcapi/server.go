@@ -0,0 +1,295 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/banking-audit-ledger/backend/internal/services"
+	pb "github.com/banking-audit-ledger/backend/proto/auditledger/v1"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements the AuditLedgerService gRPC API on top of the same
+// services as the REST handlers
+type Server struct {
+	pb.UnimplementedAuditLedgerServiceServer
+
+	logService          *services.LogService
+	verificationService *services.VerificationService
+	logger              *logrus.Logger
+}
+
+// NewServer creates a new gRPC API server
+func NewServer(logService *services.LogService, verificationService *services.VerificationService, logger *logrus.Logger) *Server {
+	return &Server{
+		logService:          logService,
+		verificationService: verificationService,
+		logger:              logger,
+	}
+}
+
+// Register registers the API on a gRPC server
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	pb.RegisterAuditLedgerServiceServer(registrar, s)
+}
+
+// CreateLog creates a new audit log
+func (s *Server) CreateLog(ctx context.Context, req *pb.CreateLogRequest) (*pb.CreateLogResponse, error) {
+	createReq := toCreateLogRequest(req)
+	createReq.IdempotencyKey = req.GetIdempotencyKey()
+	createReq.AllowedSources = allowedSources(ctx)
+
+	log, err := s.logService.CreateLog(&createReq)
+	if err != nil {
+		return nil, s.toStatus(err, "Failed to create log")
+	}
+
+	return &pb.CreateLogResponse{Log: toProtoLog(log), Replayed: log.Replayed, Receipt: log.Receipt}, nil
+}
+
+// GetLog retrieves a log by ID
+func (s *Server) GetLog(ctx context.Context, req *pb.GetLogRequest) (*pb.Log, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "log ID is required")
+	}
+
+	log, err := s.logService.GetLog(req.GetId())
+	if err != nil {
+		return nil, s.toStatus(err, "Failed to get log")
+	}
+	if !sourceVisible(ctx, log.Source) {
+		return nil, status.Error(codes.NotFound, "log not found")
+	}
+
+	return toProtoLog(log), nil
+}
+
+// ListLogs lists logs with pagination and filters
+func (s *Server) ListLogs(ctx context.Context, req *pb.ListLogsRequest) (*pb.ListLogsResponse, error) {
+	page, pageSize := int(req.GetPage()), int(req.GetPageSize())
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	logs, err := s.logService.ListLogs(page, pageSize, req.GetSource(), req.GetEventType(), allowedSources(ctx))
+	if err != nil {
+		return nil, s.toStatus(err, "Failed to list logs")
+	}
+
+	response := &pb.ListLogsResponse{
+		Logs:       make([]*pb.Log, len(logs.Logs)),
+		Total:      logs.Total,
+		Page:       int32(logs.Page),
+		PageSize:   int32(logs.PageSize),
+		TotalPages: int32(logs.TotalPages),
+	}
+	for i := range logs.Logs {
+		response.Logs[i] = toProtoLog(&logs.Logs[i])
+	}
+
+	return response, nil
+}
+
+// VerifyLog verifies a log, or the caller's hash or payload of it, against the ledger
+func (s *Server) VerifyLog(ctx context.Context, req *pb.VerifyLogRequest) (*pb.VerificationResult, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "log ID is required")
+	}
+	if allowedSources(ctx) != nil {
+		log, err := s.logService.GetLog(req.GetId())
+		if err != nil {
+			return nil, s.toStatus(err, "Failed to verify log")
+		}
+		if !sourceVisible(ctx, log.Source) {
+			return nil, status.Error(codes.NotFound, "log not found")
+		}
+	}
+
+	var verification *models.VerificationResponse
+	var err error
+	switch provided := req.GetProvided().(type) {
+	case *pb.VerifyLogRequest_Hash:
+		verification, err = s.verificationService.VerifyLogWithHash(req.GetId(), provided.Hash)
+	case *pb.VerifyLogRequest_Payload:
+		verification, err = s.verificationService.VerifyLogWithPayload(req.GetId(), provided.Payload)
+	default:
+		verification, err = s.verificationService.VerifyLog(req.GetId())
+	}
+	if err != nil {
+		return nil, s.toStatus(err, "Failed to verify log")
+	}
+
+	return toProtoVerification(verification), nil
+}
+
+// IngestLogs creates the logs of a client stream in chunks and reports the
+// totals once the client closes the stream
+func (s *Server) IngestLogs(stream pb.AuditLedgerService_IngestLogsServer) error {
+	sources := allowedSources(stream.Context())
+	index := 0
+	next := func() (services.IngestItem, error) {
+		req, err := stream.Recv()
+		if err != nil {
+			return services.IngestItem{}, err
+		}
+		index++
+		item := services.IngestItem{Line: index, Req: toCreateLogRequest(req)}
+		item.Req.AllowedSources = sources
+		return item, nil
+	}
+
+	response := &pb.IngestLogsResponse{}
+	summary, err := s.logService.Ingest(next, func(result models.StreamIngestResult) error {
+		if result.Status == models.BatchItemFailed {
+			response.Errors = append(response.Errors, &pb.IngestError{Index: int32(result.Line), Error: result.Error})
+		}
+		return nil
+	})
+	if err != nil {
+		return s.toStatus(err, "Failed to ingest logs")
+	}
+
+	response.Received = int32(summary.Lines)
+	response.Created = int32(summary.Created)
+	response.Replayed = int32(summary.Replayed)
+	response.Failed = int32(summary.Failed)
+	return stream.SendAndClose(response)
+}
+
+// toStatus maps a service error to a gRPC status
+func (s *Server) toStatus(err error, message string) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case err.Error() == "log not found":
+		return status.Error(codes.NotFound, "log not found")
+	case errors.Is(err, services.ErrInvalidPayload), errors.Is(err, services.ErrInvalidLogID),
+		errors.Is(err, services.ErrSignatureRequired), errors.Is(err, services.ErrInvalidLogSignature):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, services.ErrSourceNotAllowed):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, services.ErrIdempotencyConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, services.ErrBatchTooLarge):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	s.logger.WithError(err).Error(message)
+	return status.Errorf(codes.Internal, "%s: %v", message, err)
+}
+
+// toCreateLogRequest converts a protobuf create request
+func toCreateLogRequest(req *pb.CreateLogRequest) models.CreateLogRequest {
+	return models.CreateLogRequest{
+		LogID:     req.GetLogId(),
+		Source:    req.GetSource(),
+		EventType: req.GetEventType(),
+		Payload:   json.RawMessage(req.GetPayload()),
+		Signature: req.GetSignature(),
+		KeyID:     req.GetKeyId(),
+	}
+}
+
+// toProtoLog converts a LogResponse to its protobuf form
+func toProtoLog(log *models.LogResponse) *pb.Log {
+	payload, _ := json.Marshal(log.Payload)
+
+	result := &pb.Log{
+		Id:          log.ID.String(),
+		CreatedAt:   timestamppb.New(log.CreatedAt),
+		Source:      log.Source,
+		EventType:   log.EventType,
+		Payload:     payload,
+		Hash:        log.Hash,
+		ContentHash: log.ContentHash,
+		HashVersion: int32(log.HashVersion),
+		Sequence:    log.Sequence,
+	}
+	if log.PrevHash != nil {
+		result.PrevHash = *log.PrevHash
+	}
+	if log.TxID != nil {
+		result.TxId = *log.TxID
+	}
+	if log.EventTime != nil {
+		result.EventTime = timestamppb.New(*log.EventTime)
+	}
+	if log.CommittedAt != nil {
+		result.CommittedAt = timestamppb.New(*log.CommittedAt)
+	}
+	if log.BatchID != nil {
+		result.BatchId = log.BatchID.String()
+	}
+	if log.LeafIndex != nil {
+		leafIndex := int32(*log.LeafIndex)
+		result.LeafIndex = &leafIndex
+	}
+	if log.Signature != nil && log.SignatureKeyID != nil {
+		result.Signature = *log.Signature
+		result.SignatureKeyId = *log.SignatureKeyID
+	}
+
+	return result
+}
+
+// toProtoVerification converts a VerificationResponse to its protobuf form
+func toProtoVerification(verification *models.VerificationResponse) *pb.VerificationResult {
+	result := &pb.VerificationResult{
+		Id:             verification.ID.String(),
+		HashOffchain:   verification.HashOffChain,
+		HashOnchain:    verification.HashOnChain,
+		HashRecomputed: verification.HashRecomputed,
+		HashProvided:   verification.HashProvided,
+		HashVersion:    int32(verification.HashVersion),
+		IsValid:        verification.IsValid,
+		Status:         verification.Status,
+		Details:        verification.Details,
+		VerifiedAt:     timestamppb.New(verification.VerifiedAt),
+	}
+	for _, mismatch := range verification.MetadataMismatches {
+		result.MetadataMismatches = append(result.MetadataMismatches, &pb.FieldMismatch{
+			Field:    mismatch.Field,
+			Offchain: mismatch.OffChain,
+			Onchain:  mismatch.OnChain,
+		})
+	}
+	if signer := verification.Signer; signer != nil {
+		result.Signer = &pb.Signer{
+			KeyId:       signer.KeyID,
+			Source:      signer.Source,
+			Algorithm:   signer.Algorithm,
+			Fingerprint: signer.Fingerprint,
+			Valid:       signer.Valid,
+			Details:     signer.Details,
+		}
+	}
+	if timestamp := verification.Timestamp; timestamp != nil {
+		result.Timestamp = &pb.TrustedTimestamp{
+			SerialNumber: timestamp.SerialNumber,
+			Policy:       timestamp.Policy,
+			Authority:    timestamp.Authority,
+			Hash:         timestamp.Hash,
+			Valid:        timestamp.Valid,
+			Details:      timestamp.Details,
+		}
+		if !timestamp.GenTime.IsZero() {
+			result.Timestamp.GenTime = timestamppb.New(timestamp.GenTime)
+		}
+		if timestamp.BatchID != nil {
+			result.Timestamp.BatchId = timestamp.BatchID.String()
+		}
+	}
+
+	return result
+}
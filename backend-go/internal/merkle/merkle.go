@@ -0,0 +1,142 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Domain separation prefixes so a leaf can never be confused with an interior node
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// Tree is a binary SHA256 Merkle tree built over hex-encoded leaf hashes.
+// When a level has an odd number of nodes the last one is promoted to the
+// next level unchanged rather than duplicated.
+type Tree struct {
+	levels [][][]byte
+}
+
+// New builds a Merkle tree from hex-encoded SHA256 leaf hashes
+func New(leafHashes []string) (*Tree, error) {
+	if len(leafHashes) == 0 {
+		return nil, fmt.Errorf("cannot build merkle tree without leaves")
+	}
+
+	leaves := make([][]byte, len(leafHashes))
+	for i, leafHash := range leafHashes {
+		leaf, err := hex.DecodeString(leafHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid leaf hash at index %d: %w", i, err)
+		}
+		leaves[i] = HashLeaf(leaf)
+	}
+
+	levels := [][][]byte{leaves}
+	for level := leaves; len(level) > 1; {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, HashNode(level[i], level[i+1]))
+		}
+		levels = append(levels, next)
+		level = next
+	}
+
+	return &Tree{levels: levels}, nil
+}
+
+// Root returns the hex-encoded Merkle root
+func (t *Tree) Root() string {
+	return hex.EncodeToString(t.levels[len(t.levels)-1][0])
+}
+
+// LeafCount returns the number of leaves in the tree
+func (t *Tree) LeafCount() int {
+	return len(t.levels[0])
+}
+
+// Sibling positions in an inclusion proof
+const (
+	PositionLeft  = "left"
+	PositionRight = "right"
+)
+
+// ProofStep is one sibling on the path from a leaf to the root
+type ProofStep struct {
+	Hash     string `json:"hash"`
+	Position string `json:"position"`
+}
+
+// Proof returns the sibling path from the leaf at index to the root.
+// Levels where the node is promoted without a sibling contribute no step.
+func (t *Tree) Proof(index int) ([]ProofStep, error) {
+	if index < 0 || index >= t.LeafCount() {
+		return nil, fmt.Errorf("leaf index %d out of range", index)
+	}
+
+	proof := []ProofStep{}
+	for _, level := range t.levels[:len(t.levels)-1] {
+		if index%2 == 1 {
+			proof = append(proof, ProofStep{Hash: hex.EncodeToString(level[index-1]), Position: PositionLeft})
+		} else if index+1 < len(level) {
+			proof = append(proof, ProofStep{Hash: hex.EncodeToString(level[index+1]), Position: PositionRight})
+		}
+		index /= 2
+	}
+
+	return proof, nil
+}
+
+// VerifyProof checks that a hex-encoded leaf hash and its sibling path lead to the given root
+func VerifyProof(leafHash string, proof []ProofStep, root string) (bool, error) {
+	leaf, err := hex.DecodeString(leafHash)
+	if err != nil {
+		return false, fmt.Errorf("invalid leaf hash: %w", err)
+	}
+	expected, err := hex.DecodeString(root)
+	if err != nil {
+		return false, fmt.Errorf("invalid root: %w", err)
+	}
+
+	node := HashLeaf(leaf)
+	for i, step := range proof {
+		sibling, err := hex.DecodeString(step.Hash)
+		if err != nil {
+			return false, fmt.Errorf("invalid sibling hash at step %d: %w", i, err)
+		}
+		switch step.Position {
+		case PositionLeft:
+			node = HashNode(sibling, node)
+		case PositionRight:
+			node = HashNode(node, sibling)
+		default:
+			return false, fmt.Errorf("invalid sibling position at step %d: %s", i, step.Position)
+		}
+	}
+
+	return bytes.Equal(node, expected), nil
+}
+
+// HashLeaf computes the tree node for a leaf value
+func HashLeaf(leaf []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(leaf)
+	return h.Sum(nil)
+}
+
+// HashNode computes the parent of two child nodes
+func HashNode(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
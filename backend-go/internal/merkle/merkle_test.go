@@ -0,0 +1,163 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+// SHA-256 of "a" through "e", the leaf hashes of the vectors below
+var testLeaves = []string{
+	"ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb",
+	"3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d",
+	"2e7d2c03a9507ae265ecf5b5356885a53393a2029d241394997265a1a25aefc6",
+	"18ac3e7343f016890c510e93f935261169d9e3f565436429830faf0934f4f8e4",
+	"3f79bb7b435b05321651daefd374cdc681dc06faa65e374e38337b88ca046dea",
+}
+
+func TestRootVectors(t *testing.T) {
+	// Roots computed independently as SHA-256(0x00 || leaf) for leaves and
+	// SHA-256(0x01 || left || right) for nodes, promoting odd nodes
+	tests := []struct {
+		leaves int
+		root   string
+	}{
+		{1, "a23bd5b06da9048238a65b3f1d9d0b9e15fae3dde262688e6489aa4c763d1820"},
+		{2, "ad5ca6cddc0b27c6a83e332bf28011769236e6c6a1f786ebf7b5267b37a5bd22"},
+		{3, "cac3d448d4e20a2ad5eae1f500e63c2a7f9217cd14572ba7fd22e26dc1ec2648"},
+		{5, "4dc1abc938a0141a3c7cd1fed88948c35c4452e7e8aff9b1503eb5100a2c77b3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d leaves", tt.leaves), func(t *testing.T) {
+			tree, err := New(testLeaves[:tt.leaves])
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			if got := tree.Root(); got != tt.root {
+				t.Errorf("Root() = %s, want %s", got, tt.root)
+			}
+			if got := tree.LeafCount(); got != tt.leaves {
+				t.Errorf("LeafCount() = %d, want %d", got, tt.leaves)
+			}
+		})
+	}
+}
+
+func TestDomainSeparation(t *testing.T) {
+	leaf, _ := hex.DecodeString(testLeaves[0])
+	left, _ := hex.DecodeString(testLeaves[0])
+	right, _ := hex.DecodeString(testLeaves[1])
+
+	wantLeaf := sha256.Sum256(append([]byte{0x00}, leaf...))
+	if got := hex.EncodeToString(HashLeaf(leaf)); got != hex.EncodeToString(wantLeaf[:]) {
+		t.Errorf("HashLeaf = %s, want SHA-256 over 0x00 prefix %x", got, wantLeaf)
+	}
+
+	wantNode := sha256.Sum256(append(append([]byte{0x01}, left...), right...))
+	if got := hex.EncodeToString(HashNode(left, right)); got != hex.EncodeToString(wantNode[:]) {
+		t.Errorf("HashNode = %s, want SHA-256 over 0x01 prefix %x", got, wantNode)
+	}
+
+	// An interior node presented as a leaf must not reproduce the root
+	tree, err := New(testLeaves[:2])
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	interior := hex.EncodeToString(HashNode(HashLeaf(left), HashLeaf(right)))
+	single, err := New([]string{interior})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if single.Root() == tree.Root() {
+		t.Error("an interior node used as a leaf reproduced the root")
+	}
+}
+
+func TestOddNodePromotion(t *testing.T) {
+	tree, err := New(testLeaves[:3])
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// The third leaf has no sibling on the first level, so its proof is only
+	// the node above the first two leaves
+	proof, err := tree.Proof(2)
+	if err != nil {
+		t.Fatalf("Proof: %v", err)
+	}
+	if len(proof) != 1 {
+		t.Fatalf("len(proof) = %d, want 1", len(proof))
+	}
+	a, _ := hex.DecodeString(testLeaves[0])
+	b, _ := hex.DecodeString(testLeaves[1])
+	want := hex.EncodeToString(HashNode(HashLeaf(a), HashLeaf(b)))
+	if proof[0].Hash != want || proof[0].Position != PositionLeft {
+		t.Errorf("proof[0] = %+v, want left sibling %s", proof[0], want)
+	}
+}
+
+func TestProofRoundTrip(t *testing.T) {
+	for _, size := range []int{1, 2, 3, 4, 5, 8, 9, 17, 33} {
+		leaves := make([]string, size)
+		for i := range leaves {
+			sum := sha256.Sum256([]byte(fmt.Sprintf("log-%d", i)))
+			leaves[i] = hex.EncodeToString(sum[:])
+		}
+		tree, err := New(leaves)
+		if err != nil {
+			t.Fatalf("size %d: New: %v", size, err)
+		}
+
+		for i, leaf := range leaves {
+			proof, err := tree.Proof(i)
+			if err != nil {
+				t.Fatalf("size %d, leaf %d: Proof: %v", size, i, err)
+			}
+			ok, err := VerifyProof(leaf, proof, tree.Root())
+			if err != nil || !ok {
+				t.Errorf("size %d, leaf %d: VerifyProof = %v, %v, want true", size, i, ok, err)
+			}
+
+			// The proof must not hold for another leaf or a flipped path
+			if size > 1 {
+				other := leaves[(i+1)%size]
+				if ok, _ := VerifyProof(other, proof, tree.Root()); ok {
+					t.Errorf("size %d, leaf %d: proof verified for leaf %d", size, i, (i+1)%size)
+				}
+				flipped := append([]ProofStep(nil), proof...)
+				if flipped[0].Position == PositionLeft {
+					flipped[0].Position = PositionRight
+				} else {
+					flipped[0].Position = PositionLeft
+				}
+				if ok, _ := VerifyProof(leaf, flipped, tree.Root()); ok {
+					t.Errorf("size %d, leaf %d: proof with a flipped position verified", size, i)
+				}
+			}
+		}
+	}
+}
+
+func TestInvalidInput(t *testing.T) {
+	if _, err := New(nil); err == nil {
+		t.Error("New(nil) succeeded, want error")
+	}
+	if _, err := New([]string{"not hex"}); err == nil {
+		t.Error("New with a non-hex leaf succeeded, want error")
+	}
+
+	tree, err := New(testLeaves[:2])
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for _, index := range []int{-1, 2} {
+		if _, err := tree.Proof(index); err == nil {
+			t.Errorf("Proof(%d) succeeded, want error", index)
+		}
+	}
+	if _, err := VerifyProof(testLeaves[0], []ProofStep{{Hash: testLeaves[1], Position: "up"}}, tree.Root()); err == nil {
+		t.Error("VerifyProof with an invalid position succeeded, want error")
+	}
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Integrity scan results, used as the "result" label
+const (
+	ResultVerified   = "verified"
+	ResultMismatched = "mismatched"
+	ResultUnanchored = "unanchored"
+	ResultError      = "error"
+)
+
+var (
+	// IntegrityScannedLogs counts logs re-verified by the integrity scanner by result
+	IntegrityScannedLogs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "audit_ledger",
+		Subsystem: "integrity",
+		Name:      "scanned_logs_total",
+		Help:      "Logs re-verified by the integrity scanner, by result.",
+	}, []string{"result"})
+
+	// IntegrityLogs is the number of logs whose latest scan had a given result
+	IntegrityLogs = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "audit_ledger",
+		Subsystem: "integrity",
+		Name:      "logs",
+		Help:      "Logs whose most recent integrity scan had the given result.",
+	}, []string{"result"})
+
+	// IntegrityOldestUnverifiedAge is the age of the oldest log never verified by the scanner
+	IntegrityOldestUnverifiedAge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "audit_ledger",
+		Subsystem: "integrity",
+		Name:      "oldest_unverified_log_age_seconds",
+		Help:      "Age of the oldest log that the integrity scanner has not verified yet.",
+	})
+
+	// IntegrityLastScan is the time the integrity scanner last completed a pass
+	IntegrityLastScan = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "audit_ledger",
+		Subsystem: "integrity",
+		Name:      "last_scan_timestamp_seconds",
+		Help:      "Unix time at which the integrity scanner last completed a pass.",
+	})
+)
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/merkle"
+	"github.com/google/uuid"
+)
+
+// AnchorBatch represents a Merkle tree of log hashes whose root is anchored
+// on the blockchain in a single transaction
+type AnchorBatch struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	Root        string     `json:"root" gorm:"size:64;not null"`
+	LeafCount   int        `json:"leaf_count" gorm:"not null"`
+	TxID        *string    `json:"tx_id" gorm:"size:255"`
+	CommittedAt *time.Time `json:"committed_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table name for the AnchorBatch model
+func (AnchorBatch) TableName() string {
+	return "anchor_batches"
+}
+
+// InclusionProofResponse represents a Merkle inclusion proof for a batched log.
+// Leaves are SHA256(0x00 || leaf hash) and interior nodes SHA256(0x01 || left || right),
+// so a log can be checked against the anchored root without trusting the backend.
+type InclusionProofResponse struct {
+	LogID       uuid.UUID          `json:"log_id"`
+	LeafHash    string             `json:"leaf_hash"`
+	LeafIndex   int                `json:"leaf_index"`
+	Siblings    []merkle.ProofStep `json:"siblings"`
+	BatchID     uuid.UUID          `json:"batch_id"`
+	LeafCount   int                `json:"leaf_count"`
+	BatchRoot   string             `json:"batch_root"`
+	RootOnChain string             `json:"root_onchain"`
+	TxID        *string            `json:"tx_id"`
+	CommittedAt *time.Time         `json:"committed_at"`
+	IsValid     bool               `json:"is_valid"`
+	GeneratedAt time.Time          `json:"generated_at"`
+}
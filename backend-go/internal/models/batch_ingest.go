@@ -0,0 +1,44 @@
+package models
+
+import "github.com/google/uuid"
+
+// Batch item statuses
+const (
+	BatchItemCreated  = "created"
+	BatchItemReplayed = "replayed"
+	BatchItemFailed   = "failed"
+)
+
+// BatchCreateLogResult represents the outcome of one item of a batch create
+type BatchCreateLogResult struct {
+	Index  int          `json:"index"`
+	Status string       `json:"status"`
+	Log    *LogResponse `json:"log,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// BatchCreateLogsResponse represents the response for a batch create
+type BatchCreateLogsResponse struct {
+	Total    int                    `json:"total"`
+	Created  int                    `json:"created"`
+	Replayed int                    `json:"replayed"`
+	Failed   int                    `json:"failed"`
+	Results  []BatchCreateLogResult `json:"results"`
+}
+
+// StreamIngestResult represents the outcome of one line of a streaming ingest
+type StreamIngestResult struct {
+	Line   int        `json:"line"`
+	Status string     `json:"status"`
+	LogID  *uuid.UUID `json:"log_id,omitempty"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// StreamIngestSummary represents the totals of a streaming ingest
+type StreamIngestSummary struct {
+	Lines    int    `json:"lines"`
+	Created  int    `json:"created"`
+	Replayed int    `json:"replayed"`
+	Failed   int    `json:"failed"`
+	Error    string `json:"error,omitempty"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GenesisHash is the previous hash of the first log in every source chain
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// Chain issue types reported by chain verification
+const (
+	ChainIssueGap        = "gap"
+	ChainIssueFork       = "fork"
+	ChainIssueBrokenLink = "broken_link"
+	ChainIssueDeleted    = "deleted"
+	ChainIssueTruncated  = "truncated"
+)
+
+// SourceChainHead tracks the latest link of a source's hash chain. Its row is
+// locked while appending so sequence numbers are assigned without gaps.
+type SourceChainHead struct {
+	Source       string    `json:"source" gorm:"primary_key;size:255"`
+	LastSequence int64     `json:"last_sequence" gorm:"not null"`
+	LastHash     string    `json:"last_hash" gorm:"size:64;not null"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for the SourceChainHead model
+func (SourceChainHead) TableName() string {
+	return "source_chain_heads"
+}
+
+// ChainIssue describes a single problem found while walking a source chain
+type ChainIssue struct {
+	Type     string     `json:"type"`
+	Sequence int64      `json:"sequence"`
+	LogID    *uuid.UUID `json:"log_id,omitempty"`
+	Expected string     `json:"expected,omitempty"`
+	Actual   string     `json:"actual,omitempty"`
+}
+
+// ChainVerificationResponse represents the result of walking a source chain
+type ChainVerificationResponse struct {
+	Source          string       `json:"source"`
+	HeadSequence    int64        `json:"head_sequence"`
+	LogsChecked     int64        `json:"logs_checked"`
+	IsValid         bool         `json:"is_valid"`
+	Issues          []ChainIssue `json:"issues"`
+	IssuesTruncated bool         `json:"issues_truncated"`
+	VerifiedAt      time.Time    `json:"verified_at"`
+}
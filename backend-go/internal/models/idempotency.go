@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey records the response to a create request sent with an
+// Idempotency-Key header, so retries of the request get the same response.
+// Keys are scoped to the source of the log, which credentials are bound to,
+// and expire after the configured TTL. The response is kept as text so its
+// bytes are replayed unchanged.
+type IdempotencyKey struct {
+	Source      string    `json:"source" gorm:"primary_key;size:255"`
+	Key         string    `json:"key" gorm:"primary_key;size:255"`
+	RequestHash string    `json:"request_hash" gorm:"size:64;not null"`
+	LogID       uuid.UUID `json:"log_id" gorm:"type:uuid;not null"`
+	Response    string    `json:"response" gorm:"type:text;not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for the IdempotencyKey model
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
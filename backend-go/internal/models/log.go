@@ -1,22 +1,51 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// Hash versions describe how Log.Hash was computed, so rows written under an
+// older scheme remain verifiable
+const (
+	// HashVersionLegacy hashes the payload as re-marshalled by Go, chained
+	// with the previous hash and sequence number when the log has them
+	HashVersionLegacy = 0
+	// HashVersionJCS hashes the RFC 8785 canonical form of the original
+	// payload bytes, chained with the previous hash and sequence number
+	HashVersionJCS = 1
+	// HashVersionEnvelope hashes the canonical form of an envelope binding
+	// the payload to the log ID, source, event type, creation time and chain link
+	HashVersionEnvelope = 2
+)
+
 // Log represents an audit log entry
 type Log struct {
 	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	CreatedAt   time.Time      `json:"created_at" gorm:"not null"`
-	Source      string         `json:"source" gorm:"not null;size:255"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"not null;index"`
+	Source      string         `json:"source" gorm:"not null;size:255;uniqueIndex:idx_logs_source_sequence,priority:1"`
+	Sequence    *int64         `json:"sequence" gorm:"uniqueIndex:idx_logs_source_sequence,priority:2"`
+	PrevHash    *string        `json:"prev_hash" gorm:"size:64"`
 	EventType   string         `json:"event_type" gorm:"not null;size:255"`
+	EventTime   *time.Time     `json:"event_time" gorm:"index"`
 	Payload     string         `json:"payload" gorm:"type:jsonb;not null"`
+	RawPayload  string         `json:"raw_payload" gorm:"type:text"`
 	Hash        string         `json:"hash" gorm:"size:64;not null"`
+	ContentHash string         `json:"content_hash" gorm:"size:64;index"`
+	HashVersion int            `json:"hash_version" gorm:"not null;default:0"`
 	TxID        *string        `json:"tx_id" gorm:"size:255"`
 	CommittedAt *time.Time     `json:"committed_at"`
+	BatchID     *uuid.UUID     `json:"batch_id" gorm:"type:uuid;index"`
+	LeafIndex   *int           `json:"leaf_index"`
+	VerifiedAt  *time.Time     `json:"verified_at" gorm:"index"`
+	ScanStatus  *string        `json:"scan_status" gorm:"size:64"`
+	// Signature is the producer's base64 signature of the log, made with
+	// the registered key SignatureKeyID
+	Signature      *string `json:"signature" gorm:"type:text"`
+	SignatureKeyID *string `json:"signature_key_id" gorm:"size:255;index"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
@@ -31,7 +60,18 @@ type CreateLogRequest struct {
 	LogID     string      `json:"log_id"`
 	Source    string      `json:"source" binding:"required"`
 	EventType string      `json:"event_type" binding:"required"`
-	Payload   interface{} `json:"payload" binding:"required"`
+	// EventTime is when the event occurred at the producer, if it says so
+	EventTime *time.Time  `json:"event_time,omitempty"`
+	Payload   json.RawMessage `json:"payload" binding:"required"`
+	// IdempotencyKey is taken from the Idempotency-Key header
+	IdempotencyKey string `json:"-"`
+	// AllowedSources are the sources the caller may write under, taken from
+	// its credential; nil means any source
+	AllowedSources []string `json:"-"`
+	// Signature is an optional base64 signature by the producer with the
+	// registered key KeyID; signed requests must carry a log_id
+	Signature string `json:"signature,omitempty"`
+	KeyID     string `json:"key_id,omitempty"`
 }
 
 // LogResponse represents the response for log operations
@@ -40,19 +80,104 @@ type LogResponse struct {
 	CreatedAt   time.Time  `json:"created_at"`
 	Source      string     `json:"source"`
 	EventType   string     `json:"event_type"`
+	EventTime   *time.Time `json:"event_time,omitempty"`
 	Payload     interface{} `json:"payload"`
 	Hash        string     `json:"hash"`
+	ContentHash string     `json:"content_hash,omitempty"`
+	HashVersion int        `json:"hash_version"`
+	Sequence    *int64     `json:"sequence,omitempty"`
+	PrevHash    *string    `json:"prev_hash,omitempty"`
 	TxID        *string    `json:"tx_id"`
 	CommittedAt *time.Time `json:"committed_at"`
+	BatchID     *uuid.UUID `json:"batch_id,omitempty"`
+	LeafIndex   *int       `json:"leaf_index,omitempty"`
+	Signature      *string `json:"signature,omitempty"`
+	SignatureKeyID *string `json:"signature_key_id,omitempty"`
+	// Receipt is the signed ingestion receipt returned by CreateLog
+	Receipt string `json:"receipt,omitempty"`
+	// Replayed is set when the response repeats the outcome of an earlier request
+	Replayed bool `json:"-"`
+}
+
+// Verification statuses classify the outcome of comparing the recomputed
+// payload hash, the stored hash column and the ledger
+const (
+	VerificationStatusValid              = "valid"
+	VerificationStatusPayloadTampered    = "payload_tampered"
+	VerificationStatusHashColumnTampered = "hash_column_tampered"
+	VerificationStatusHashMismatch       = "hash_mismatch"
+	VerificationStatusMetadataMismatch   = "metadata_mismatch"
+	VerificationStatusNotAnchored        = "not_anchored"
+	VerificationStatusLedgerUnreachable  = "ledger_unreachable"
+	VerificationStatusSignatureInvalid   = "signature_invalid"
+	VerificationStatusTimestampInvalid   = "timestamp_invalid"
+)
+
+// FieldMismatch describes a metadata field whose database and on-chain values differ
+type FieldMismatch struct {
+	Field    string `json:"field"`
+	OffChain string `json:"offchain"`
+	OnChain  string `json:"onchain"`
+}
+
+// SignerInfo identifies the producer that signed a log and whether its
+// signature still verifies
+type SignerInfo struct {
+	KeyID       string `json:"key_id"`
+	Source      string `json:"source,omitempty"`
+	Algorithm   string `json:"algorithm,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Valid       bool   `json:"valid"`
+	Details     string `json:"details,omitempty"`
 }
 
 // VerificationResponse represents the response for verification operations
 type VerificationResponse struct {
-	ID           uuid.UUID `json:"id"`
-	HashOffChain string    `json:"hash_offchain"`
-	HashOnChain  string    `json:"hash_onchain"`
-	IsValid      bool      `json:"is_valid"`
-	VerifiedAt   time.Time `json:"verified_at"`
+	ID                 uuid.UUID       `json:"id"`
+	HashOffChain       string          `json:"hash_offchain"`
+	HashOnChain        string          `json:"hash_onchain"`
+	HashRecomputed     string          `json:"hash_recomputed,omitempty"`
+	HashProvided       string          `json:"hash_provided,omitempty"`
+	HashVersion        int             `json:"hash_version"`
+	IsValid            bool            `json:"is_valid"`
+	Status             string          `json:"status"`
+	MetadataMismatches []FieldMismatch `json:"metadata_mismatches,omitempty"`
+	Signer             *SignerInfo     `json:"signer,omitempty"`
+	Timestamp          *TimestampInfo  `json:"timestamp,omitempty"`
+	Details            string          `json:"details,omitempty"`
+	VerifiedAt         time.Time       `json:"verified_at"`
+}
+
+// VerifyLogRequest represents a caller's copy of a log to check against the
+// ledger: either its hash or its full original payload
+type VerifyLogRequest struct {
+	Hash    string          `json:"hash"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// ContentLookupRequest represents a document whose anchoring is looked up
+type ContentLookupRequest struct {
+	Payload json.RawMessage `json:"payload" binding:"required"`
+}
+
+// LedgerEntry represents a log hash entry found on the blockchain
+type LedgerEntry struct {
+	LogID     string            `json:"log_id"`
+	Hash      string            `json:"hash"`
+	TxID      string            `json:"tx_id"`
+	Timestamp string            `json:"timestamp"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
+// ContentLookupResponse represents every log and ledger entry carrying the
+// hash of a document
+type ContentLookupResponse struct {
+	ContentHash   string        `json:"content_hash"`
+	Anchored      bool          `json:"anchored"`
+	Logs          []LogResponse `json:"logs"`
+	LedgerEntries []LedgerEntry `json:"ledger_entries"`
+	LedgerError   string        `json:"ledger_error,omitempty"`
+	CheckedAt     time.Time     `json:"checked_at"`
 }
 
 // ListLogsResponse represents the response for listing logs
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Outbox entry statuses
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusCompleted = "completed"
+)
+
+// OutboxEntry represents a pending blockchain commit for a log or, in
+// batched anchoring mode, for the Merkle root of a batch of logs. Exactly one
+// of LogID and BatchID is set. It is written in the same database transaction
+// as the rows it anchors so that they can never be saved without a durable
+// record of the commit they still need.
+type OutboxEntry struct {
+	ID            uint       `json:"id" gorm:"primary_key"`
+	LogID         *uuid.UUID `json:"log_id" gorm:"type:uuid;uniqueIndex"`
+	BatchID       *uuid.UUID `json:"batch_id" gorm:"type:uuid;uniqueIndex"`
+	Hash          string     `json:"hash" gorm:"size:64;not null"`
+	LeafCount     int        `json:"leaf_count" gorm:"not null;default:0"`
+	Metadata      string     `json:"metadata" gorm:"type:jsonb;not null"`
+	Status        string     `json:"status" gorm:"size:32;not null;index:idx_outbox_pending,priority:1"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"not null;index:idx_outbox_pending,priority:2"`
+	LastError     *string    `json:"last_error" gorm:"type:text"`
+	ProcessedAt   *time.Time `json:"processed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table name for the OutboxEntry model
+func (OutboxEntry) TableName() string {
+	return "log_outbox"
+}
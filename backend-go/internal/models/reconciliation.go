@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reconciliation actions taken for an unanchored log
+const (
+	ReconcileActionBackfilled  = "backfilled"
+	ReconcileActionRecommitted = "recommitted"
+	ReconcileActionMismatch    = "hash_mismatch"
+	ReconcileActionFailed      = "failed"
+)
+
+// ReconciliationResult describes what the reconciler did for a single log or batch
+type ReconciliationResult struct {
+	LogID   *uuid.UUID `json:"log_id,omitempty"`
+	BatchID *uuid.UUID `json:"batch_id,omitempty"`
+	Action  string     `json:"action"`
+	TxID    string     `json:"tx_id,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// ReconciliationReport summarizes a reconciliation run
+type ReconciliationReport struct {
+	StartedAt   time.Time              `json:"started_at"`
+	CompletedAt time.Time              `json:"completed_at"`
+	Scanned     int                    `json:"scanned"`
+	Backfilled  int                    `json:"backfilled"`
+	Recommitted int                    `json:"recommitted"`
+	Mismatched  int                    `json:"mismatched"`
+	Failed      int                    `json:"failed"`
+	Results     []ReconciliationResult `json:"results"`
+}
+
+// Add records a result and updates the summary counters
+func (r *ReconciliationReport) Add(result ReconciliationResult) {
+	r.Scanned++
+	switch result.Action {
+	case ReconcileActionBackfilled:
+		r.Backfilled++
+	case ReconcileActionRecommitted:
+		r.Recommitted++
+	case ReconcileActionMismatch:
+		r.Mismatched++
+	default:
+		r.Failed++
+	}
+	r.Results = append(r.Results, result)
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// SigningKey is a producer's public key registered for a source. Logs of a
+// source with active keys must be signed with one of them.
+type SigningKey struct {
+	ID          string     `json:"key_id" gorm:"primary_key;size:255"`
+	Source      string     `json:"source" gorm:"not null;size:255;index"`
+	Algorithm   string     `json:"algorithm" gorm:"not null;size:32"`
+	PublicKey   string     `json:"public_key" gorm:"type:text;not null"`
+	Fingerprint string     `json:"fingerprint" gorm:"size:64;not null"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// TableName returns the table name for the SigningKey model
+func (SigningKey) TableName() string {
+	return "signing_keys"
+}
+
+// RegisterSigningKeyRequest represents the request payload for registering a
+// source's public key in PEM form
+type RegisterSigningKeyRequest struct {
+	KeyID     string `json:"key_id" binding:"required"`
+	PublicKey string `json:"public_key" binding:"required"`
+}
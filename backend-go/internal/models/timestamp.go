@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimestampToken is an RFC 3161 time-stamp token obtained for the hash of an
+// individually anchored log or for the root of an anchor batch
+type TimestampToken struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	LogID        *uuid.UUID `json:"log_id,omitempty" gorm:"type:uuid;uniqueIndex"`
+	BatchID      *uuid.UUID `json:"batch_id,omitempty" gorm:"type:uuid;uniqueIndex"`
+	Hash         string     `json:"hash" gorm:"size:64;not null"`
+	Token        []byte     `json:"-" gorm:"not null"`
+	GenTime      time.Time  `json:"gen_time" gorm:"not null"`
+	SerialNumber string     `json:"serial_number" gorm:"size:128"`
+	Policy       string     `json:"policy" gorm:"size:255"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// TableName returns the table name for the TimestampToken model
+func (TimestampToken) TableName() string {
+	return "timestamp_tokens"
+}
+
+// TimestampAttempt records a log hash or batch root the TSA refused or
+// answered with an invalid token, so that it is retried with backoff
+// instead of holding back the hashes after it
+type TimestampAttempt struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	LogID         *uuid.UUID `json:"log_id,omitempty" gorm:"type:uuid;uniqueIndex"`
+	BatchID       *uuid.UUID `json:"batch_id,omitempty" gorm:"type:uuid;uniqueIndex"`
+	Attempts      int        `json:"attempts" gorm:"not null"`
+	LastError     string     `json:"last_error" gorm:"type:text"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"not null;index"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table name for the TimestampAttempt model
+func (TimestampAttempt) TableName() string {
+	return "timestamp_attempts"
+}
+
+// TimestampInfo reports the trusted timestamp of a log and whether its
+// token still verifies
+type TimestampInfo struct {
+	GenTime      time.Time  `json:"gen_time"`
+	SerialNumber string     `json:"serial_number,omitempty"`
+	Policy       string     `json:"policy,omitempty"`
+	Authority    string     `json:"authority,omitempty"`
+	Hash         string     `json:"hash"`
+	BatchID      *uuid.UUID `json:"batch_id,omitempty"`
+	Valid        bool       `json:"valid"`
+	Details      string     `json:"details,omitempty"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Verification job statuses
+const (
+	VerificationJobPending   = "pending"
+	VerificationJobRunning   = "running"
+	VerificationJobCompleted = "completed"
+	VerificationJobFailed    = "failed"
+)
+
+// VerificationJob represents an asynchronous verification of every log
+// matching a filter
+type VerificationJob struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	Status      string     `json:"status" gorm:"size:32;not null"`
+	From        *time.Time `json:"from"`
+	To          *time.Time `json:"to"`
+	Source      string     `json:"source" gorm:"size:255"`
+	EventType   string     `json:"event_type" gorm:"size:255"`
+	Total       int64      `json:"total"`
+	Processed   int64      `json:"processed"`
+	Valid       int64      `json:"valid"`
+	Invalid     int64      `json:"invalid"`
+	Unanchored  int64      `json:"unanchored"`
+	Errored     int64      `json:"errored"`
+	Error       *string    `json:"error" gorm:"type:text"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table name for the VerificationJob model
+func (VerificationJob) TableName() string {
+	return "verification_jobs"
+}
+
+// VerificationJobFailure records a log that did not verify as valid during a job
+type VerificationJobFailure struct {
+	ID             uint      `json:"-" gorm:"primary_key"`
+	JobID          uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	LogID          uuid.UUID `json:"log_id" gorm:"type:uuid;not null"`
+	Status         string    `json:"status" gorm:"size:64;not null"`
+	HashOffChain   string    `json:"hash_offchain" gorm:"size:64"`
+	HashOnChain    string    `json:"hash_onchain" gorm:"size:64"`
+	HashRecomputed string    `json:"hash_recomputed" gorm:"size:64"`
+	Details        string    `json:"details" gorm:"type:text"`
+}
+
+// TableName returns the table name for the VerificationJobFailure model
+func (VerificationJobFailure) TableName() string {
+	return "verification_job_failures"
+}
+
+// CreateVerificationJobRequest represents the filter of a verification job
+type CreateVerificationJobRequest struct {
+	From      *time.Time `json:"from"`
+	To        *time.Time `json:"to"`
+	Source    string     `json:"source"`
+	EventType string     `json:"event_type"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+// Webhook is a named inbound webhook. Deliveries are signed with Secret and
+// mapped onto a log through the templates and payload selection; the source
+// they map to must be one of AllowedSources, or the fixed source template.
+type Webhook struct {
+	Name                string    `json:"name" gorm:"primary_key;size:100"`
+	Secret              string    `json:"-" gorm:"not null"`
+	SignatureHeader     string    `json:"signature_header" gorm:"size:100;not null"`
+	TimestampHeader     string    `json:"timestamp_header" gorm:"size:100;not null"`
+	ReplayWindowSeconds int       `json:"replay_window_seconds" gorm:"not null"`
+	SourceTemplate      string    `json:"source_template" gorm:"not null"`
+	AllowedSources      []string  `json:"allowed_sources,omitempty" gorm:"serializer:json;type:jsonb"`
+	EventTypeTemplate   string    `json:"event_type_template" gorm:"not null"`
+	PayloadPath         string    `json:"payload_path,omitempty"`
+	PayloadFields       []string  `json:"payload_fields,omitempty" gorm:"serializer:json;type:jsonb"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for the Webhook model
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// RegisterWebhookRequest represents the request payload for registering or
+// replacing a webhook
+type RegisterWebhookRequest struct {
+	Name                string   `json:"name" binding:"required"`
+	Secret              string   `json:"secret" binding:"required"`
+	SignatureHeader     string   `json:"signature_header"`
+	TimestampHeader     string   `json:"timestamp_header"`
+	ReplayWindowSeconds int      `json:"replay_window_seconds"`
+	SourceTemplate      string   `json:"source_template" binding:"required"`
+	AllowedSources      []string `json:"allowed_sources"`
+	EventTypeTemplate   string   `json:"event_type_template" binding:"required"`
+	PayloadPath         string   `json:"payload_path"`
+	PayloadFields       []string `json:"payload_fields"`
+}
@@ -0,0 +1,222 @@
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/google/uuid"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+// logIDNamespace derives log IDs from record content, so an exporter
+// retrying an export replays the logs it already created
+var logIDNamespace = uuid.MustParse("4f0c6a52-8a7e-4d43-9a36-0c1f6e2b7d15")
+
+// maxSafeInteger is the largest integer a double represents exactly; larger
+// attribute values are stored as strings so their hash stays reproducible
+const maxSafeInteger = 1<<53 - 1
+
+// Mapping decides the source and event type of the logs created from OTLP
+// log records
+type Mapping struct {
+	// SourceAttribute is the resource attribute holding the source
+	SourceAttribute string
+	// EventTypeAttribute is the record attribute holding the event type when
+	// the record has no event name
+	EventTypeAttribute string
+	DefaultSource      string
+	DefaultEventType   string
+}
+
+// Record is the payload stored for one OTLP log record
+type Record struct {
+	Time           string                 `json:"time,omitempty"`
+	ObservedTime   string                 `json:"observed_time,omitempty"`
+	SeverityNumber int32                  `json:"severity_number,omitempty"`
+	SeverityText   string                 `json:"severity_text,omitempty"`
+	EventName      string                 `json:"event_name,omitempty"`
+	Body           interface{}            `json:"body,omitempty"`
+	Attributes     map[string]interface{} `json:"attributes,omitempty"`
+	Resource       map[string]interface{} `json:"resource,omitempty"`
+	Scope          *Scope                 `json:"scope,omitempty"`
+	TraceID        string                 `json:"trace_id,omitempty"`
+	SpanID         string                 `json:"span_id,omitempty"`
+	Flags          uint32                 `json:"flags,omitempty"`
+}
+
+// Scope is the instrumentation scope that emitted a record
+type Scope struct {
+	Name       string                 `json:"name,omitempty"`
+	Version    string                 `json:"version,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// Request converts a log record, with the resource and scope it was exported
+// under, into a create request. Only fields of the export are used, so the
+// same record always maps to the same log ID and payload.
+func (m Mapping) Request(resource map[string]interface{}, scope *Scope, record *logspb.LogRecord) (*models.CreateLogRequest, error) {
+	payload := Record{
+		Time:           formatUnixNano(record.GetTimeUnixNano()),
+		ObservedTime:   formatUnixNano(record.GetObservedTimeUnixNano()),
+		SeverityNumber: int32(record.GetSeverityNumber()),
+		SeverityText:   record.GetSeverityText(),
+		EventName:      record.GetEventName(),
+		Body:           anyValue(record.GetBody()),
+		Attributes:     attributes(record.GetAttributes()),
+		Resource:       resource,
+		Scope:          scope,
+		TraceID:        hex.EncodeToString(record.GetTraceId()),
+		SpanID:         hex.EncodeToString(record.GetSpanId()),
+		Flags:          record.GetFlags(),
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal log record: %w", err)
+	}
+
+	source := stringAttribute(resource, m.SourceAttribute)
+	if source == "" {
+		source = m.DefaultSource
+	}
+	eventType := payload.EventName
+	if eventType == "" {
+		eventType = stringAttribute(payload.Attributes, m.EventTypeAttribute)
+	}
+	if eventType == "" {
+		eventType = m.DefaultEventType
+	}
+
+	name := make([]byte, 0, len(source)+len(eventType)+len(body)+2)
+	name = append(name, source...)
+	name = append(name, 0)
+	name = append(name, eventType...)
+	name = append(name, 0)
+	name = append(name, body...)
+
+	return &models.CreateLogRequest{
+		LogID:     uuid.NewSHA1(logIDNamespace, name).String(),
+		Source:    source,
+		EventType: eventType,
+		Payload:   body,
+	}, nil
+}
+
+// ScopeOf converts an instrumentation scope, returning nil for an empty one
+func ScopeOf(scope *commonpb.InstrumentationScope) *Scope {
+	if scope.GetName() == "" && scope.GetVersion() == "" && len(scope.GetAttributes()) == 0 {
+		return nil
+	}
+	return &Scope{
+		Name:       scope.GetName(),
+		Version:    scope.GetVersion(),
+		Attributes: attributes(scope.GetAttributes()),
+	}
+}
+
+// attributes converts key-value pairs into a map, or nil if there are none
+func attributes(kvs []*commonpb.KeyValue) map[string]interface{} {
+	if len(kvs) == 0 {
+		return nil
+	}
+	values := make(map[string]interface{}, len(kvs))
+	for _, kv := range kvs {
+		values[kv.GetKey()] = anyValue(kv.GetValue())
+	}
+	return values
+}
+
+// anyValue converts an OTLP value into its JSON form. Bytes are base64
+// encoded, and integers and doubles JSON cannot hold exactly become strings.
+func anyValue(value *commonpb.AnyValue) interface{} {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return v.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		if v.IntValue > maxSafeInteger || v.IntValue < -maxSafeInteger {
+			return fmt.Sprintf("%d", v.IntValue)
+		}
+		return v.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		if math.IsNaN(v.DoubleValue) || math.IsInf(v.DoubleValue, 0) {
+			return fmt.Sprintf("%v", v.DoubleValue)
+		}
+		return v.DoubleValue
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]interface{}, 0, len(v.ArrayValue.GetValues()))
+		for _, item := range v.ArrayValue.GetValues() {
+			values = append(values, anyValue(item))
+		}
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		values := attributes(v.KvlistValue.GetValues())
+		if values == nil {
+			values = map[string]interface{}{}
+		}
+		return values
+	case *commonpb.AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(v.BytesValue)
+	default:
+		return nil
+	}
+}
+
+// stringAttribute returns an attribute as a string, or "" if it is missing
+func stringAttribute(values map[string]interface{}, key string) string {
+	if key == "" {
+		return ""
+	}
+	switch v := values[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatUnixNano formats a timestamp in nanoseconds, or "" if it is unset
+func formatUnixNano(ns uint64) string {
+	if ns == 0 {
+		return ""
+	}
+	return time.Unix(0, int64(ns)).UTC().Format(time.RFC3339Nano)
+}
+
+// fixJSONIDs re-decodes trace and span IDs of a JSON export. OTLP/JSON
+// encodes them as hex while protojson reads bytes as base64; hex IDs are
+// valid unpadded base64, so re-encoding the decoded bytes recovers the hex.
+func fixJSONIDs(resourceLogs []*logspb.ResourceLogs) error {
+	decode := func(id []byte) ([]byte, error) {
+		if len(id) == 0 {
+			return id, nil
+		}
+		return hex.DecodeString(base64.StdEncoding.EncodeToString(id))
+	}
+
+	for _, resourceLog := range resourceLogs {
+		for _, scopeLog := range resourceLog.GetScopeLogs() {
+			for _, record := range scopeLog.GetLogRecords() {
+				traceID, err := decode(record.TraceId)
+				if err != nil {
+					return fmt.Errorf("invalid traceId: %w", err)
+				}
+				spanID, err := decode(record.SpanId)
+				if err != nil {
+					return fmt.Errorf("invalid spanId: %w", err)
+				}
+				record.TraceId, record.SpanId = traceID, spanID
+			}
+		}
+	}
+	return nil
+}
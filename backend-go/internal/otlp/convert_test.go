@@ -0,0 +1,274 @@
+package otlp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var testMapping = Mapping{
+	SourceAttribute:    "service.name",
+	EventTypeAttribute: "event.name",
+	DefaultSource:      "otel",
+	DefaultEventType:   "otel.log",
+}
+
+func stringValue(s string) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}}
+}
+
+func keyValue(key string, value *commonpb.AnyValue) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: value}
+}
+
+func TestMappingRequestSourceAndEventType(t *testing.T) {
+	payments := map[string]interface{}{"service.name": "payments"}
+
+	tests := []struct {
+		name      string
+		resource  map[string]interface{}
+		record    *logspb.LogRecord
+		source    string
+		eventType string
+	}{
+		{
+			name:      "event name",
+			resource:  payments,
+			record:    &logspb.LogRecord{EventName: "transfer.completed", Attributes: []*commonpb.KeyValue{keyValue("event.name", stringValue("ignored"))}},
+			source:    "payments",
+			eventType: "transfer.completed",
+		},
+		{
+			name:      "event type attribute",
+			resource:  payments,
+			record:    &logspb.LogRecord{Attributes: []*commonpb.KeyValue{keyValue("event.name", stringValue("transfer.failed"))}},
+			source:    "payments",
+			eventType: "transfer.failed",
+		},
+		{
+			name:      "non-string source attribute",
+			resource:  map[string]interface{}{"service.name": int64(7)},
+			record:    &logspb.LogRecord{},
+			source:    "7",
+			eventType: "otel.log",
+		},
+		{
+			name:      "defaults",
+			resource:  nil,
+			record:    &logspb.LogRecord{},
+			source:    "otel",
+			eventType: "otel.log",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := testMapping.Request(tt.resource, nil, tt.record)
+			if err != nil {
+				t.Fatalf("Request: %v", err)
+			}
+			if req.Source != tt.source || req.EventType != tt.eventType {
+				t.Errorf("Request = %s/%s, want %s/%s", req.Source, req.EventType, tt.source, tt.eventType)
+			}
+		})
+	}
+}
+
+func TestMappingRequestPayload(t *testing.T) {
+	traceID, _ := hex.DecodeString("5b8efff798038103d269b633813fc60c")
+	spanID, _ := hex.DecodeString("eee19b7ec3c1b174")
+	record := &logspb.LogRecord{
+		TimeUnixNano:         1741064767123456789,
+		ObservedTimeUnixNano: 1741064768000000000,
+		SeverityNumber:       logspb.SeverityNumber_SEVERITY_NUMBER_WARN,
+		SeverityText:         "WARN",
+		Body:                 stringValue("limit exceeded"),
+		Attributes: []*commonpb.KeyValue{
+			keyValue("account", stringValue("ACC-1")),
+			keyValue("attempts", &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: 3}}),
+		},
+		TraceId: traceID,
+		SpanId:  spanID,
+		Flags:   1,
+	}
+	resource := map[string]interface{}{"service.name": "payments"}
+	scope := ScopeOf(&commonpb.InstrumentationScope{Name: "io.example.payments", Version: "1.2.0"})
+
+	req, err := testMapping.Request(resource, scope, record)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(req.Payload, &payload); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	want := map[string]interface{}{
+		"time":            "2025-03-04T05:06:07.123456789Z",
+		"observed_time":   "2025-03-04T05:06:08Z",
+		"severity_number": float64(13),
+		"severity_text":   "WARN",
+		"body":            "limit exceeded",
+		"attributes":      map[string]interface{}{"account": "ACC-1", "attempts": float64(3)},
+		"resource":        map[string]interface{}{"service.name": "payments"},
+		"scope":           map[string]interface{}{"name": "io.example.payments", "version": "1.2.0"},
+		"trace_id":        "5b8efff798038103d269b633813fc60c",
+		"span_id":         "eee19b7ec3c1b174",
+		"flags":           float64(1),
+	}
+	if !reflect.DeepEqual(payload, want) {
+		t.Errorf("payload =\n%v\nwant\n%v", payload, want)
+	}
+
+	// Unset fields are left out
+	req, err = testMapping.Request(nil, nil, &logspb.LogRecord{})
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if string(req.Payload) != `{}` {
+		t.Errorf("empty record payload = %s, want {}", req.Payload)
+	}
+}
+
+func TestMappingRequestLogIDIsDerivedFromRecord(t *testing.T) {
+	resource := map[string]interface{}{"service.name": "payments"}
+	record := func(body string) *logspb.LogRecord {
+		return &logspb.LogRecord{TimeUnixNano: 1741064767000000000, Body: stringValue(body)}
+	}
+
+	first, err := testMapping.Request(resource, nil, record("a"))
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	retried, err := testMapping.Request(resource, nil, record("a"))
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if first.LogID != retried.LogID {
+		t.Errorf("retried export maps to %s, want %s", retried.LogID, first.LogID)
+	}
+
+	other, _ := testMapping.Request(resource, nil, record("b"))
+	elsewhere, _ := testMapping.Request(map[string]interface{}{"service.name": "cards"}, nil, record("a"))
+	if other.LogID == first.LogID || elsewhere.LogID == first.LogID {
+		t.Error("different records map to the same log ID")
+	}
+}
+
+func TestAnyValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value *commonpb.AnyValue
+		want  interface{}
+	}{
+		{"string", stringValue("s"), "s"},
+		{"bool", &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: true}}, true},
+		{"safe integer", &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: maxSafeInteger}}, int64(maxSafeInteger)},
+		{"large integer", &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: maxSafeInteger + 1}}, "9007199254740992"},
+		{"large negative integer", &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: -maxSafeInteger - 1}}, "-9007199254740992"},
+		{"double", &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: 1.5}}, 1.5},
+		{"NaN", &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: math.NaN()}}, "NaN"},
+		{"infinity", &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: math.Inf(-1)}}, "-Inf"},
+		{"bytes", &commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: []byte{0xde, 0xad}}}, "3q0="},
+		{"array", &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{
+			Values: []*commonpb.AnyValue{stringValue("a"), {Value: &commonpb.AnyValue_BoolValue{BoolValue: false}}},
+		}}}, []interface{}{"a", false}},
+		{"empty array", &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{}}}, []interface{}{}},
+		{"kvlist", &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{
+			Values: []*commonpb.KeyValue{keyValue("k", stringValue("v"))},
+		}}}, map[string]interface{}{"k": "v"}},
+		{"empty kvlist", &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{}}}, map[string]interface{}{}},
+		{"unset", &commonpb.AnyValue{}, nil},
+		{"nil", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := anyValue(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("anyValue = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopeOfEmptyScope(t *testing.T) {
+	if scope := ScopeOf(&commonpb.InstrumentationScope{}); scope != nil {
+		t.Errorf("ScopeOf(empty) = %+v, want nil", scope)
+	}
+	if scope := ScopeOf(nil); scope != nil {
+		t.Errorf("ScopeOf(nil) = %+v, want nil", scope)
+	}
+}
+
+// decodeJSONExport decodes an OTLP/JSON export the way the receiver does
+func decodeJSONExport(t *testing.T, body string) (*collectorpb.ExportLogsServiceRequest, error) {
+	t.Helper()
+	request := &collectorpb.ExportLogsServiceRequest{}
+	if err := protojson.Unmarshal([]byte(body), request); err != nil {
+		t.Fatalf("protojson: %v", err)
+	}
+	return request, fixJSONIDs(request.GetResourceLogs())
+}
+
+func jsonExport(traceID, spanID string) string {
+	return `{"resourceLogs": [{"scopeLogs": [{"logRecords": [
+		{"traceId": "` + traceID + `", "spanId": "` + spanID + `", "body": {"stringValue": "first"}},
+		{"body": {"stringValue": "second"}}
+	]}]}]}`
+}
+
+func TestFixJSONIDs(t *testing.T) {
+	traceID, spanID := "5b8efff798038103d269b633813fc60c", "eee19b7ec3c1b174"
+	request, err := decodeJSONExport(t, jsonExport(traceID, spanID))
+	if err != nil {
+		t.Fatalf("fixJSONIDs: %v", err)
+	}
+
+	records := request.GetResourceLogs()[0].GetScopeLogs()[0].GetLogRecords()
+	wantTrace, _ := hex.DecodeString(traceID)
+	wantSpan, _ := hex.DecodeString(spanID)
+	if !bytes.Equal(records[0].TraceId, wantTrace) || !bytes.Equal(records[0].SpanId, wantSpan) {
+		t.Errorf("IDs = %x/%x, want %s/%s", records[0].TraceId, records[0].SpanId, traceID, spanID)
+	}
+	// Records without IDs keep none
+	if len(records[1].TraceId) != 0 || len(records[1].SpanId) != 0 {
+		t.Errorf("IDs = %x/%x, want none", records[1].TraceId, records[1].SpanId)
+	}
+
+	// The stored payload carries the IDs as the exporter sent them
+	req, err := testMapping.Request(nil, nil, records[0])
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if !strings.Contains(string(req.Payload), `"trace_id":"`+traceID+`"`) || !strings.Contains(string(req.Payload), `"span_id":"`+spanID+`"`) {
+		t.Errorf("payload = %s, want the hex IDs", req.Payload)
+	}
+}
+
+func TestFixJSONIDsRejectsNonHexIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		traceID string
+		spanID  string
+		want    string
+	}{
+		// Valid base64, so protojson accepts them, but not hex
+		{"trace ID", "zzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzz", "eee19b7ec3c1b174", "invalid traceId"},
+		{"span ID", "5b8efff798038103d269b633813fc60c", "zzzzzzzzzzzzzzzz", "invalid spanId"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeJSONExport(t, jsonExport(tt.traceID, tt.spanID))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("fixJSONIDs = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package otlp
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/banking-audit-ledger/backend/internal/auth"
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/banking-audit-ledger/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// Receiver implements the OTLP/HTTP logs endpoint. Every log record of an
+// export becomes a log, anchored like logs created through the REST API.
+type Receiver struct {
+	cfg        config.OTLPConfig
+	mapping    Mapping
+	logService *services.LogService
+	logger     *logrus.Logger
+}
+
+// NewReceiver creates a new OTLP logs receiver
+func NewReceiver(cfg config.OTLPConfig, logService *services.LogService, logger *logrus.Logger) *Receiver {
+	return &Receiver{
+		cfg: cfg,
+		mapping: Mapping{
+			SourceAttribute:    cfg.SourceAttribute,
+			EventTypeAttribute: cfg.EventTypeAttribute,
+			DefaultSource:      cfg.DefaultSource,
+			DefaultEventType:   cfg.DefaultEventType,
+		},
+		logService: logService,
+		logger:     logger,
+	}
+}
+
+// Export handles POST /v1/logs with a protobuf or JSON encoded
+// ExportLogsServiceRequest. Records that cannot be stored are reported
+// through partial success; a storage failure is answered with 503 so the
+// exporter retries, which replays the records already created.
+func (r *Receiver) Export(c *gin.Context) {
+	contentType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if contentType != contentTypeProtobuf && contentType != contentTypeJSON {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Unsupported content type", "details": "use application/x-protobuf or application/json"})
+		return
+	}
+
+	body, err := r.readBody(c)
+	if err != nil {
+		r.writeStatus(c, contentType, http.StatusBadRequest, codes.InvalidArgument, err.Error())
+		return
+	}
+
+	request := &collectorpb.ExportLogsServiceRequest{}
+	if contentType == contentTypeProtobuf {
+		err = proto.Unmarshal(body, request)
+	} else {
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(body, request)
+		if err == nil {
+			err = fixJSONIDs(request.GetResourceLogs())
+		}
+	}
+	if err != nil {
+		r.writeStatus(c, contentType, http.StatusBadRequest, codes.InvalidArgument, fmt.Sprintf("invalid export request: %v", err))
+		return
+	}
+
+	var firstError string
+	var allowedSources []string
+	if principal := auth.PrincipalFrom(c); principal != nil {
+		allowedSources = principal.AllowedSources()
+	}
+
+	summary, err := r.logService.Ingest(r.records(request, allowedSources), func(result models.StreamIngestResult) error {
+		if result.Status == models.BatchItemFailed && firstError == "" {
+			firstError = fmt.Sprintf("log record %d: %s", result.Line, result.Error)
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("component", "otlp").Error("Failed to ingest OTLP logs")
+		r.writeStatus(c, contentType, http.StatusServiceUnavailable, codes.Unavailable, err.Error())
+		return
+	}
+
+	response := &collectorpb.ExportLogsServiceResponse{}
+	if summary.Failed > 0 {
+		response.PartialSuccess = &collectorpb.ExportLogsPartialSuccess{
+			RejectedLogRecords: int64(summary.Failed),
+			ErrorMessage:       firstError,
+		}
+	}
+	r.write(c, contentType, http.StatusOK, response)
+}
+
+// records returns an ingest iterator over the log records of an export,
+// restricted to allowedSources unless it is nil. Records are numbered from 1
+// in export order.
+func (r *Receiver) records(request *collectorpb.ExportLogsServiceRequest, allowedSources []string) func() (services.IngestItem, error) {
+	type exported struct {
+		resource map[string]interface{}
+		scope    *Scope
+		record   *logspb.LogRecord
+	}
+	var records []exported
+	for _, resourceLog := range request.GetResourceLogs() {
+		resource := attributes(resourceLog.GetResource().GetAttributes())
+		for _, scopeLog := range resourceLog.GetScopeLogs() {
+			scope := ScopeOf(scopeLog.GetScope())
+			for _, record := range scopeLog.GetLogRecords() {
+				records = append(records, exported{resource, scope, record})
+			}
+		}
+	}
+
+	n := 0
+	return func() (services.IngestItem, error) {
+		if n == len(records) {
+			return services.IngestItem{}, io.EOF
+		}
+		next := records[n]
+		n++
+
+		item := services.IngestItem{Line: n}
+		req, err := r.mapping.Request(next.resource, next.scope, next.record)
+		if err != nil {
+			item.Err = err
+		} else {
+			item.Req = *req
+			item.Req.AllowedSources = allowedSources
+		}
+		return item, nil
+	}
+}
+
+// readBody reads the request body, decompressing gzip, up to the
+// configured size
+func (r *Receiver) readBody(c *gin.Context) ([]byte, error) {
+	var reader io.Reader = c.Request.Body
+	switch strings.ToLower(c.GetHeader("Content-Encoding")) {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", c.GetHeader("Content-Encoding"))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, r.cfg.MaxBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if int64(len(body)) > r.cfg.MaxBodyBytes {
+		return nil, errors.New("export request exceeds the maximum body size")
+	}
+	return body, nil
+}
+
+// writeStatus answers with a google.rpc.Status, as OTLP/HTTP requires for errors
+func (r *Receiver) writeStatus(c *gin.Context, contentType string, httpStatus int, code codes.Code, message string) {
+	r.write(c, contentType, httpStatus, status.New(code, message).Proto())
+}
+
+// write encodes a message in the content type of the request
+func (r *Receiver) write(c *gin.Context, contentType string, httpStatus int, message proto.Message) {
+	var body []byte
+	var err error
+	if contentType == contentTypeProtobuf {
+		body, err = proto.Marshal(message)
+	} else {
+		body, err = protojson.Marshal(message)
+	}
+	if err != nil {
+		r.logger.WithError(err).WithField("component", "otlp").Error("Failed to encode OTLP response")
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Data(httpStatus, contentType, body)
+}
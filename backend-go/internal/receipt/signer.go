@@ -0,0 +1,238 @@
+package receipt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Commit statuses of a receipt
+const (
+	CommitStatusCommitted = "committed"
+	CommitStatusPending   = "pending"
+)
+
+// Claims are the claims of an ingestion receipt. The receipt is a JWS in
+// compact form over these claims, signed with a key from the JWKS.
+type Claims struct {
+	LogID        string `json:"log_id"`
+	Source       string `json:"source"`
+	Hash         string `json:"hash"`
+	ReceivedAt   string `json:"received_at"`
+	TxID         string `json:"tx_id,omitempty"`
+	BatchID      string `json:"batch_id,omitempty"`
+	CommitStatus string `json:"commit_status"`
+	jwt.RegisteredClaims
+}
+
+// signingKey is a receipt signing key loaded from the keys directory
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	writtenAt time.Time
+}
+
+// Signer issues signed receipts for created logs. Its keys live as PEM
+// files in a directory, named after their key ID; the most recently written
+// key signs and every key is published so older receipts stay verifiable.
+type Signer struct {
+	dir    string
+	issuer string
+	logger *logrus.Logger
+
+	mu     sync.RWMutex
+	keys   []signingKey
+	active *signingKey
+}
+
+// NewSigner loads the receipt signing keys, generating a first one when the
+// directory holds none
+func NewSigner(cfg config.ReceiptConfig, logger *logrus.Logger) (*Signer, error) {
+	if err := os.MkdirAll(cfg.KeysDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create receipt keys directory: %w", err)
+	}
+
+	signer := &Signer{
+		dir:    cfg.KeysDir,
+		issuer: cfg.Issuer,
+		logger: logger,
+	}
+	if err := signer.load(); err != nil {
+		return nil, err
+	}
+	if signer.active == nil {
+		if _, err := signer.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+	return signer, nil
+}
+
+// Issue signs a receipt for a log. A log is committed once it has a
+// transaction ID; until then its anchoring is pending in the outbox or batch.
+func (s *Signer) Issue(log *models.LogResponse) (string, error) {
+	claims := Claims{
+		LogID:        log.ID.String(),
+		Source:       log.Source,
+		Hash:         log.Hash,
+		ReceivedAt:   log.CreatedAt.UTC().Format(time.RFC3339Nano),
+		CommitStatus: CommitStatusPending,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:   s.issuer,
+			Subject:  log.ID.String(),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	}
+	if log.TxID != nil && *log.TxID != "" {
+		claims.TxID = *log.TxID
+		claims.CommitStatus = CommitStatusCommitted
+	}
+	if log.BatchID != nil {
+		claims.BatchID = log.BatchID.String()
+	}
+
+	s.mu.RLock()
+	key := s.active
+	s.mu.RUnlock()
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	signed, err := token.SignedString(key.private)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign receipt: %w", err)
+	}
+	return signed, nil
+}
+
+// Rotate generates a new Ed25519 key, writes it to the keys directory and
+// makes it the signing key. Earlier keys stay published until their files
+// are removed.
+func (s *Signer) Rotate() (string, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate receipt key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode receipt key: %w", err)
+	}
+
+	now := time.Now().UTC()
+	id := "receipt-" + now.Format("20060102T150405.000Z")
+	file, err := os.OpenFile(filepath.Join(s.dir, id+".pem"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create receipt key file: %w", err)
+	}
+	if err := pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write receipt key: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write receipt key: %w", err)
+	}
+
+	s.mu.Lock()
+	s.keys = append(s.keys, signingKey{id: id, method: jwt.SigningMethodEdDSA, private: private, writtenAt: now})
+	s.active = &s.keys[len(s.keys)-1]
+	s.mu.Unlock()
+
+	s.logger.WithFields(logrus.Fields{"component": "receipt", "keyID": id}).Info("Receipt signing key rotated")
+	return id, nil
+}
+
+// load reads every key in the keys directory
+func (s *Signer) load() error {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.pem"))
+	if err != nil {
+		return fmt.Errorf("failed to list receipt keys: %w", err)
+	}
+
+	var keys []signingKey
+	for _, path := range paths {
+		key, err := readKey(path)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, *key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].writtenAt.Before(keys[j].writtenAt)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+	s.active = nil
+	if len(keys) > 0 {
+		s.active = &s.keys[len(keys)-1]
+	}
+	return nil
+}
+
+// readKey reads a PKCS#8 or SEC 1 private key file. Ed25519 keys sign with
+// EdDSA and ECDSA keys with the ES algorithm of their curve.
+func readKey(path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read receipt key: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read receipt key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("receipt key %s is not PEM encoded", path)
+	}
+
+	var private interface{}
+	if block.Type == "EC PRIVATE KEY" {
+		private, err = x509.ParseECPrivateKey(block.Bytes)
+	} else {
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse receipt key %s: %w", path, err)
+	}
+
+	key := &signingKey{
+		id:        strings.TrimSuffix(filepath.Base(path), ".pem"),
+		writtenAt: info.ModTime(),
+	}
+	switch private := private.(type) {
+	case ed25519.PrivateKey:
+		key.method, key.private = jwt.SigningMethodEdDSA, private
+	case *ecdsa.PrivateKey:
+		switch private.Curve {
+		case elliptic.P256():
+			key.method = jwt.SigningMethodES256
+		case elliptic.P384():
+			key.method = jwt.SigningMethodES384
+		case elliptic.P521():
+			key.method = jwt.SigningMethodES512
+		default:
+			return nil, fmt.Errorf("receipt key %s uses an unsupported curve", path)
+		}
+		key.private = private
+	default:
+		return nil, fmt.Errorf("receipt key %s is not an Ed25519 or ECDSA key", path)
+	}
+	return key, nil
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrBatchTooLarge is returned when a batch create holds more items than allowed
+var ErrBatchTooLarge = errors.New("batch too large")
+
+// BatchBody limits a batch request body to the configured size. Reading past
+// the limit fails with ErrBatchTooLarge.
+func (s *LogService) BatchBody(body io.Reader) io.Reader {
+	return &batchBodyReader{body: body, limit: s.cfg.BatchMaxBytes, remaining: s.cfg.BatchMaxBytes}
+}
+
+// DecodeBatch reads a JSON array of create requests item by item, so that an
+// oversized batch is rejected with ErrBatchTooLarge as soon as it exceeds the
+// configured item count or body size rather than after it is buffered
+func (s *LogService) DecodeBatch(body io.Reader) ([]models.CreateLogRequest, error) {
+	dec := json.NewDecoder(s.BatchBody(body))
+	if token, err := dec.Token(); err != nil {
+		return nil, err
+	} else if token != json.Delim('[') {
+		return nil, fmt.Errorf("batch must be a JSON array")
+	}
+
+	var reqs []models.CreateLogRequest
+	for dec.More() {
+		if len(reqs) == s.cfg.BatchMaxItems {
+			return nil, fmt.Errorf("%w: more than %d items", ErrBatchTooLarge, s.cfg.BatchMaxItems)
+		}
+		var req models.CreateLogRequest
+		if err := dec.Decode(&req); err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, req)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
+
+// batchBodyReader fails reads once more than limit bytes have been read
+type batchBodyReader struct {
+	body      io.Reader
+	limit     int64
+	remaining int64
+}
+
+func (r *batchBodyReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, fmt.Errorf("%w: body exceeds %d bytes", ErrBatchTooLarge, r.limit)
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.body.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
+
+// createLogsInsertSize is the number of rows per INSERT statement of a batch create
+const createLogsInsertSize = 500
+
+// CreateLogs validates and creates a batch of logs in one transaction. Items
+// that fail validation, or whose client log ID already exists, are reported
+// individually and do not affect the others. Outside batching mode the new
+// logs are anchored together through a single Merkle root.
+func (s *LogService) CreateLogs(reqs []models.CreateLogRequest) (*models.BatchCreateLogsResponse, error) {
+	if len(reqs) > s.cfg.BatchMaxItems {
+		return nil, fmt.Errorf("%w: %d items, at most %d allowed", ErrBatchTooLarge, len(reqs), s.cfg.BatchMaxItems)
+	}
+
+	response := &models.BatchCreateLogsResponse{
+		Total:   len(reqs),
+		Results: make([]models.BatchCreateLogResult, len(reqs)),
+	}
+	fail := func(i int, err error) {
+		response.Results[i] = models.BatchCreateLogResult{Index: i, Status: models.BatchItemFailed, Error: err.Error()}
+		response.Failed++
+	}
+
+	// Validate every item and set aside retries of logs that already exist
+	var logs []models.Log
+	var canonicals [][]byte
+	var indexes []int
+	seen := map[uuid.UUID]bool{}
+	signatures := newSignatureChecker(s.db)
+	for i := range reqs {
+		req := &reqs[i]
+		log, canonical, err := prepareLog(req)
+		if err == nil {
+			err = signatures.check(req, log)
+		}
+		if err != nil {
+			fail(i, err)
+			continue
+		}
+		if seen[log.ID] {
+			fail(i, fmt.Errorf("%w: log %s appears more than once in the batch", ErrIdempotencyConflict, log.ID))
+			continue
+		}
+		seen[log.ID] = true
+
+		if req.LogID != "" {
+			existing, err := s.replayExistingLog(log.ID, req, log.ContentHash)
+			if err != nil {
+				fail(i, err)
+				continue
+			}
+			if existing != nil {
+				response.Results[i] = models.BatchCreateLogResult{Index: i, Status: models.BatchItemReplayed, Log: existing}
+				response.Replayed++
+				continue
+			}
+		}
+
+		logs = append(logs, *log)
+		canonicals = append(canonicals, canonical)
+		indexes = append(indexes, i)
+	}
+	if len(logs) == 0 {
+		return response, nil
+	}
+
+	var batch *models.AnchorBatch
+	var entry *models.OutboxEntry
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		sources := []string{}
+		seenSources := map[string]bool{}
+		for _, log := range logs {
+			if !seenSources[log.Source] {
+				seenSources[log.Source] = true
+				sources = append(sources, log.Source)
+			}
+		}
+		if err := lockChainHeads(tx, sources); err != nil {
+			return err
+		}
+
+		for i := range logs {
+			if err := appendToChain(tx, &logs[i], canonicals[i]); err != nil {
+				return err
+			}
+		}
+
+		result := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "id"}}, DoNothing: true}).
+			CreateInBatches(&logs, createLogsInsertSize)
+		if result.Error != nil {
+			return fmt.Errorf("failed to save logs to database: %w", result.Error)
+		}
+		if result.RowsAffected != int64(len(logs)) {
+			return errLogIDTaken
+		}
+
+		// In batching mode the logs wait for the batcher to anchor them
+		if s.batcher != nil {
+			return nil
+		}
+
+		var err error
+		batch, entry, err = anchorInBatch(tx, s.outbox, logs)
+		return err
+	})
+	if errors.Is(err, errLogIDTaken) {
+		return nil, fmt.Errorf("%w: a log ID in the batch was created concurrently, retry the batch", ErrIdempotencyConflict)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.batcher != nil {
+		for range logs {
+			s.batcher.Notify()
+		}
+	} else if s.fabric != nil {
+		txID, err := s.outbox.Dispatch(entry.ID)
+		if err != nil {
+			s.logger.WithError(err).WithField("batchID", batch.ID).Error("Failed to commit batch root to blockchain, queued for retry")
+		} else if txID != "" {
+			now := time.Now()
+			for i := range logs {
+				logs[i].TxID = &txID
+				logs[i].CommittedAt = &now
+			}
+		}
+	} else {
+		s.logger.Warning("Fabric client is nil - blockchain commit left in outbox")
+	}
+
+	for n, i := range indexes {
+		response.Results[i] = models.BatchCreateLogResult{Index: i, Status: models.BatchItemCreated, Log: s.toLogResponse(&logs[n])}
+	}
+	response.Created = len(logs)
+
+	fields := logrus.Fields{
+		"total":    response.Total,
+		"created":  response.Created,
+		"replayed": response.Replayed,
+		"failed":   response.Failed,
+	}
+	if batch != nil {
+		fields["batchID"] = batch.ID
+	}
+	s.logger.WithFields(fields).Info("Log batch created successfully")
+
+	return response, nil
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/banking-audit-ledger/backend/internal/merkle"
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Batcher groups unanchored log hashes into Merkle trees and anchors only
+// the root of each tree, trading one blockchain transaction per log for one
+// per batch
+type Batcher struct {
+	db     *gorm.DB
+	outbox *Outbox
+	cfg    config.AnchorConfig
+	logger *logrus.Logger
+
+	pending atomic.Int64
+	full    chan struct{}
+}
+
+// NewBatcher creates a new Merkle batcher
+func NewBatcher(db *gorm.DB, outbox *Outbox, cfg config.AnchorConfig, logger *logrus.Logger) *Batcher {
+	return &Batcher{
+		db:     db,
+		outbox: outbox,
+		cfg:    cfg,
+		logger: logger,
+		full:   make(chan struct{}, 1),
+	}
+}
+
+// Notify tells the batcher that a log is waiting to be batched. Once enough
+// logs are waiting a batch is cut without waiting for the window to elapse.
+func (b *Batcher) Notify() {
+	if b.pending.Add(1) < int64(b.cfg.BatchMaxSize) {
+		return
+	}
+	select {
+	case b.full <- struct{}{}:
+	default:
+	}
+}
+
+// Run cuts batches every window, or sooner when a batch fills up, until the
+// context is cancelled
+func (b *Batcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.cfg.BatchWindow)
+	defer ticker.Stop()
+
+	b.logger.WithFields(logrus.Fields{
+		"component":    "batcher",
+		"batchWindow":  b.cfg.BatchWindow,
+		"batchMaxSize": b.cfg.BatchMaxSize,
+	}).Info("Merkle batcher started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.logger.WithField("component", "batcher").Info("Merkle batcher stopped")
+			return
+		case <-ticker.C:
+		case <-b.full:
+		}
+
+		b.pending.Store(0)
+		for ctx.Err() == nil {
+			batch, err := b.Flush()
+			if err != nil {
+				b.logger.WithError(err).WithField("component", "batcher").Error("Failed to cut batch")
+				break
+			}
+			if batch == nil || batch.LeafCount < b.cfg.BatchMaxSize {
+				break
+			}
+		}
+	}
+}
+
+// Flush cuts a single batch from up to BatchMaxSize waiting logs and commits
+// its root. It returns nil if no logs are waiting.
+func (b *Batcher) Flush() (*models.AnchorBatch, error) {
+	var batch *models.AnchorBatch
+	var entry *models.OutboxEntry
+	err := b.db.Transaction(func(tx *gorm.DB) error {
+		// Logs committed individually have an outbox entry and are left alone
+		var logs []models.Log
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("batch_id IS NULL AND tx_id IS NULL").
+			Where("NOT EXISTS (SELECT 1 FROM log_outbox WHERE log_outbox.log_id = logs.id)").
+			Order("created_at, id").
+			Limit(b.cfg.BatchMaxSize).
+			Find(&logs).Error; err != nil {
+			return fmt.Errorf("failed to load logs awaiting batching: %w", err)
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+
+		var err error
+		batch, entry, err = anchorInBatch(tx, b.outbox, logs)
+		return err
+	})
+	if err != nil || batch == nil {
+		return nil, err
+	}
+
+	txID, err := b.outbox.Dispatch(entry.ID)
+	if err != nil {
+		b.logger.WithError(err).WithField("batchID", batch.ID).Error("Failed to commit batch root to blockchain, queued for retry")
+	} else if txID != "" {
+		now := time.Now()
+		batch.TxID = &txID
+		batch.CommittedAt = &now
+	}
+
+	b.logger.WithFields(logrus.Fields{
+		"batchID":   batch.ID,
+		"root":      batch.Root,
+		"leafCount": batch.LeafCount,
+		"txID":      txID,
+	}).Info("Batch created successfully")
+
+	return batch, nil
+}
+
+// anchorInBatch builds a Merkle tree over the hashes of logs, in order,
+// records it as a batch, assigns each log its leaf and queues the root for
+// anchoring. It must run in the transaction that holds the logs.
+func anchorInBatch(tx *gorm.DB, outbox *Outbox, logs []models.Log) (*models.AnchorBatch, *models.OutboxEntry, error) {
+	leaves := make([]string, len(logs))
+	for i, log := range logs {
+		leaves[i] = log.Hash
+	}
+	tree, err := merkle.New(leaves)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build merkle tree: %w", err)
+	}
+
+	batch := &models.AnchorBatch{
+		ID:        uuid.New(),
+		Root:      tree.Root(),
+		LeafCount: tree.LeafCount(),
+		CreatedAt: time.Now(),
+	}
+	if err := tx.Create(batch).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to save batch: %w", err)
+	}
+
+	for i := range logs {
+		leafIndex := i
+		if err := tx.Model(&models.Log{}).Where("id = ?", logs[i].ID).Updates(map[string]interface{}{
+			"batch_id":   batch.ID,
+			"leaf_index": leafIndex,
+		}).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to assign log to batch: %w", err)
+		}
+		logs[i].BatchID = &batch.ID
+		logs[i].LeafIndex = &leafIndex
+	}
+
+	entry, err := outbox.EnqueueBatch(tx, batch, batchMetadata(batch))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return batch, entry, nil
+}
+
+// batchMetadata builds the metadata anchored on-chain alongside a batch root
+func batchMetadata(batch *models.AnchorBatch) map[string]string {
+	return map[string]string{
+		"created_at": batch.CreatedAt.Format(time.RFC3339),
+	}
+}
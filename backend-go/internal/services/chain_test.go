@@ -0,0 +1,291 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestChainDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm: %v", err)
+	}
+	return db, mock
+}
+
+// expectAppend expects appendToChain to find the head of source at
+// lastSequence and lastHash and to advance it to sequence and hash
+func expectAppend(mock sqlmock.Sqlmock, source string, lastSequence int64, lastHash string, sequence int64, hash string) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "source_chain_heads" .* ON CONFLICT DO NOTHING`).
+		WithArgs(source, 0, models.GenesisHash, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT \* FROM "source_chain_heads" WHERE source = \$1 .* FOR UPDATE`).
+		WithArgs(source, source).
+		WillReturnRows(sqlmock.NewRows([]string{"source", "last_sequence", "last_hash"}).AddRow(source, lastSequence, lastHash))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "source_chain_heads" SET`).
+		WithArgs(sequence, hash, sqlmock.AnyArg(), source).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+func TestAppendToChainLinksLogs(t *testing.T) {
+	db, mock := newTestChainDB(t)
+	payload := []byte(`{"amount":1}`)
+
+	first := &models.Log{ID: uuid.New(), Source: "core-banking", HashVersion: models.HashVersionJCS}
+	firstHash := computeChainedHash(models.GenesisHash, 1, payload)
+	second := &models.Log{ID: uuid.New(), Source: "core-banking", HashVersion: models.HashVersionJCS}
+	secondHash := computeChainedHash(firstHash, 2, payload)
+
+	// The first log of a source links to the genesis hash
+	expectAppend(mock, "core-banking", 0, models.GenesisHash, 1, firstHash)
+	expectAppend(mock, "core-banking", 1, firstHash, 2, secondHash)
+
+	for _, log := range []*models.Log{first, second} {
+		if err := appendToChain(db, log, payload); err != nil {
+			t.Fatalf("appendToChain: %v", err)
+		}
+	}
+
+	if *first.Sequence != 1 || *first.PrevHash != models.GenesisHash || first.Hash != firstHash {
+		t.Errorf("first = sequence %d, prev %s, hash %s; want 1, genesis, %s", *first.Sequence, *first.PrevHash, first.Hash, firstHash)
+	}
+	if *second.Sequence != 2 || *second.PrevHash != first.Hash || second.Hash != secondHash {
+		t.Errorf("second = sequence %d, prev %s, hash %s; want 2, %s, %s", *second.Sequence, *second.PrevHash, second.Hash, first.Hash, secondHash)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAppendToChainEnvelopeBindsLink(t *testing.T) {
+	db, mock := newTestChainDB(t)
+	payload := []byte(`{"amount":1}`)
+	prevHash := fmt.Sprintf("%064x", 41)
+	log := &models.Log{ID: uuid.New(), Source: "cards", EventType: "card.issued", HashVersion: models.HashVersionEnvelope}
+
+	// The envelope hash is computed after the link is assigned
+	linked := *log
+	sequence := int64(42)
+	linked.Sequence, linked.PrevHash = &sequence, &prevHash
+	want, err := computeLogHash(&linked, payload)
+	if err != nil {
+		t.Fatalf("computeLogHash: %v", err)
+	}
+	expectAppend(mock, "cards", 41, prevHash, 42, want)
+
+	if err := appendToChain(db, log, payload); err != nil {
+		t.Fatalf("appendToChain: %v", err)
+	}
+	if log.Hash != want {
+		t.Errorf("Hash = %s, want %s", log.Hash, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// testChain returns n correctly linked logs of one source
+func testChain(n int) []models.Log {
+	logs := make([]models.Log, n)
+	prevHash := models.GenesisHash
+	for i := range logs {
+		sequence := int64(i + 1)
+		link := prevHash
+		logs[i] = models.Log{
+			ID:       uuid.New(),
+			Source:   "core-banking",
+			Sequence: &sequence,
+			PrevHash: &link,
+			Hash:     computeChainedHash(link, sequence, []byte(fmt.Sprintf(`{"n":%d}`, i))),
+		}
+		prevHash = logs[i].Hash
+	}
+	return logs
+}
+
+func chainRows(logs []models.Log) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "source", "sequence", "prev_hash", "hash", "deleted_at"})
+	for _, log := range logs {
+		var deletedAt interface{}
+		if log.DeletedAt.Valid {
+			deletedAt = log.DeletedAt.Time
+		}
+		rows.AddRow(log.ID, log.Source, *log.Sequence, *log.PrevHash, log.Hash, deletedAt)
+	}
+	return rows
+}
+
+func TestVerifyChain(t *testing.T) {
+	otherHash := fmt.Sprintf("%064x", 99)
+
+	tests := []struct {
+		name string
+		// edit changes a three log chain and returns the logs and chain
+		// head found in the database
+		edit func(logs []models.Log) ([]models.Log, int64, string)
+		// want receives the edited chain and the logs found
+		want func(chain, found []models.Log) []models.ChainIssue
+	}{
+		{
+			name: "valid",
+			edit: func(logs []models.Log) ([]models.Log, int64, string) { return logs, 3, logs[2].Hash },
+			want: func(_, _ []models.Log) []models.ChainIssue { return nil },
+		},
+		{
+			name: "empty",
+			edit: func([]models.Log) ([]models.Log, int64, string) { return nil, 0, models.GenesisHash },
+			want: func(_, _ []models.Log) []models.ChainIssue { return nil },
+		},
+		{
+			name: "genesis not linked",
+			edit: func(logs []models.Log) ([]models.Log, int64, string) {
+				logs[0].PrevHash = &otherHash
+				return logs, 3, logs[2].Hash
+			},
+			want: func(logs, _ []models.Log) []models.ChainIssue {
+				return []models.ChainIssue{{Type: models.ChainIssueBrokenLink, Sequence: 1, LogID: &logs[0].ID, Expected: models.GenesisHash, Actual: otherHash}}
+			},
+		},
+		{
+			name: "gap",
+			edit: func(logs []models.Log) ([]models.Log, int64, string) {
+				return []models.Log{logs[0], logs[2]}, 3, logs[2].Hash
+			},
+			want: func(_, _ []models.Log) []models.ChainIssue {
+				return []models.ChainIssue{{Type: models.ChainIssueGap, Sequence: 2, Expected: "2", Actual: "3"}}
+			},
+		},
+		{
+			name: "fork",
+			edit: func(logs []models.Log) ([]models.Log, int64, string) {
+				fork := logs[1]
+				fork.ID = uuid.New()
+				fork.Hash = otherHash
+				return []models.Log{logs[0], logs[1], fork, logs[2]}, 3, logs[2].Hash
+			},
+			want: func(_, logs []models.Log) []models.ChainIssue {
+				return []models.ChainIssue{{Type: models.ChainIssueFork, Sequence: 2, LogID: &logs[2].ID, Expected: logs[1].ID.String(), Actual: logs[2].ID.String()}}
+			},
+		},
+		{
+			name: "broken link",
+			edit: func(logs []models.Log) ([]models.Log, int64, string) {
+				logs[2].PrevHash = &otherHash
+				return logs, 3, logs[2].Hash
+			},
+			want: func(logs, _ []models.Log) []models.ChainIssue {
+				return []models.ChainIssue{{Type: models.ChainIssueBrokenLink, Sequence: 3, LogID: &logs[2].ID, Expected: logs[1].Hash, Actual: otherHash}}
+			},
+		},
+		{
+			name: "deleted",
+			edit: func(logs []models.Log) ([]models.Log, int64, string) {
+				logs[1].DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+				return logs, 3, logs[2].Hash
+			},
+			want: func(logs, _ []models.Log) []models.ChainIssue {
+				return []models.ChainIssue{{Type: models.ChainIssueDeleted, Sequence: 2, LogID: &logs[1].ID}}
+			},
+		},
+		{
+			name: "truncated",
+			edit: func(logs []models.Log) ([]models.Log, int64, string) { return logs[:2], 3, logs[2].Hash },
+			want: func(logs, _ []models.Log) []models.ChainIssue {
+				return []models.ChainIssue{{Type: models.ChainIssueTruncated, Sequence: 3, Expected: logs[2].Hash, Actual: logs[1].Hash}}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newTestChainDB(t)
+			log := logrus.New()
+			log.SetOutput(io.Discard)
+			service := NewVerificationService(db, newFakeFabric(), nil, log)
+
+			chain := testChain(3)
+			found, headSequence, headHash := tt.edit(chain)
+			want := tt.want(chain, found)
+
+			mock.ExpectQuery(`SELECT \* FROM "source_chain_heads" WHERE source = \$1`).
+				WithArgs("core-banking").
+				WillReturnRows(sqlmock.NewRows([]string{"source", "last_sequence", "last_hash"}).AddRow("core-banking", headSequence, headHash))
+			mock.ExpectQuery(`SELECT \* FROM "logs" WHERE source = \$1 AND sequence IS NOT NULL ORDER BY sequence, id LIMIT 1000`).
+				WithArgs("core-banking").
+				WillReturnRows(chainRows(found))
+
+			response, err := service.VerifyChain("core-banking")
+			if err != nil {
+				t.Fatalf("VerifyChain: %v", err)
+			}
+			if response.LogsChecked != int64(len(found)) {
+				t.Errorf("LogsChecked = %d, want %d", response.LogsChecked, len(found))
+			}
+			if response.IsValid != (len(want) == 0) {
+				t.Errorf("IsValid = %t with issues %+v", response.IsValid, response.Issues)
+			}
+			if len(response.Issues) != len(want) {
+				t.Fatalf("Issues = %+v, want %+v", response.Issues, want)
+			}
+			for i := range want {
+				got := response.Issues[i]
+				if got.Type != want[i].Type || got.Sequence != want[i].Sequence || got.Expected != want[i].Expected || got.Actual != want[i].Actual ||
+					(got.LogID == nil) != (want[i].LogID == nil) || (got.LogID != nil && *got.LogID != *want[i].LogID) {
+					t.Errorf("Issues[%d] = %+v, want %+v", i, got, want[i])
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestVerifyChainPagesOnSequenceAndID(t *testing.T) {
+	db, mock := newTestChainDB(t)
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	service := NewVerificationService(db, newFakeFabric(), nil, log)
+
+	chain := testChain(chainWalkBatchSize + 1)
+	last := chain[chainWalkBatchSize-1]
+
+	mock.ExpectQuery(`SELECT \* FROM "source_chain_heads"`).
+		WillReturnRows(sqlmock.NewRows([]string{"source", "last_sequence", "last_hash"}).AddRow("core-banking", len(chain), chain[len(chain)-1].Hash))
+	mock.ExpectQuery(`SELECT \* FROM "logs" WHERE source = \$1 AND sequence IS NOT NULL ORDER BY`).
+		WillReturnRows(chainRows(chain[:chainWalkBatchSize]))
+	mock.ExpectQuery(`SELECT \* FROM "logs" WHERE \(source = \$1 AND sequence IS NOT NULL\) AND \(sequence, id\) > \(\$2, \$3\) ORDER BY`).
+		WithArgs("core-banking", *last.Sequence, last.ID).
+		WillReturnRows(chainRows(chain[chainWalkBatchSize:]))
+
+	response, err := service.VerifyChain("core-banking")
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if !response.IsValid || response.LogsChecked != int64(len(chain)) {
+		t.Errorf("VerifyChain = valid %t, %d checked, issues %+v; want a valid chain of %d", response.IsValid, response.LogsChecked, response.Issues, len(chain))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/banking-audit-ledger/backend/pkg/jcs"
+)
+
+// hashEnvelope is the document hashed for HashVersionEnvelope logs. Its RFC
+// 8785 canonical form is hashed, so field order here does not matter.
+type hashEnvelope struct {
+	Version   int             `json:"v"`
+	LogID     string          `json:"log_id"`
+	Source    string          `json:"source"`
+	EventType string          `json:"event_type"`
+	CreatedAt string          `json:"created_at"`
+	EventTime *string         `json:"event_time,omitempty"`
+	Signature *string         `json:"signature,omitempty"`
+	SignerKey *string         `json:"signer_key_id,omitempty"`
+	Sequence  *int64          `json:"sequence"`
+	PrevHash  *string         `json:"prev_hash"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// computeLogHash computes the hash of a log under its hash version. payload
+// is the canonical payload for JCS and envelope logs and the re-marshalled
+// payload for legacy logs.
+func computeLogHash(log *models.Log, payload []byte) (string, error) {
+	switch log.HashVersion {
+	case models.HashVersionLegacy, models.HashVersionJCS:
+		if log.Sequence != nil && log.PrevHash != nil {
+			return computeChainedHash(*log.PrevHash, *log.Sequence, payload), nil
+		}
+		return fmt.Sprintf("%x", sha256.Sum256(payload)), nil
+	case models.HashVersionEnvelope:
+		// The event time and producer signature are left out when unset, so
+		// logs without them hash as they did before they existed
+		var eventTime *string
+		if log.EventTime != nil {
+			formatted := formatEnvelopeTime(*log.EventTime)
+			eventTime = &formatted
+		}
+		envelope, err := json.Marshal(hashEnvelope{
+			Version:   models.HashVersionEnvelope,
+			LogID:     log.ID.String(),
+			Source:    log.Source,
+			EventType: log.EventType,
+			CreatedAt: formatEnvelopeTime(log.CreatedAt),
+			EventTime: eventTime,
+			Signature: log.Signature,
+			SignerKey: log.SignatureKeyID,
+			Sequence:  log.Sequence,
+			PrevHash:  log.PrevHash,
+			Payload:   payload,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal hash envelope: %w", err)
+		}
+		canonical, err := jcs.Canonicalize(envelope)
+		if err != nil {
+			return "", fmt.Errorf("failed to canonicalize hash envelope: %w", err)
+		}
+		return fmt.Sprintf("%x", sha256.Sum256(canonical)), nil
+	default:
+		return "", fmt.Errorf("unknown hash version %d", log.HashVersion)
+	}
+}
+
+// recomputeLogHash recomputes the hash of a stored log from its payload
+// using the scheme recorded in its hash version
+func recomputeLogHash(log *models.Log) (string, error) {
+	if log.HashVersion == models.HashVersionLegacy {
+		return hashPayloadAs(log, []byte(log.Payload))
+	}
+	return hashPayloadAs(log, []byte(log.RawPayload))
+}
+
+// hashPayloadAs hashes a payload as if it were the payload of log, using the
+// log's hash version and metadata
+func hashPayloadAs(log *models.Log, raw []byte) (string, error) {
+	var payload []byte
+	switch log.HashVersion {
+	case models.HashVersionLegacy:
+		// Legacy logs hashed the payload as re-marshalled by Go, which sorts
+		// keys and normalizes numbers the same way on every round trip
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return "", fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+		marshalled, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		payload = marshalled
+	default:
+		canonical, err := jcs.Canonicalize(raw)
+		if err != nil {
+			return "", fmt.Errorf("failed to canonicalize payload: %w", err)
+		}
+		payload = canonical
+	}
+
+	return computeLogHash(log, payload)
+}
+
+// contentHash returns the hash of a payload's RFC 8785 canonical form, the
+// content hash recorded for JCS and envelope logs
+func contentHash(raw []byte) (string, error) {
+	canonical, err := jcs.Canonicalize(raw)
+	if err != nil {
+		return "", fmt.Errorf("failed to canonicalize payload: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(canonical)), nil
+}
+
+// formatEnvelopeTime formats a time for the hash envelope. The
+// database keeps microseconds, so that is the precision that is hashed.
+func formatEnvelopeTime(t time.Time) string {
+	return t.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+}
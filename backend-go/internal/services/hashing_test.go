@@ -0,0 +1,110 @@
+package services
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// hashTestLog returns an unhashed log whose raw payload is neither ordered
+// nor compact, and holds a character Go escapes but RFC 8785 does not
+func hashTestLog(version int) *models.Log {
+	raw := `{"note": "a<b", "account": "ACC-1"}`
+	sequence := int64(7)
+	prevHash := fmt.Sprintf("%064x", 6)
+	return &models.Log{
+		ID:          uuid.MustParse("6f1c1c56-2b7e-4a43-9a43-5a2a3c2f0b11"),
+		CreatedAt:   time.Date(2025, 3, 4, 5, 6, 7, 123456789, time.UTC),
+		Source:      "core-banking",
+		EventType:   "transfer.completed",
+		Sequence:    &sequence,
+		PrevHash:    &prevHash,
+		Payload:     raw,
+		RawPayload:  raw,
+		HashVersion: version,
+	}
+}
+
+func TestRecomputeLogHashVersions(t *testing.T) {
+	prevHash := fmt.Sprintf("%064x", 6)
+	envelope := `{"created_at":"2025-03-04T05:06:07.123456Z","event_type":"transfer.completed",` +
+		`"log_id":"6f1c1c56-2b7e-4a43-9a43-5a2a3c2f0b11","payload":{"account":"ACC-1","note":"a<b"},` +
+		`"prev_hash":"` + prevHash + `","sequence":7,"source":"core-banking","v":2}`
+
+	tests := []struct {
+		name    string
+		version int
+		want    string
+	}{
+		{"legacy hashes the payload as Go re-marshals it", models.HashVersionLegacy,
+			computeChainedHash(prevHash, 7, []byte(`{"account":"ACC-1","note":"a\u003cb"}`))},
+		{"jcs hashes the canonical payload", models.HashVersionJCS,
+			computeChainedHash(prevHash, 7, []byte(`{"account":"ACC-1","note":"a<b"}`))},
+		{"envelope hashes the canonical envelope", models.HashVersionEnvelope,
+			fmt.Sprintf("%x", sha256.Sum256([]byte(envelope)))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := recomputeLogHash(hashTestLog(tt.version))
+			if err != nil {
+				t.Fatalf("recomputeLogHash: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("hash = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecomputeLogHashCoversVersionFields(t *testing.T) {
+	edits := []struct {
+		name   string
+		edit   func(*models.Log)
+		covers map[int]bool
+	}{
+		{"payload value", func(log *models.Log) {
+			log.Payload = `{"note": "a>b", "account": "ACC-1"}`
+			log.RawPayload = log.Payload
+		}, map[int]bool{models.HashVersionLegacy: true, models.HashVersionJCS: true, models.HashVersionEnvelope: true}},
+		{"payload key order", func(log *models.Log) {
+			log.Payload = `{"account":"ACC-1","note":"a<b"}`
+			log.RawPayload = log.Payload
+		}, map[int]bool{}},
+		{"prev hash", func(log *models.Log) {
+			prevHash := fmt.Sprintf("%064x", 5)
+			log.PrevHash = &prevHash
+		}, map[int]bool{models.HashVersionLegacy: true, models.HashVersionJCS: true, models.HashVersionEnvelope: true}},
+		{"source", func(log *models.Log) { log.Source = "cards" }, map[int]bool{models.HashVersionEnvelope: true}},
+		{"event type", func(log *models.Log) { log.EventType = "transfer.failed" }, map[int]bool{models.HashVersionEnvelope: true}},
+		{"created at", func(log *models.Log) { log.CreatedAt = log.CreatedAt.Add(time.Second) }, map[int]bool{models.HashVersionEnvelope: true}},
+		{"created at below a microsecond", func(log *models.Log) { log.CreatedAt = log.CreatedAt.Add(100) }, map[int]bool{}},
+	}
+
+	for _, version := range []int{models.HashVersionLegacy, models.HashVersionJCS, models.HashVersionEnvelope} {
+		original, err := recomputeLogHash(hashTestLog(version))
+		if err != nil {
+			t.Fatalf("version %d: recomputeLogHash: %v", version, err)
+		}
+		for _, tt := range edits {
+			log := hashTestLog(version)
+			tt.edit(log)
+			edited, err := recomputeLogHash(log)
+			if err != nil {
+				t.Fatalf("version %d, %s: recomputeLogHash: %v", version, tt.name, err)
+			}
+			if changed := edited != original; changed != tt.covers[version] {
+				t.Errorf("version %d: changing the %s changed the hash = %t, want %t", version, tt.name, changed, tt.covers[version])
+			}
+		}
+	}
+}
+
+func TestRecomputeLogHashUnknownVersion(t *testing.T) {
+	if _, err := recomputeLogHash(hashTestLog(models.HashVersionEnvelope + 1)); err == nil {
+		t.Error("recomputeLogHash succeeded for an unknown hash version")
+	}
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidLogID is returned when a client-supplied log ID is not a UUID
+var ErrInvalidLogID = errors.New("invalid log ID")
+
+// ErrIdempotencyConflict is returned when a log ID or idempotency key is
+// reused for a different request
+var ErrIdempotencyConflict = errors.New("idempotency conflict")
+
+// errLogIDTaken and errIdempotencyKeyTaken abort a create transaction that
+// lost a race with a concurrent request for the same log ID or key
+var (
+	errLogIDTaken          = errors.New("log ID already exists")
+	errIdempotencyKeyTaken = errors.New("idempotency key already used")
+)
+
+// requestFingerprint identifies the content of a create request, so a retry
+// can be told apart from a different request reusing a key
+func requestFingerprint(req *models.CreateLogRequest, canonicalPayload []byte) (string, error) {
+	fingerprint, err := json.Marshal(struct {
+		LogID     string          `json:"log_id"`
+		Source    string          `json:"source"`
+		EventType string          `json:"event_type"`
+		EventTime *time.Time      `json:"event_time,omitempty"`
+		Signature string          `json:"signature,omitempty"`
+		KeyID     string          `json:"key_id,omitempty"`
+		Payload   json.RawMessage `json:"payload"`
+	}{req.LogID, req.Source, req.EventType, eventTime(req), req.Signature, req.KeyID, canonicalPayload})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request fingerprint: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(fingerprint)), nil
+}
+
+// idempotencyPurgeInterval is how often expired idempotency keys are deleted
+const idempotencyPurgeInterval = time.Hour
+
+// claimIdempotencyKey records key for the log being created in tx. It
+// returns errIdempotencyKeyTaken if the key was already used; Postgres makes
+// the insert wait for a concurrent transaction holding the same key.
+func claimIdempotencyKey(tx *gorm.DB, key, fingerprint string, response *models.LogResponse) error {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.IdempotencyKey{
+		Source:      response.Source,
+		Key:         key,
+		RequestHash: fingerprint,
+		LogID:       response.ID,
+		Response:    string(body),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to save idempotency key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errIdempotencyKeyTaken
+	}
+	return nil
+}
+
+// storeIdempotentResponse replaces the stored response of key with the final
+// response sent to the client
+func (s *LogService) storeIdempotentResponse(key string, response *models.LogResponse) {
+	body, err := json.Marshal(response)
+	if err == nil {
+		err = s.db.Model(&models.IdempotencyKey{}).
+			Where("source = ? AND key = ?", response.Source, key).
+			Update("response", string(body)).Error
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("idempotencyKey", key).Error("Failed to store idempotent response")
+	}
+}
+
+// replayIdempotencyKey returns the stored response of key under source, or
+// nil if the key has not been used or has expired. An expired key is deleted
+// so that it can be claimed again.
+func (s *LogService) replayIdempotencyKey(source, key, fingerprint string) (*models.LogResponse, error) {
+	if err := s.db.Where("source = ? AND key = ? AND created_at < ?", source, key, time.Now().Add(-s.cfg.IdempotencyKeyTTL)).
+		Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete expired idempotency key: %w", err)
+	}
+
+	var record models.IdempotencyKey
+	if err := s.db.Where("source = ? AND key = ?", source, key).First(&record).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	if record.RequestHash != fingerprint {
+		return nil, fmt.Errorf("%w: idempotency key %q was used for a different request", ErrIdempotencyConflict, key)
+	}
+
+	// Decode the payload into a raw message so its original bytes are replayed
+	response := &models.LogResponse{Payload: &json.RawMessage{}}
+	if err := json.Unmarshal([]byte(record.Response), response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal stored response: %w", err)
+	}
+	response.Replayed = true
+
+	return response, nil
+}
+
+// PurgeIdempotencyKeys deletes idempotency keys older than the configured TTL
+func (s *LogService) PurgeIdempotencyKeys() (int64, error) {
+	result := s.db.Where("created_at < ?", time.Now().Add(-s.cfg.IdempotencyKeyTTL)).Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// RunIdempotencyKeyPurge deletes expired idempotency keys every purge
+// interval until the context is cancelled
+func (s *LogService) RunIdempotencyKeyPurge(ctx context.Context) {
+	ticker := time.NewTicker(idempotencyPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.PurgeIdempotencyKeys()
+			if err != nil {
+				s.logger.WithError(err).WithField("component", "idempotency").Error("Failed to purge idempotency keys")
+			} else if purged > 0 {
+				s.logger.WithFields(logrus.Fields{"component": "idempotency", "purged": purged}).Info("Expired idempotency keys purged")
+			}
+		}
+	}
+}
+
+// replayExistingLog returns the log with a client-supplied ID if it exists
+// and matches the request, or nil if there is no such log
+func (s *LogService) replayExistingLog(id uuid.UUID, req *models.CreateLogRequest, contentHash string) (*models.LogResponse, error) {
+	var log models.Log
+	if err := s.db.Unscoped().Where("id = ?", id).First(&log).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get log: %w", err)
+	}
+
+	sameEventTime := (log.EventTime == nil) == (req.EventTime == nil) &&
+		(log.EventTime == nil || log.EventTime.Equal(*eventTime(req)))
+	if log.DeletedAt.Valid || log.Source != req.Source || log.EventType != req.EventType || !sameEventTime || log.ContentHash != contentHash {
+		return nil, fmt.Errorf("%w: log %s already exists with different content", ErrIdempotencyConflict, id)
+	}
+
+	response := s.toLogResponse(&log)
+	response.Replayed = true
+	return response, nil
+}
+
+// eventTime returns the event time of a request at the precision it is
+// stored with, or nil if it has none
+func eventTime(req *models.CreateLogRequest) *time.Time {
+	if req.EventTime == nil {
+		return nil
+	}
+	t := req.EventTime.UTC().Truncate(time.Microsecond)
+	return &t
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestLogService(t *testing.T) (*LogService, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm: %v", err)
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	cfg := config.IngestConfig{IdempotencyKeyTTL: time.Hour}
+	return NewLogService(db, newFakeFabric(), nil, nil, nil, cfg, log), mock
+}
+
+func TestRequestFingerprint(t *testing.T) {
+	eventTime := time.Date(2025, 3, 4, 5, 6, 7, 123456000, time.UTC)
+	base := func() *models.CreateLogRequest {
+		at := eventTime
+		return &models.CreateLogRequest{
+			LogID:     "6f1c1c56-2b7e-4a43-9a43-5a2a3c2f0b11",
+			Source:    "core-banking",
+			EventType: "transfer.completed",
+			EventTime: &at,
+			Signature: "c2lnbmF0dXJl",
+			KeyID:     "producer-1",
+		}
+	}
+	payload := []byte(`{"amount":1}`)
+	original, err := requestFingerprint(base(), payload)
+	if err != nil {
+		t.Fatalf("requestFingerprint: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(*models.CreateLogRequest)
+		payload string
+		same    bool
+	}{
+		{"identical", func(*models.CreateLogRequest) {}, `{"amount":1}`, true},
+		{"event time in another zone", func(req *models.CreateLogRequest) {
+			at := eventTime.In(time.FixedZone("CET", 3600))
+			req.EventTime = &at
+		}, `{"amount":1}`, true},
+		{"event time below a microsecond", func(req *models.CreateLogRequest) {
+			at := eventTime.Add(500)
+			req.EventTime = &at
+		}, `{"amount":1}`, true},
+		{"idempotency key and allowed sources", func(req *models.CreateLogRequest) {
+			req.IdempotencyKey = "key-1"
+			req.AllowedSources = []string{"core-banking"}
+		}, `{"amount":1}`, true},
+		{"payload", func(*models.CreateLogRequest) {}, `{"amount":2}`, false},
+		{"log ID", func(req *models.CreateLogRequest) { req.LogID = uuid.NewString() }, `{"amount":1}`, false},
+		{"source", func(req *models.CreateLogRequest) { req.Source = "cards" }, `{"amount":1}`, false},
+		{"event type", func(req *models.CreateLogRequest) { req.EventType = "transfer.failed" }, `{"amount":1}`, false},
+		{"event time", func(req *models.CreateLogRequest) {
+			at := eventTime.Add(time.Second)
+			req.EventTime = &at
+		}, `{"amount":1}`, false},
+		{"no event time", func(req *models.CreateLogRequest) { req.EventTime = nil }, `{"amount":1}`, false},
+		{"signature", func(req *models.CreateLogRequest) { req.Signature = "b3RoZXI=" }, `{"amount":1}`, false},
+		{"key ID", func(req *models.CreateLogRequest) { req.KeyID = "producer-2" }, `{"amount":1}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := base()
+			tt.edit(req)
+			fingerprint, err := requestFingerprint(req, []byte(tt.payload))
+			if err != nil {
+				t.Fatalf("requestFingerprint: %v", err)
+			}
+			if (fingerprint == original) != tt.same {
+				t.Errorf("fingerprint equal = %t, want %t", fingerprint == original, tt.same)
+			}
+		})
+	}
+}
+
+// storedKey returns the idempotency key row holding response
+func storedKey(t *testing.T, source, key, fingerprint string, response *models.LogResponse) *sqlmock.Rows {
+	t.Helper()
+	body, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("marshal response: %v", err)
+	}
+	return sqlmock.NewRows([]string{"source", "key", "request_hash", "log_id", "response", "created_at"}).
+		AddRow(source, key, fingerprint, response.ID, string(body), time.Now())
+}
+
+// expectExpiredKeyDelete expects the expired copy of key under source to be deleted
+func expectExpiredKeyDelete(mock sqlmock.Sqlmock, source, key string, ttl time.Duration, deleted int64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "idempotency_keys" WHERE source = \$1 AND key = \$2 AND created_at < \$3`).
+		WithArgs(source, key, within{time.Now().Add(-ttl - time.Minute), time.Now().Add(-ttl + time.Minute)}).
+		WillReturnResult(sqlmock.NewResult(0, deleted))
+	mock.ExpectCommit()
+}
+
+func TestReplayIdempotencyKey(t *testing.T) {
+	stored := &models.LogResponse{
+		ID:        uuid.New(),
+		Source:    "core-banking",
+		EventType: "transfer.completed",
+		Payload:   json.RawMessage(`{"amount": 1.50}`),
+		Hash:      testHash("log"),
+	}
+
+	t.Run("same request replays the stored response", func(t *testing.T) {
+		service, mock := newTestLogService(t)
+		expectExpiredKeyDelete(mock, "core-banking", "key-1", time.Hour, 0)
+		mock.ExpectQuery(`SELECT \* FROM "idempotency_keys" WHERE source = \$1 AND key = \$2`).
+			WithArgs("core-banking", "key-1").
+			WillReturnRows(storedKey(t, "core-banking", "key-1", "fingerprint", stored))
+
+		response, err := service.replayIdempotencyKey("core-banking", "key-1", "fingerprint")
+		if err != nil {
+			t.Fatalf("replayIdempotencyKey: %v", err)
+		}
+		if response == nil || !response.Replayed || response.ID != stored.ID || response.Hash != stored.Hash {
+			t.Fatalf("response = %+v, want the stored response marked replayed", response)
+		}
+		// The payload is not decoded, so its numbers keep their original form
+		if payload, ok := response.Payload.(*json.RawMessage); !ok || string(*payload) != `{"amount":1.50}` {
+			t.Errorf("Payload = %v, want the stored payload as given", response.Payload)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("different request conflicts", func(t *testing.T) {
+		service, mock := newTestLogService(t)
+		expectExpiredKeyDelete(mock, "core-banking", "key-1", time.Hour, 0)
+		mock.ExpectQuery(`SELECT \* FROM "idempotency_keys"`).
+			WillReturnRows(storedKey(t, "core-banking", "key-1", "fingerprint", stored))
+
+		_, err := service.replayIdempotencyKey("core-banking", "key-1", "other fingerprint")
+		if !errors.Is(err, ErrIdempotencyConflict) {
+			t.Errorf("err = %v, want ErrIdempotencyConflict", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("keys are scoped per source", func(t *testing.T) {
+		service, mock := newTestLogService(t)
+		// key-1 was used by core-banking; cards looks up only its own keys
+		expectExpiredKeyDelete(mock, "cards", "key-1", time.Hour, 0)
+		mock.ExpectQuery(`SELECT \* FROM "idempotency_keys" WHERE source = \$1 AND key = \$2`).
+			WithArgs("cards", "key-1").
+			WillReturnRows(sqlmock.NewRows([]string{"source", "key"}))
+
+		response, err := service.replayIdempotencyKey("cards", "key-1", "other fingerprint")
+		if err != nil || response != nil {
+			t.Errorf("replayIdempotencyKey = %+v, %v; want no replay", response, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("expired key can be claimed again", func(t *testing.T) {
+		service, mock := newTestLogService(t)
+		expectExpiredKeyDelete(mock, "core-banking", "key-1", time.Hour, 1)
+		mock.ExpectQuery(`SELECT \* FROM "idempotency_keys"`).
+			WillReturnRows(sqlmock.NewRows([]string{"source", "key"}))
+
+		response, err := service.replayIdempotencyKey("core-banking", "key-1", "other fingerprint")
+		if err != nil || response != nil {
+			t.Errorf("replayIdempotencyKey = %+v, %v; want no replay", response, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestClaimIdempotencyKey(t *testing.T) {
+	response := &models.LogResponse{ID: uuid.New(), Source: "core-banking"}
+
+	tests := []struct {
+		name    string
+		claimed int64
+		want    error
+	}{
+		{"unused key", 1, nil},
+		{"key taken by a concurrent request", 0, errIdempotencyKeyTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newTestLogService(t)
+			mock.ExpectBegin()
+			mock.ExpectExec(`INSERT INTO "idempotency_keys" .* ON CONFLICT DO NOTHING`).
+				WithArgs("core-banking", "key-1", "fingerprint", response.ID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, tt.claimed))
+			mock.ExpectCommit()
+
+			if err := claimIdempotencyKey(service.db, "key-1", "fingerprint", response); !errors.Is(err, tt.want) {
+				t.Errorf("claimIdempotencyKey = %v, want %v", err, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestPurgeIdempotencyKeys(t *testing.T) {
+	service, mock := newTestLogService(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "idempotency_keys" WHERE created_at < \$1`).
+		WithArgs(within{time.Now().Add(-time.Hour - time.Minute), time.Now().Add(-time.Hour + time.Minute)}).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	purged, err := service.PurgeIdempotencyKeys()
+	if err != nil {
+		t.Fatalf("PurgeIdempotencyKeys: %v", err)
+	}
+	if purged != 3 {
+		t.Errorf("purged = %d, want 3", purged)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReplayExistingLog(t *testing.T) {
+	id := uuid.New()
+	eventTime := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	contentHash := testHash("content")
+	request := func() *models.CreateLogRequest {
+		at := eventTime
+		return &models.CreateLogRequest{LogID: id.String(), Source: "core-banking", EventType: "transfer.completed", EventTime: &at}
+	}
+
+	tests := []struct {
+		name        string
+		edit        func(*models.CreateLogRequest)
+		contentHash string
+		deleted     bool
+		conflict    bool
+	}{
+		{"same content replays", func(*models.CreateLogRequest) {}, contentHash, false, false},
+		{"different payload", func(*models.CreateLogRequest) {}, testHash("other"), false, true},
+		{"different source", func(req *models.CreateLogRequest) { req.Source = "cards" }, contentHash, false, true},
+		{"different event type", func(req *models.CreateLogRequest) { req.EventType = "transfer.failed" }, contentHash, false, true},
+		{"different event time", func(req *models.CreateLogRequest) {
+			at := eventTime.Add(time.Second)
+			req.EventTime = &at
+		}, contentHash, false, true},
+		{"missing event time", func(req *models.CreateLogRequest) { req.EventTime = nil }, contentHash, false, true},
+		{"deleted log", func(*models.CreateLogRequest) {}, contentHash, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newTestLogService(t)
+			var deletedAt interface{}
+			if tt.deleted {
+				deletedAt = time.Now()
+			}
+			mock.ExpectQuery(`SELECT \* FROM "logs" WHERE id = \$1`).
+				WithArgs(id).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "source", "event_type", "event_time", "content_hash", "raw_payload", "deleted_at"}).
+					AddRow(id, "core-banking", "transfer.completed", eventTime, contentHash, `{"amount":1}`, deletedAt))
+
+			req := request()
+			tt.edit(req)
+			response, err := service.replayExistingLog(id, req, tt.contentHash)
+			if tt.conflict {
+				if !errors.Is(err, ErrIdempotencyConflict) {
+					t.Errorf("err = %v, want ErrIdempotencyConflict", err)
+				}
+			} else if err != nil || response == nil || !response.Replayed || response.ID != id {
+				t.Errorf("replayExistingLog = %+v, %v; want the log replayed", response, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestReplayExistingLogNotFound(t *testing.T) {
+	service, mock := newTestLogService(t)
+	id := uuid.New()
+	mock.ExpectQuery(`SELECT \* FROM "logs" WHERE id = \$1`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	response, err := service.replayExistingLog(id, &models.CreateLogRequest{Source: "core-banking"}, "")
+	if err != nil || response != nil {
+		t.Errorf("replayExistingLog = %+v, %v; want no log", response, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/banking-audit-ledger/backend/internal/metrics"
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// IntegrityScanner continuously re-verifies logs in the background and
+// exports the outcome as Prometheus metrics, so tampering can be alerted on
+// without anyone calling the API
+type IntegrityScanner struct {
+	db       *gorm.DB
+	verifier *VerificationService
+	cfg      config.ScannerConfig
+	logger   *logrus.Logger
+}
+
+// NewIntegrityScanner creates a new integrity scanner
+func NewIntegrityScanner(db *gorm.DB, verifier *VerificationService, cfg config.ScannerConfig, logger *logrus.Logger) *IntegrityScanner {
+	return &IntegrityScanner{
+		db:       db,
+		verifier: verifier,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+// Scan verifies one batch of logs and records each result on the log. In
+// sweep mode the least recently verified logs are picked, so repeated scans
+// cycle through the whole table; in sample mode the batch starts at a
+// random point in time. Logs younger than the configured minimum age are
+// skipped because they may not be anchored yet.
+func (s *IntegrityScanner) Scan() error {
+	cutoff := time.Now().Add(-s.cfg.MinAge)
+
+	var logs []models.Log
+	if s.cfg.Mode == config.ScannerModeSample {
+		var err error
+		if logs, err = s.sampleLogs(cutoff); err != nil {
+			return err
+		}
+	} else if err := s.db.Where("created_at < ?", cutoff).
+		Order("verified_at ASC NULLS FIRST, created_at").
+		Limit(s.cfg.BatchSize).
+		Find(&logs).Error; err != nil {
+		return fmt.Errorf("failed to load logs: %w", err)
+	}
+
+	results := s.verifier.verifyMany(logs, s.cfg.Concurrency)
+
+	byStatus := map[string][]uuid.UUID{}
+	for _, result := range results {
+		byStatus[result.Status] = append(byStatus[result.Status], result.ID)
+		metrics.IntegrityScannedLogs.WithLabelValues(scanResult(result.Status)).Inc()
+
+		if result.Status != models.VerificationStatusValid {
+			s.logger.WithFields(logrus.Fields{
+				"component": "integrity-scanner",
+				"logID":     result.ID,
+				"status":    result.Status,
+				"details":   result.Details,
+			}).Warn("Log failed integrity scan")
+		}
+	}
+
+	// UpdateColumns leaves updated_at alone; a scan does not modify the log
+	verifiedAt := time.Now()
+	for status, ids := range byStatus {
+		if err := s.db.Model(&models.Log{}).Where("id IN ?", ids).UpdateColumns(map[string]interface{}{
+			"verified_at": verifiedAt,
+			"scan_status": status,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to record scan results: %w", err)
+		}
+	}
+
+	metrics.IntegrityLastScan.Set(float64(verifiedAt.Unix()))
+
+	s.logger.WithFields(logrus.Fields{
+		"component": "integrity-scanner",
+		"mode":      s.cfg.Mode,
+		"scanned":   len(logs),
+	}).Debug("Integrity scan completed")
+
+	return s.refreshGauges()
+}
+
+// sampleLogs reads a batch forward from a random creation time, wrapping
+// around to the oldest logs when it reaches the cutoff. Unlike ordering by
+// random() this walks the created_at index instead of sorting the table.
+func (s *IntegrityScanner) sampleLogs(cutoff time.Time) ([]models.Log, error) {
+	var bounds struct {
+		Oldest *time.Time
+		Newest *time.Time
+	}
+	if err := s.db.Model(&models.Log{}).
+		Select("MIN(created_at) AS oldest, MAX(created_at) AS newest").
+		Where("created_at < ?", cutoff).
+		Scan(&bounds).Error; err != nil {
+		return nil, fmt.Errorf("failed to find log time range: %w", err)
+	}
+	if bounds.Oldest == nil || bounds.Newest == nil {
+		return nil, nil
+	}
+	start := bounds.Oldest.Add(time.Duration(rand.Int64N(int64(bounds.Newest.Sub(*bounds.Oldest)) + 1)))
+
+	var logs []models.Log
+	if err := s.db.Where("created_at >= ? AND created_at < ?", start, cutoff).
+		Order("created_at, id").
+		Limit(s.cfg.BatchSize).
+		Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load logs: %w", err)
+	}
+	if len(logs) == s.cfg.BatchSize {
+		return logs, nil
+	}
+
+	var wrapped []models.Log
+	if err := s.db.Where("created_at < ?", start).
+		Order("created_at, id").
+		Limit(s.cfg.BatchSize - len(logs)).
+		Find(&wrapped).Error; err != nil {
+		return nil, fmt.Errorf("failed to load logs: %w", err)
+	}
+	return append(logs, wrapped...), nil
+}
+
+// Run scans on every interval until ctx is cancelled
+func (s *IntegrityScanner) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	s.logger.WithFields(logrus.Fields{
+		"component": "integrity-scanner",
+		"mode":      s.cfg.Mode,
+		"interval":  s.cfg.Interval,
+	}).Info("Integrity scanner started")
+
+	if err := s.refreshGauges(); err != nil {
+		s.logger.WithError(err).WithField("component", "integrity-scanner").Error("Failed to refresh integrity metrics")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.WithField("component", "integrity-scanner").Info("Integrity scanner stopped")
+			return
+		case <-ticker.C:
+			if err := s.Scan(); err != nil {
+				s.logger.WithError(err).WithField("component", "integrity-scanner").Error("Integrity scan failed")
+			}
+		}
+	}
+}
+
+// refreshGauges recomputes the gauges from the scan results stored on the logs
+func (s *IntegrityScanner) refreshGauges() error {
+	var counts []struct {
+		ScanStatus string
+		Count      int64
+	}
+	if err := s.db.Model(&models.Log{}).
+		Select("scan_status, count(*) AS count").
+		Where("scan_status IS NOT NULL").
+		Group("scan_status").
+		Scan(&counts).Error; err != nil {
+		return fmt.Errorf("failed to count scan results: %w", err)
+	}
+
+	totals := map[string]int64{
+		metrics.ResultVerified:   0,
+		metrics.ResultMismatched: 0,
+		metrics.ResultUnanchored: 0,
+		metrics.ResultError:      0,
+	}
+	for _, count := range counts {
+		totals[scanResult(count.ScanStatus)] += count.Count
+	}
+	for result, total := range totals {
+		metrics.IntegrityLogs.WithLabelValues(result).Set(float64(total))
+	}
+
+	var oldest *time.Time
+	if err := s.db.Model(&models.Log{}).
+		Select("min(created_at)").
+		Where("verified_at IS NULL").
+		Scan(&oldest).Error; err != nil {
+		return fmt.Errorf("failed to find oldest unverified log: %w", err)
+	}
+	age := 0.0
+	if oldest != nil {
+		age = time.Since(*oldest).Seconds()
+	}
+	metrics.IntegrityOldestUnverifiedAge.Set(age)
+
+	return nil
+}
+
+// scanResult maps a verification status to its metrics result label
+func scanResult(status string) string {
+	switch status {
+	case models.VerificationStatusValid:
+		return metrics.ResultVerified
+	case models.VerificationStatusNotAnchored:
+		return metrics.ResultUnanchored
+	case models.VerificationStatusLedgerUnreachable:
+		return metrics.ResultError
+	default:
+		return metrics.ResultMismatched
+	}
+}
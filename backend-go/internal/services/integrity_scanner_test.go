@@ -0,0 +1,109 @@
+package services
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestIntegrityScanner(t *testing.T, batchSize int) (*IntegrityScanner, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm: %v", err)
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	cfg := config.ScannerConfig{Mode: config.ScannerModeSample, BatchSize: batchSize}
+	return NewIntegrityScanner(db, newTestVerificationService(newFakeFabric()), cfg, log), mock
+}
+
+func TestSampleLogsWrapsAroundFromRandomStart(t *testing.T) {
+	scanner, mock := newTestIntegrityScanner(t, 3)
+	cutoff := time.Now()
+	// With a single creation time the random start is that time
+	start := cutoff.Add(-time.Hour)
+	newest, older, oldest := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`SELECT MIN\(created_at\) AS oldest, MAX\(created_at\) AS newest FROM "logs" WHERE created_at < \$1`).
+		WithArgs(cutoff).
+		WillReturnRows(sqlmock.NewRows([]string{"oldest", "newest"}).AddRow(start, start))
+	mock.ExpectQuery(`SELECT \* FROM "logs" WHERE \(created_at >= \$1 AND created_at < \$2\) .* ORDER BY created_at, id LIMIT 3`).
+		WithArgs(start, cutoff).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newest))
+	mock.ExpectQuery(`SELECT \* FROM "logs" WHERE created_at < \$1 .* ORDER BY created_at, id LIMIT 2`).
+		WithArgs(start).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(oldest).AddRow(older))
+
+	logs, err := scanner.sampleLogs(cutoff)
+	if err != nil {
+		t.Fatalf("sampleLogs: %v", err)
+	}
+	want := []uuid.UUID{newest, oldest, older}
+	if len(logs) != len(want) {
+		t.Fatalf("sampled %d logs, want %d", len(logs), len(want))
+	}
+	for i, id := range want {
+		if logs[i].ID != id {
+			t.Errorf("logs[%d] = %s, want %s", i, logs[i].ID, id)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSampleLogsStopsWhenBatchIsFull(t *testing.T) {
+	scanner, mock := newTestIntegrityScanner(t, 1)
+	cutoff := time.Now()
+	oldest, newest := cutoff.Add(-2*time.Hour), cutoff.Add(-time.Hour)
+
+	mock.ExpectQuery(`SELECT MIN\(created_at\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"oldest", "newest"}).AddRow(oldest, newest))
+	mock.ExpectQuery(`SELECT \* FROM "logs" WHERE \(created_at >= \$1 AND created_at < \$2\)`).
+		WithArgs(within{oldest, newest}, cutoff).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+
+	logs, err := scanner.sampleLogs(cutoff)
+	if err != nil {
+		t.Fatalf("sampleLogs: %v", err)
+	}
+	if len(logs) != 1 {
+		t.Errorf("sampled %d logs, want 1", len(logs))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSampleLogsEmptyTable(t *testing.T) {
+	scanner, mock := newTestIntegrityScanner(t, 3)
+
+	mock.ExpectQuery(`SELECT MIN\(created_at\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"oldest", "newest"}).AddRow(nil, nil))
+
+	logs, err := scanner.sampleLogs(time.Now())
+	if err != nil {
+		t.Fatalf("sampleLogs: %v", err)
+	}
+	if len(logs) != 0 {
+		t.Errorf("sampled %d logs from an empty table", len(logs))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -3,14 +3,20 @@ package services
 import (
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"time"
 
+	"github.com/banking-audit-ledger/backend/internal/config"
 	"github.com/banking-audit-ledger/backend/internal/fabric"
 	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/banking-audit-ledger/backend/pkg/jcs"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // FabricClient defines the interface for blockchain operations
@@ -18,73 +24,163 @@ type FabricClient interface {
 	CommitLogHash(logID, hash string, metadata map[string]string) (string, error)
 	GetLogHash(logID string) (*fabric.LogHash, error)
 	VerifyLogHash(logID, providedHash string) (bool, error)
+	GetLogHashesByHash(hash string) ([]fabric.LogHash, error)
+	CommitBatchRoot(batchID, root string, leafCount int, metadata map[string]string) (string, error)
+	GetBatchRoot(batchID string) (*fabric.BatchRoot, error)
 	Close()
 }
 
+// ErrInvalidPayload is returned when a log payload cannot be canonicalized for hashing
+var ErrInvalidPayload = errors.New("invalid payload")
+
+// ErrSourceNotAllowed is returned when the caller's credential may not write under a log's source
+var ErrSourceNotAllowed = errors.New("source not allowed")
+
+// ReceiptIssuer signs ingestion receipts for created logs
+type ReceiptIssuer interface {
+	Issue(log *models.LogResponse) (string, error)
+}
+
 // LogService handles log-related operations
 type LogService struct {
-	db     *gorm.DB
-	fabric FabricClient
-	logger *logrus.Logger
+	db       *gorm.DB
+	fabric   FabricClient
+	outbox   *Outbox
+	batcher  *Batcher
+	receipts ReceiptIssuer
+	cfg      config.IngestConfig
+	logger   *logrus.Logger
 }
 
-// NewLogService creates a new log service
-func NewLogService(db *gorm.DB, fabricClient FabricClient, logger *logrus.Logger) *LogService {
+// NewLogService creates a new log service. When batcher is nil every log is
+// anchored with its own blockchain transaction; when receipts is nil no
+// receipts are issued.
+func NewLogService(db *gorm.DB, fabricClient FabricClient, outbox *Outbox, batcher *Batcher, receipts ReceiptIssuer, cfg config.IngestConfig, logger *logrus.Logger) *LogService {
 	return &LogService{
-		db:     db,
-		fabric: fabricClient,
-		logger: logger,
+		db:       db,
+		fabric:   fabricClient,
+		outbox:   outbox,
+		batcher:  batcher,
+		receipts: receipts,
+		cfg:      cfg,
+		logger:   logger,
 	}
 }
 
-// CreateLog creates a new audit log
+// CreateLog creates a new audit log and attaches a signed receipt of its
+// acceptance. Replays get a fresh receipt with the current commit status.
 func (s *LogService) CreateLog(req *models.CreateLogRequest) (*models.LogResponse, error) {
-	// Convert payload to JSON string
-	payloadBytes, err := json.Marshal(req.Payload)
+	response, err := s.createLog(req)
+	if err != nil || s.receipts == nil {
+		return response, err
+	}
+
+	// The log is stored either way, so a receipt failure does not fail the request
+	receipt, err := s.receipts.Issue(response)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		s.logger.WithError(err).WithField("logID", response.ID).Error("Failed to issue receipt")
+		return response, nil
 	}
-	payloadStr := string(payloadBytes)
+	response.Receipt = receipt
+	return response, nil
+}
 
-	// Compute SHA256 hash
-	hash := fmt.Sprintf("%x", sha256.Sum256(payloadBytes))
+// createLog creates a new audit log
+func (s *LogService) createLog(req *models.CreateLogRequest) (*models.LogResponse, error) {
+	log, canonical, err := prepareLog(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := newSignatureChecker(s.db).check(req, log); err != nil {
+		return nil, err
+	}
 
-	// Create log entry
-	log := &models.Log{
-		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		Source:    req.Source,
-		EventType: req.EventType,
-		Payload:   payloadStr,
-		Hash:      hash,
+	fingerprint, err := requestFingerprint(req, canonical)
+	if err != nil {
+		return nil, err
+	}
+	if req.IdempotencyKey != "" {
+		if response, err := s.replayIdempotencyKey(log.Source, req.IdempotencyKey, fingerprint); response != nil || err != nil {
+			return response, err
+		}
+	}
+	if req.LogID != "" {
+		if response, err := s.replayExistingLog(log.ID, req, log.ContentHash); response != nil || err != nil {
+			return response, err
+		}
 	}
 
-	// Save to database
-	if err := s.db.Create(log).Error; err != nil {
-		return nil, fmt.Errorf("failed to save log to database: %w", err)
+	// Chain the log to its source, save it and record its pending blockchain commit atomically
+	var entry *models.OutboxEntry
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := appendToChain(tx, log, canonical); err != nil {
+			return err
+		}
+
+		result := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "id"}}, DoNothing: true}).Create(log)
+		if result.Error != nil {
+			return fmt.Errorf("failed to save log to database: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errLogIDTaken
+		}
+
+		if req.IdempotencyKey != "" {
+			if err := claimIdempotencyKey(tx, req.IdempotencyKey, fingerprint, s.toLogResponse(log)); err != nil {
+				return err
+			}
+		}
+
+		// In batching mode the log waits for the batcher to anchor it in a Merkle root
+		if s.batcher != nil {
+			return nil
+		}
+
+		// Always use database ID for consistency between commit and verification
+		entry, err = s.outbox.Enqueue(tx, log.ID, log.Hash, commitMetadata(log))
+		return err
+	})
+	// A concurrent request with the same key or log ID committed first
+	if errors.Is(err, errIdempotencyKeyTaken) {
+		return s.replayIdempotencyKey(log.Source, req.IdempotencyKey, fingerprint)
 	}
+	if errors.Is(err, errLogIDTaken) {
+		if response, err := s.replayExistingLog(log.ID, req, log.ContentHash); response != nil || err != nil {
+			return response, err
+		}
+		return nil, fmt.Errorf("failed to save log to database: %w", errLogIDTaken)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.batcher != nil {
+		s.batcher.Notify()
 
-	// Commit hash to blockchain
+		s.logger.WithFields(logrus.Fields{
+			"logID":     log.ID,
+			"source":    log.Source,
+			"eventType": log.EventType,
+			"sequence":  *log.Sequence,
+		}).Info("Log created successfully, awaiting batch anchoring")
+
+		return s.toLogResponse(log), nil
+	}
+
+	// Try to commit hash to blockchain right away; the outbox worker retries on failure
 	var txID string
 	if s.fabric != nil {
-		// Always use database ID for consistency between commit and verification
-		txID, err = s.fabric.CommitLogHash(log.ID.String(), hash, map[string]string{
-			"source":     req.Source,
-			"event_type": req.EventType,
-			"created_at": log.CreatedAt.Format(time.RFC3339),
-		})
+		txID, err = s.outbox.Dispatch(entry.ID)
 		if err != nil {
-			s.logger.WithError(err).Error("Failed to commit hash to blockchain")
-			// Don't fail the entire operation, just log the error
-		} else {
-			// Update log with transaction ID
-			log.TxID = &txID
+			s.logger.WithError(err).WithField("logID", log.ID).Error("Failed to commit hash to blockchain, queued for retry")
+			// Don't fail the entire operation, the outbox worker will retry
+		} else if txID != "" {
 			now := time.Now()
+			log.TxID = &txID
 			log.CommittedAt = &now
-			s.db.Save(log)
 		}
 	} else {
-		s.logger.Warning("Fabric client is nil - skipping blockchain commit")
+		s.logger.Warning("Fabric client is nil - blockchain commit left in outbox")
 	}
 
 	s.logger.WithFields(logrus.Fields{
@@ -94,7 +190,12 @@ func (s *LogService) CreateLog(req *models.CreateLogRequest) (*models.LogRespons
 		"txID":     txID,
 	}).Info("Log created successfully")
 
-	return s.toLogResponse(log), nil
+	response := s.toLogResponse(log)
+	if req.IdempotencyKey != "" {
+		s.storeIdempotentResponse(req.IdempotencyKey, response)
+	}
+
+	return response, nil
 }
 
 // GetLog retrieves a log by ID
@@ -110,8 +211,108 @@ func (s *LogService) GetLog(id string) (*models.LogResponse, error) {
 	return s.toLogResponse(&log), nil
 }
 
-// ListLogs retrieves logs with pagination
-func (s *LogService) ListLogs(page, pageSize int, source, eventType string) (*models.ListLogsResponse, error) {
+// prepareLog validates a create request and builds the log it describes,
+// returning it with the canonical form of its payload
+func prepareLog(req *models.CreateLogRequest) (*models.Log, []byte, error) {
+	if req.Source == "" || req.EventType == "" || len(req.Payload) == 0 {
+		return nil, nil, fmt.Errorf("%w: source, event_type and payload are required", ErrInvalidPayload)
+	}
+	if req.AllowedSources != nil && !slices.Contains(req.AllowedSources, req.Source) {
+		return nil, nil, fmt.Errorf("%w: credential may not write under source %q", ErrSourceNotAllowed, req.Source)
+	}
+
+	// Hash the canonical form of the exact bytes received, so any party can
+	// recompute it from the stored original without float or key order drift
+	canonical, err := jcs.Canonicalize(req.Payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	// Clients may supply the log ID so that retries cannot create duplicates
+	logID := uuid.New()
+	if req.LogID != "" {
+		logID, err = uuid.Parse(req.LogID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidLogID, err)
+		}
+	}
+
+	// The creation time is truncated to the precision the database keeps
+	// since it is part of the hash
+	return &models.Log{
+		ID:          logID,
+		CreatedAt:   time.Now().Truncate(time.Microsecond),
+		Source:      req.Source,
+		EventType:   req.EventType,
+		EventTime:   eventTime(req),
+		Payload:     string(req.Payload),
+		RawPayload:  string(req.Payload),
+		ContentHash: fmt.Sprintf("%x", sha256.Sum256(canonical)),
+		HashVersion: models.HashVersionEnvelope,
+	}, canonical, nil
+}
+
+// FindByContent hashes a payload the way CreateLog does and returns every
+// log and ledger entry carrying that hash, for callers holding a document
+// but no log ID
+func (s *LogService) FindByContent(payload []byte) (*models.ContentLookupResponse, error) {
+	canonical, err := jcs.Canonicalize(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	contentHash := fmt.Sprintf("%x", sha256.Sum256(canonical))
+
+	// Logs from before chaining were hashed over the re-marshalled payload
+	// alone, which for most documents is its canonical form, so their log
+	// hash can be the content hash. Later logs carry the content hash, which
+	// migrations backfill for those stored before it existed.
+	var logs []models.Log
+	if err := s.db.Where("content_hash = ? OR hash = ?", contentHash, contentHash).
+		Order("created_at").
+		Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find logs: %w", err)
+	}
+
+	response := &models.ContentLookupResponse{
+		ContentHash:   contentHash,
+		Logs:          make([]models.LogResponse, len(logs)),
+		LedgerEntries: []models.LedgerEntry{},
+	}
+	for i := range logs {
+		response.Logs[i] = *s.toLogResponse(&logs[i])
+	}
+
+	// Batched logs are only anchored through their batch root and are found
+	// through the database alone
+	entries, err := s.fabric.GetLogHashesByHash(contentHash)
+	if err != nil {
+		s.logger.WithError(err).WithField("contentHash", contentHash).Error("Failed to look up hash on blockchain")
+		response.LedgerError = err.Error()
+	}
+	for _, entry := range entries {
+		response.LedgerEntries = append(response.LedgerEntries, models.LedgerEntry{
+			LogID:     entry.LogID,
+			Hash:      entry.Hash,
+			TxID:      entry.TxID,
+			Timestamp: entry.Timestamp,
+			Metadata:  entry.Metadata,
+		})
+	}
+	response.Anchored = len(response.LedgerEntries) > 0
+	response.CheckedAt = time.Now()
+
+	s.logger.WithFields(logrus.Fields{
+		"contentHash":   contentHash,
+		"logs":          len(response.Logs),
+		"ledgerEntries": len(response.LedgerEntries),
+	}).Info("Content lookup completed")
+
+	return response, nil
+}
+
+// ListLogs retrieves logs with pagination, restricted to allowedSources
+// unless it is nil
+func (s *LogService) ListLogs(page, pageSize int, source, eventType string, allowedSources []string) (*models.ListLogsResponse, error) {
 	var logs []models.Log
 	var total int64
 
@@ -124,6 +325,9 @@ func (s *LogService) ListLogs(page, pageSize int, source, eventType string) (*mo
 	if eventType != "" {
 		query = query.Where("event_type = ?", eventType)
 	}
+	if allowedSources != nil {
+		query = query.Where("source IN ?", allowedSources)
+	}
 
 	// Count total records
 	if err := query.Count(&total).Error; err != nil {
@@ -153,22 +357,60 @@ func (s *LogService) ListLogs(page, pageSize int, source, eventType string) (*mo
 	}, nil
 }
 
+// commitMetadata builds the metadata anchored on-chain alongside a log hash
+func commitMetadata(log *models.Log) map[string]string {
+	metadata := map[string]string{
+		"source":     log.Source,
+		"event_type": log.EventType,
+		"created_at": log.CreatedAt.Format(time.RFC3339),
+	}
+	if log.Sequence != nil && log.PrevHash != nil {
+		metadata["sequence"] = strconv.FormatInt(*log.Sequence, 10)
+		metadata["prev_hash"] = *log.PrevHash
+	}
+	if log.HashVersion >= models.HashVersionEnvelope {
+		metadata["hash_version"] = strconv.Itoa(log.HashVersion)
+	}
+	if log.ContentHash != "" {
+		metadata["content_hash"] = log.ContentHash
+	}
+	if log.EventTime != nil {
+		metadata["event_time"] = log.EventTime.UTC().Format(time.RFC3339Nano)
+	}
+	if log.SignatureKeyID != nil {
+		metadata["signer_key_id"] = *log.SignatureKeyID
+	}
+	return metadata
+}
+
 // toLogResponse converts a Log model to LogResponse
 func (s *LogService) toLogResponse(log *models.Log) *models.LogResponse {
+	// Prefer the original bytes, the jsonb column does not preserve them
 	var payload interface{}
-	if err := json.Unmarshal([]byte(log.Payload), &payload); err != nil {
+	if log.RawPayload != "" {
+		payload = json.RawMessage(log.RawPayload)
+	} else if err := json.Unmarshal([]byte(log.Payload), &payload); err != nil {
 		s.logger.WithError(err).Error("Failed to unmarshal payload")
 		payload = log.Payload
 	}
 
 	return &models.LogResponse{
-		ID:          log.ID,
-		CreatedAt:   log.CreatedAt,
-		Source:      log.Source,
-		EventType:   log.EventType,
-		Payload:     payload,
-		Hash:        log.Hash,
-		TxID:        log.TxID,
-		CommittedAt: log.CommittedAt,
+		ID:             log.ID,
+		CreatedAt:      log.CreatedAt,
+		Source:         log.Source,
+		EventType:      log.EventType,
+		EventTime:      log.EventTime,
+		Payload:        payload,
+		Hash:           log.Hash,
+		ContentHash:    log.ContentHash,
+		HashVersion:    log.HashVersion,
+		Sequence:       log.Sequence,
+		PrevHash:       log.PrevHash,
+		TxID:           log.TxID,
+		CommittedAt:    log.CommittedAt,
+		BatchID:        log.BatchID,
+		LeafIndex:      log.LeafIndex,
+		Signature:      log.Signature,
+		SignatureKeyID: log.SignatureKeyID,
 	}
 }
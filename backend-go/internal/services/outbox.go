@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Outbox stores pending blockchain commits alongside their logs and
+// drains them in the background until every hash is anchored
+type Outbox struct {
+	db     *gorm.DB
+	fabric FabricClient
+	cfg    config.OutboxConfig
+	logger *logrus.Logger
+}
+
+// NewOutbox creates a new blockchain commit outbox
+func NewOutbox(db *gorm.DB, fabricClient FabricClient, cfg config.OutboxConfig, logger *logrus.Logger) *Outbox {
+	return &Outbox{
+		db:     db,
+		fabric: fabricClient,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Enqueue records a pending commit for the given log. It must be called with
+// the same transaction that inserts the log row.
+func (o *Outbox) Enqueue(tx *gorm.DB, logID uuid.UUID, hash string, metadata map[string]string) (*models.OutboxEntry, error) {
+	entry, err := newOutboxEntry(hash, metadata)
+	if err != nil {
+		return nil, err
+	}
+	entry.LogID = &logID
+
+	if err := tx.Create(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to enqueue blockchain commit: %w", err)
+	}
+
+	return entry, nil
+}
+
+// EnqueueBatch records a pending commit for the root of a batch. It must be
+// called with the same transaction that creates the batch.
+func (o *Outbox) EnqueueBatch(tx *gorm.DB, batch *models.AnchorBatch, metadata map[string]string) (*models.OutboxEntry, error) {
+	entry, err := newOutboxEntry(batch.Root, metadata)
+	if err != nil {
+		return nil, err
+	}
+	entry.BatchID = &batch.ID
+	entry.LeafCount = batch.LeafCount
+
+	if err := tx.Create(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to enqueue batch commit: %w", err)
+	}
+
+	return entry, nil
+}
+
+// Requeue resets the outbox entry for a log to pending, creating it if the
+// log predates the outbox, so that the hash is committed again
+func (o *Outbox) Requeue(logID uuid.UUID, hash string, metadata map[string]string) (*models.OutboxEntry, error) {
+	entry, err := newOutboxEntry(hash, metadata)
+	if err != nil {
+		return nil, err
+	}
+	entry.LogID = &logID
+
+	return o.requeue(entry, "log_id", logID)
+}
+
+// RequeueBatch resets the outbox entry for a batch to pending so that its
+// root is committed again
+func (o *Outbox) RequeueBatch(batch *models.AnchorBatch, metadata map[string]string) (*models.OutboxEntry, error) {
+	entry, err := newOutboxEntry(batch.Root, metadata)
+	if err != nil {
+		return nil, err
+	}
+	entry.BatchID = &batch.ID
+	entry.LeafCount = batch.LeafCount
+
+	return o.requeue(entry, "batch_id", batch.ID)
+}
+
+// MarkAnchored records a transaction ID found on the ledger for a log and
+// completes any pending outbox entry for it
+func (o *Outbox) MarkAnchored(logID uuid.UUID, txID string, committedAt time.Time) error {
+	return o.db.Transaction(func(tx *gorm.DB) error {
+		if err := markLogsAnchored(tx.Where("id = ?", logID), txID, committedAt); err != nil {
+			return err
+		}
+		return completeOutboxEntries(tx.Where("log_id = ?", logID))
+	})
+}
+
+// MarkBatchAnchored records a transaction ID found on the ledger for a batch
+// and its logs and completes any pending outbox entry for it
+func (o *Outbox) MarkBatchAnchored(batchID uuid.UUID, txID string, committedAt time.Time) error {
+	return o.db.Transaction(func(tx *gorm.DB) error {
+		if err := markBatchAnchored(tx, batchID, txID, committedAt); err != nil {
+			return err
+		}
+		return completeOutboxEntries(tx.Where("batch_id = ?", batchID))
+	})
+}
+
+// Dispatch attempts to commit a single pending entry to the blockchain.
+// The entry is claimed with a lease that pushes its next attempt past the
+// longest a commit may take, so concurrent dispatchers never submit the same
+// hash twice and no database transaction is held while the ledger is
+// reached. It returns an empty transaction ID without error if the entry was
+// already claimed or completed.
+func (o *Outbox) Dispatch(entryID uint) (string, error) {
+	if o.fabric == nil {
+		return "", fmt.Errorf("fabric client is not available")
+	}
+
+	entry, err := o.claim(entryID)
+	if err != nil || entry == nil {
+		return "", err
+	}
+
+	var metadata map[string]string
+	if err := json.Unmarshal([]byte(entry.Metadata), &metadata); err != nil {
+		return "", fmt.Errorf("failed to unmarshal outbox metadata: %w", err)
+	}
+
+	var txID string
+	var commitErr error
+	if entry.BatchID != nil {
+		txID, commitErr = o.fabric.CommitBatchRoot(entry.BatchID.String(), entry.Hash, entry.LeafCount, metadata)
+	} else {
+		txID, commitErr = o.fabric.CommitLogHash(entry.LogID.String(), entry.Hash, metadata)
+	}
+	if commitErr != nil {
+		if err := o.db.Model(&models.OutboxEntry{}).
+			Where("id = ? AND status = ?", entry.ID, models.OutboxStatusPending).
+			Updates(map[string]interface{}{
+				"last_error":      commitErr.Error(),
+				"next_attempt_at": time.Now().Add(o.backoff(entry.Attempts)),
+			}).Error; err != nil {
+			return "", fmt.Errorf("failed to record outbox attempt: %w", err)
+		}
+		return "", fmt.Errorf("failed to commit hash to blockchain: %w", commitErr)
+	}
+
+	now := time.Now()
+	err = o.db.Transaction(func(tx *gorm.DB) error {
+		if entry.BatchID != nil {
+			if err := markBatchAnchored(tx, *entry.BatchID, txID, now); err != nil {
+				return err
+			}
+		} else if err := markLogsAnchored(tx.Where("id = ?", *entry.LogID), txID, now); err != nil {
+			return err
+		}
+
+		return tx.Model(&models.OutboxEntry{}).Where("id = ?", entry.ID).Updates(map[string]interface{}{
+			"status":       models.OutboxStatusCompleted,
+			"last_error":   nil,
+			"processed_at": now,
+		}).Error
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to record outbox commit: %w", err)
+	}
+
+	return txID, nil
+}
+
+// claim leases a due pending entry to this dispatcher by moving its next
+// attempt past the lease and counting the attempt. It returns nil if the
+// entry is not due, is leased to another dispatcher or is completed.
+func (o *Outbox) claim(entryID uint) (*models.OutboxEntry, error) {
+	now := time.Now()
+	var entry models.OutboxEntry
+	result := o.db.Model(&entry).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", entryID, models.OutboxStatusPending, now).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": now.Add(o.cfg.Lease),
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to claim outbox entry: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// Run drains due outbox entries every poll interval until the context is cancelled
+func (o *Outbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.cfg.PollInterval)
+	defer ticker.Stop()
+
+	o.logger.WithFields(logrus.Fields{
+		"component":    "outbox",
+		"pollInterval": o.cfg.PollInterval,
+		"batchSize":    o.cfg.BatchSize,
+	}).Info("Outbox worker started")
+
+	for {
+		if err := o.drain(ctx); err != nil {
+			o.logger.WithError(err).WithField("component", "outbox").Error("Failed to drain outbox")
+		}
+
+		select {
+		case <-ctx.Done():
+			o.logger.WithField("component", "outbox").Info("Outbox worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain dispatches one batch of entries whose next attempt is due
+func (o *Outbox) drain(ctx context.Context) error {
+	var ids []uint
+	if err := o.db.Model(&models.OutboxEntry{}).
+		Where("status = ? AND next_attempt_at <= ?", models.OutboxStatusPending, time.Now()).
+		Order("next_attempt_at").
+		Limit(o.cfg.BatchSize).
+		Pluck("id", &ids).Error; err != nil {
+		return fmt.Errorf("failed to load pending outbox entries: %w", err)
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		txID, err := o.Dispatch(id)
+		if err != nil {
+			o.logger.WithError(err).WithFields(logrus.Fields{
+				"component": "outbox",
+				"entryID":   id,
+			}).Warning("Outbox commit attempt failed")
+			continue
+		}
+		if txID != "" {
+			o.logger.WithFields(logrus.Fields{
+				"component": "outbox",
+				"entryID":   id,
+				"txID":      txID,
+			}).Info("Outbox entry committed")
+		}
+	}
+
+	return nil
+}
+
+// backoff returns the delay before the next attempt, doubling per attempt up to the configured maximum
+func (o *Outbox) backoff(attempts int) time.Duration {
+	delay := o.cfg.BaseBackoff
+	for i := 1; i < attempts && delay < o.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > o.cfg.MaxBackoff {
+		delay = o.cfg.MaxBackoff
+	}
+	return delay
+}
+
+// requeue upserts a pending entry keyed on the given unique column
+func (o *Outbox) requeue(entry *models.OutboxEntry, column string, id uuid.UUID) (*models.OutboxEntry, error) {
+	if err := o.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: column}},
+		DoUpdates: clause.AssignmentColumns([]string{"hash", "leaf_count", "metadata", "status", "next_attempt_at", "processed_at", "updated_at"}),
+	}).Create(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to requeue blockchain commit: %w", err)
+	}
+
+	// The returned ID is not populated on conflict, so read it back
+	if err := o.db.Where(column+" = ?", id).First(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to load requeued outbox entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// newOutboxEntry creates a pending entry that is due immediately
+func newOutboxEntry(hash string, metadata map[string]string) (*models.OutboxEntry, error) {
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	return &models.OutboxEntry{
+		Hash:          hash,
+		Metadata:      string(metadataBytes),
+		Status:        models.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}, nil
+}
+
+// markLogsAnchored stores the anchoring transaction on the logs matched by query
+func markLogsAnchored(query *gorm.DB, txID string, committedAt time.Time) error {
+	if err := query.Model(&models.Log{}).Updates(map[string]interface{}{
+		"tx_id":        txID,
+		"committed_at": committedAt,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update log with transaction ID: %w", err)
+	}
+	return nil
+}
+
+// markBatchAnchored stores the anchoring transaction on a batch and all of its logs
+func markBatchAnchored(tx *gorm.DB, batchID uuid.UUID, txID string, committedAt time.Time) error {
+	if err := tx.Model(&models.AnchorBatch{}).Where("id = ?", batchID).Updates(map[string]interface{}{
+		"tx_id":        txID,
+		"committed_at": committedAt,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update batch with transaction ID: %w", err)
+	}
+	return markLogsAnchored(tx.Where("batch_id = ?", batchID), txID, committedAt)
+}
+
+// completeOutboxEntries marks the pending entries matched by query as completed
+func completeOutboxEntries(query *gorm.DB) error {
+	if err := query.Model(&models.OutboxEntry{}).
+		Where("status = ?", models.OutboxStatusPending).
+		Updates(map[string]interface{}{
+			"status":       models.OutboxStatusCompleted,
+			"processed_at": time.Now(),
+		}).Error; err != nil {
+		return fmt.Errorf("failed to complete outbox entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/banking-audit-ledger/backend/internal/fabric"
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ErrReconciliationInProgress is returned when a reconciliation run is already active
+var ErrReconciliationInProgress = errors.New("reconciliation already in progress")
+
+// ReconciliationService finds logs that were never anchored and repairs them
+type ReconciliationService struct {
+	db     *gorm.DB
+	fabric FabricClient
+	outbox *Outbox
+	cfg    config.ReconcileConfig
+	anchor config.AnchorConfig
+	logger *logrus.Logger
+
+	running    sync.Mutex
+	mu         sync.RWMutex
+	lastReport *models.ReconciliationReport
+
+	// Each run resumes after the last row the previous run scanned, so rows
+	// that keep failing or mismatching cannot crowd out newer ones. They are
+	// guarded by running.
+	logCursor   scanCursor
+	batchCursor scanCursor
+}
+
+// scanCursor is a position in a created_at, id ordered scan. The zero value
+// is the start of the table.
+type scanCursor struct {
+	createdAt time.Time
+	id        uuid.UUID
+}
+
+// after restricts query to the rows past the cursor in created_at, id order
+func (c scanCursor) after(query *gorm.DB) *gorm.DB {
+	if c.createdAt.IsZero() {
+		return query
+	}
+	return query.Where("created_at > ? OR (created_at = ? AND id > ?)", c.createdAt, c.createdAt, c.id)
+}
+
+// advance moves the cursor past the last of scanned rows, or back to the start
+// once a page comes back short because the end of the table was reached
+func (c *scanCursor) advance(scanned, limit int, createdAt time.Time, id uuid.UUID) {
+	if scanned < limit {
+		*c = scanCursor{}
+		return
+	}
+	*c = scanCursor{createdAt: createdAt, id: id}
+}
+
+// NewReconciliationService creates a new reconciliation service
+func NewReconciliationService(db *gorm.DB, fabricClient FabricClient, outbox *Outbox, cfg config.ReconcileConfig, anchor config.AnchorConfig, logger *logrus.Logger) *ReconciliationService {
+	return &ReconciliationService{
+		db:     db,
+		fabric: fabricClient,
+		outbox: outbox,
+		cfg:    cfg,
+		anchor: anchor,
+		logger: logger,
+	}
+}
+
+// Reconcile scans up to limit logs and batches without a transaction ID or
+// commit time, continuing from where the previous run stopped and wrapping
+// around at the end. Entries already on-chain get their transaction ID backfilled
+// and entries missing on-chain are committed again through the outbox.
+func (s *ReconciliationService) Reconcile(limit int) (*models.ReconciliationReport, error) {
+	if !s.running.TryLock() {
+		return nil, ErrReconciliationInProgress
+	}
+	defer s.running.Unlock()
+
+	if limit <= 0 {
+		limit = s.cfg.BatchSize
+	}
+
+	report := &models.ReconciliationReport{
+		StartedAt: time.Now(),
+		Results:   []models.ReconciliationResult{},
+	}
+
+	// Skip very recent entries, their outbox entries are still being dispatched
+	cutoff := time.Now().Add(-s.cfg.MinAge)
+
+	// Batched logs are anchored through their batch root. In batch mode a log
+	// without an outbox entry is still waiting for the batcher, and anchoring
+	// it on its own would keep it out of every batch.
+	logQuery := s.db.Where("(tx_id IS NULL OR committed_at IS NULL) AND batch_id IS NULL AND created_at <= ?", cutoff)
+	if s.anchor.Mode == config.AnchorModeBatch {
+		logQuery = logQuery.Where("EXISTS (SELECT 1 FROM log_outbox WHERE log_outbox.log_id = logs.id)")
+	}
+	var logs []models.Log
+	if err := s.logCursor.after(logQuery).
+		Order("created_at, id").
+		Limit(limit).
+		Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find unanchored logs: %w", err)
+	}
+
+	var batches []models.AnchorBatch
+	if err := s.batchCursor.after(s.db.
+		Where("(tx_id IS NULL OR committed_at IS NULL) AND created_at <= ?", cutoff)).
+		Order("created_at, id").
+		Limit(limit).
+		Find(&batches).Error; err != nil {
+		return nil, fmt.Errorf("failed to find unanchored batches: %w", err)
+	}
+
+	if len(logs) > 0 {
+		last := logs[len(logs)-1]
+		s.logCursor.advance(len(logs), limit, last.CreatedAt, last.ID)
+	} else {
+		s.logCursor = scanCursor{}
+	}
+	if len(batches) > 0 {
+		last := batches[len(batches)-1]
+		s.batchCursor.advance(len(batches), limit, last.CreatedAt, last.ID)
+	} else {
+		s.batchCursor = scanCursor{}
+	}
+
+	for i := range logs {
+		report.Add(s.reconcileLog(&logs[i]))
+	}
+	for i := range batches {
+		report.Add(s.reconcileBatch(&batches[i]))
+	}
+	report.CompletedAt = time.Now()
+
+	s.mu.Lock()
+	s.lastReport = report
+	s.mu.Unlock()
+
+	s.logger.WithFields(logrus.Fields{
+		"component":   "reconciler",
+		"scanned":     report.Scanned,
+		"backfilled":  report.Backfilled,
+		"recommitted": report.Recommitted,
+		"mismatched":  report.Mismatched,
+		"failed":      report.Failed,
+	}).Info("Reconciliation completed")
+
+	return report, nil
+}
+
+// LastReport returns the report of the most recent reconciliation run, if any
+func (s *ReconciliationService) LastReport() *models.ReconciliationReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastReport
+}
+
+// Run reconciles on the configured interval until the context is cancelled
+func (s *ReconciliationService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	s.logger.WithFields(logrus.Fields{
+		"component": "reconciler",
+		"interval":  s.cfg.Interval,
+	}).Info("Reconciliation job started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.WithField("component", "reconciler").Info("Reconciliation job stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.Reconcile(0); err != nil && !errors.Is(err, ErrReconciliationInProgress) {
+				s.logger.WithError(err).WithField("component", "reconciler").Error("Reconciliation failed")
+			}
+		}
+	}
+}
+
+// reconcileLog checks a single log against the ledger and repairs it
+func (s *ReconciliationService) reconcileLog(log *models.Log) models.ReconciliationResult {
+	result := models.ReconciliationResult{LogID: &log.ID}
+
+	if s.fabric == nil {
+		result.Action = models.ReconcileActionFailed
+		result.Error = "fabric client is not available"
+		return result
+	}
+
+	onChain, err := s.fabric.GetLogHash(log.ID.String())
+	if err != nil && !errors.Is(err, fabric.ErrLogHashNotFound) {
+		result.Action = models.ReconcileActionFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	// Already on-chain: fill in the transaction ID we lost
+	if err == nil {
+		if onChain.Hash != log.Hash {
+			s.logger.WithFields(logrus.Fields{
+				"component":    "reconciler",
+				"logID":        log.ID,
+				"hashOffChain": log.Hash,
+				"hashOnChain":  onChain.Hash,
+			}).Warning("On-chain hash does not match database hash")
+			result.Action = models.ReconcileActionMismatch
+			result.TxID = onChain.TxID
+			return result
+		}
+
+		committedAt, parseErr := time.Parse(time.RFC3339, onChain.Timestamp)
+		if parseErr != nil {
+			committedAt = time.Now()
+		}
+		if err := s.outbox.MarkAnchored(log.ID, onChain.TxID, committedAt); err != nil {
+			result.Action = models.ReconcileActionFailed
+			result.Error = err.Error()
+			return result
+		}
+
+		result.Action = models.ReconcileActionBackfilled
+		result.TxID = onChain.TxID
+		return result
+	}
+
+	// Missing on-chain: commit it again
+	entry, err := s.outbox.Requeue(log.ID, log.Hash, commitMetadata(log))
+	if err != nil {
+		result.Action = models.ReconcileActionFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	txID, err := s.outbox.Dispatch(entry.ID)
+	if err != nil {
+		result.Action = models.ReconcileActionFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Action = models.ReconcileActionRecommitted
+	result.TxID = txID
+	return result
+}
+
+// reconcileBatch checks a single batch root against the ledger and repairs it
+func (s *ReconciliationService) reconcileBatch(batch *models.AnchorBatch) models.ReconciliationResult {
+	result := models.ReconciliationResult{BatchID: &batch.ID}
+
+	if s.fabric == nil {
+		result.Action = models.ReconcileActionFailed
+		result.Error = "fabric client is not available"
+		return result
+	}
+
+	onChain, err := s.fabric.GetBatchRoot(batch.ID.String())
+	if err != nil && !errors.Is(err, fabric.ErrBatchRootNotFound) {
+		result.Action = models.ReconcileActionFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	// Already on-chain: fill in the transaction ID we lost
+	if err == nil {
+		if onChain.Root != batch.Root {
+			s.logger.WithFields(logrus.Fields{
+				"component":    "reconciler",
+				"batchID":      batch.ID,
+				"rootOffChain": batch.Root,
+				"rootOnChain":  onChain.Root,
+			}).Warning("On-chain batch root does not match database root")
+			result.Action = models.ReconcileActionMismatch
+			result.TxID = onChain.TxID
+			return result
+		}
+
+		committedAt, parseErr := time.Parse(time.RFC3339, onChain.Timestamp)
+		if parseErr != nil {
+			committedAt = time.Now()
+		}
+		if err := s.outbox.MarkBatchAnchored(batch.ID, onChain.TxID, committedAt); err != nil {
+			result.Action = models.ReconcileActionFailed
+			result.Error = err.Error()
+			return result
+		}
+
+		result.Action = models.ReconcileActionBackfilled
+		result.TxID = onChain.TxID
+		return result
+	}
+
+	// Missing on-chain: commit it again
+	entry, err := s.outbox.RequeueBatch(batch, batchMetadata(batch))
+	if err != nil {
+		result.Action = models.ReconcileActionFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	txID, err := s.outbox.Dispatch(entry.ID)
+	if err != nil {
+		result.Action = models.ReconcileActionFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Action = models.ReconcileActionRecommitted
+	result.TxID = txID
+	return result
+}
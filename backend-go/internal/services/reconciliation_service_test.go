@@ -0,0 +1,55 @@
+package services
+
+import (
+	"io"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestReconciliationService(t *testing.T, mode string) (*ReconciliationService, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm: %v", err)
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	cfg := config.ReconcileConfig{BatchSize: 10}
+	return NewReconciliationService(db, newFakeFabric(), nil, cfg, config.AnchorConfig{Mode: mode}, log), mock
+}
+
+func TestReconcileLeavesLogsWaitingForTheBatcher(t *testing.T) {
+	tests := []struct {
+		mode      string
+		logsQuery string
+	}{
+		{config.AnchorModeBatch, `SELECT \* FROM "logs" WHERE .*batch_id IS NULL.* AND EXISTS \(SELECT 1 FROM log_outbox WHERE log_outbox.log_id = logs.id\) AND "logs"."deleted_at" IS NULL ORDER BY`},
+		{config.AnchorModeSingle, `SELECT \* FROM "logs" WHERE \(\(tx_id IS NULL OR committed_at IS NULL\) AND batch_id IS NULL AND created_at <= \$1\) AND "logs"."deleted_at" IS NULL ORDER BY`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			service, mock := newTestReconciliationService(t, tt.mode)
+			mock.ExpectQuery(tt.logsQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectQuery(`SELECT \* FROM "anchor_batches"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+			if _, err := service.Reconcile(0); err != nil {
+				t.Fatalf("Reconcile: %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
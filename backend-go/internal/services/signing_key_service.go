@@ -0,0 +1,305 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/banking-audit-ledger/backend/pkg/jcs"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Signature algorithms of registered keys
+const (
+	SignatureAlgorithmEd25519 = "Ed25519"
+	SignatureAlgorithmES256   = "ES256"
+	SignatureAlgorithmES384   = "ES384"
+	SignatureAlgorithmES512   = "ES512"
+)
+
+var (
+	// ErrInvalidSigningKey is returned when a public key cannot be registered
+	ErrInvalidSigningKey = errors.New("invalid signing key")
+	// ErrSigningKeyNotFound is returned for an unknown or foreign key ID
+	ErrSigningKeyNotFound = errors.New("signing key not found")
+	// ErrSignatureRequired is returned for an unsigned log of a source with active keys
+	ErrSignatureRequired = errors.New("signature required")
+	// ErrInvalidLogSignature is returned when a log's signature does not verify
+	ErrInvalidLogSignature = errors.New("invalid log signature")
+)
+
+// SigningKeyService manages the public keys producers sign logs with
+type SigningKeyService struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+// NewSigningKeyService creates a new signing key service
+func NewSigningKeyService(db *gorm.DB, logger *logrus.Logger) *SigningKeyService {
+	return &SigningKeyService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// RegisterKey registers an Ed25519 or ECDSA public key for a source. Key IDs
+// are global and cannot be reused, so a signature always names one key.
+func (s *SigningKeyService) RegisterKey(source string, req *models.RegisterSigningKeyRequest) (*models.SigningKey, error) {
+	publicKey, err := parsePublicKey(req.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	algorithm, err := signatureAlgorithm(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSigningKey, err)
+	}
+
+	key := &models.SigningKey{
+		ID:          req.KeyID,
+		Source:      source,
+		Algorithm:   algorithm,
+		PublicKey:   req.PublicKey,
+		Fingerprint: fmt.Sprintf("%x", sha256.Sum256(der)),
+	}
+	result := s.db.Where("id = ?", key.ID).FirstOrCreate(key)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to save signing key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: key ID %q is already registered", ErrInvalidSigningKey, req.KeyID)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"source":    source,
+		"keyID":     key.ID,
+		"algorithm": algorithm,
+	}).Info("Signing key registered")
+
+	return key, nil
+}
+
+// ListKeys returns the keys registered for a source, revoked ones included
+func (s *SigningKeyService) ListKeys(source string) ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	if err := s.db.Where("source = ?", source).Order("created_at").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeKey stops a key from signing new logs. Logs it signed earlier still
+// verify against it.
+func (s *SigningKeyService) RevokeKey(source, keyID string) error {
+	result := s.db.Model(&models.SigningKey{}).
+		Where("id = ? AND source = ? AND revoked_at IS NULL", keyID, source).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke signing key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrSigningKeyNotFound
+	}
+	return nil
+}
+
+// signatureChecker verifies producer signatures of logs being created,
+// caching keys for the duration of one request
+type signatureChecker struct {
+	db      *gorm.DB
+	keys    map[string]*models.SigningKey
+	sources map[string]bool
+}
+
+func newSignatureChecker(db *gorm.DB) *signatureChecker {
+	return &signatureChecker{db: db, keys: map[string]*models.SigningKey{}, sources: map[string]bool{}}
+}
+
+// check verifies the signature of a prepared log and records it on the log.
+// Unsigned logs are accepted only for sources without active keys.
+func (c *signatureChecker) check(req *models.CreateLogRequest, log *models.Log) error {
+	if req.Signature == "" && req.KeyID == "" {
+		keyed, err := c.sourceHasKeys(log.Source)
+		if err != nil {
+			return err
+		}
+		if keyed {
+			return fmt.Errorf("%w: source %q has registered signing keys", ErrSignatureRequired, log.Source)
+		}
+		return nil
+	}
+
+	if req.Signature == "" || req.KeyID == "" || req.LogID == "" {
+		return fmt.Errorf("%w: signed logs need signature, key_id and log_id", ErrInvalidLogSignature)
+	}
+
+	key, err := c.key(req.KeyID)
+	if err != nil {
+		return err
+	}
+	if key == nil || key.Source != log.Source {
+		return fmt.Errorf("%w: key %q is not registered for source %q", ErrInvalidLogSignature, req.KeyID, log.Source)
+	}
+	if key.RevokedAt != nil {
+		return fmt.Errorf("%w: key %q was revoked", ErrInvalidLogSignature, req.KeyID)
+	}
+
+	log.Signature = &req.Signature
+	log.SignatureKeyID = &req.KeyID
+	return verifyLogSignature(key, log)
+}
+
+// key returns a registered key, or nil if there is none with the ID
+func (c *signatureChecker) key(id string) (*models.SigningKey, error) {
+	if key, ok := c.keys[id]; ok {
+		return key, nil
+	}
+	var key models.SigningKey
+	if err := c.db.Where("id = ?", id).First(&key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.keys[id] = nil
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get signing key: %w", err)
+	}
+	c.keys[id] = &key
+	return &key, nil
+}
+
+// sourceHasKeys reports whether a source has active signing keys
+func (c *signatureChecker) sourceHasKeys(source string) (bool, error) {
+	if keyed, ok := c.sources[source]; ok {
+		return keyed, nil
+	}
+	var count int64
+	if err := c.db.Model(&models.SigningKey{}).Where("source = ? AND revoked_at IS NULL", source).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to count signing keys: %w", err)
+	}
+	c.sources[source] = count > 0
+	return count > 0, nil
+}
+
+// signedDocument is what producers sign: the RFC 8785 canonical form of the
+// log ID, source, event type, event time (when set) and payload
+type signedDocument struct {
+	LogID     string          `json:"log_id"`
+	Source    string          `json:"source"`
+	EventType string          `json:"event_type"`
+	EventTime *string         `json:"event_time,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// signingInput returns the bytes a log's producer signs
+func signingInput(log *models.Log) ([]byte, error) {
+	document := signedDocument{
+		LogID:     log.ID.String(),
+		Source:    log.Source,
+		EventType: log.EventType,
+		Payload:   json.RawMessage(log.RawPayload),
+	}
+	if log.EventTime != nil {
+		eventTime := formatEnvelopeTime(*log.EventTime)
+		document.EventTime = &eventTime
+	}
+
+	marshalled, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal signed document: %w", err)
+	}
+	canonical, err := jcs.Canonicalize(marshalled)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	return canonical, nil
+}
+
+// verifyLogSignature checks the signature recorded on a log against key.
+// Ed25519 signs the input itself; ECDSA signatures are ASN.1 DER over its
+// SHA-2 digest matching the curve size.
+func verifyLogSignature(key *models.SigningKey, log *models.Log) error {
+	if log.Signature == nil {
+		return fmt.Errorf("%w: log is not signed", ErrInvalidLogSignature)
+	}
+	signature, err := base64.StdEncoding.DecodeString(*log.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: signature is not base64: %v", ErrInvalidLogSignature, err)
+	}
+	publicKey, err := parsePublicKey(key.PublicKey)
+	if err != nil {
+		return err
+	}
+	input, err := signingInput(log)
+	if err != nil {
+		return err
+	}
+
+	valid := false
+	switch publicKey := publicKey.(type) {
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(publicKey, input, signature)
+	case *ecdsa.PublicKey:
+		var digest []byte
+		switch publicKey.Curve {
+		case elliptic.P256():
+			sum := sha256.Sum256(input)
+			digest = sum[:]
+		case elliptic.P384():
+			sum := sha512.Sum384(input)
+			digest = sum[:]
+		default:
+			sum := sha512.Sum512(input)
+			digest = sum[:]
+		}
+		valid = ecdsa.VerifyASN1(publicKey, digest, signature)
+	}
+	if !valid {
+		return fmt.Errorf("%w: signature does not match key %q", ErrInvalidLogSignature, key.ID)
+	}
+	return nil
+}
+
+// parsePublicKey decodes a PEM encoded PKIX public key
+func parsePublicKey(encoded string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, fmt.Errorf("%w: public key is not PEM encoded", ErrInvalidSigningKey)
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSigningKey, err)
+	}
+	return publicKey, nil
+}
+
+// signatureAlgorithm names the algorithm signatures of a key are made with
+func signatureAlgorithm(publicKey crypto.PublicKey) (string, error) {
+	switch publicKey := publicKey.(type) {
+	case ed25519.PublicKey:
+		return SignatureAlgorithmEd25519, nil
+	case *ecdsa.PublicKey:
+		switch publicKey.Curve {
+		case elliptic.P256():
+			return SignatureAlgorithmES256, nil
+		case elliptic.P384():
+			return SignatureAlgorithmES384, nil
+		case elliptic.P521():
+			return SignatureAlgorithmES512, nil
+		}
+	}
+	return "", fmt.Errorf("%w: only Ed25519 and ECDSA P-256, P-384 and P-521 keys are supported", ErrInvalidSigningKey)
+}
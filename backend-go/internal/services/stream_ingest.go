@@ -0,0 +1,160 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// IngestItem is one record of an ingest stream. Line identifies it in the
+// results; Err reports a record that could not be decoded.
+type IngestItem struct {
+	Line int
+	Req  models.CreateLogRequest
+	Err  error
+}
+
+// IngestStream creates logs from newline-delimited JSON, one CreateLogRequest
+// per line, restricted to allowedSources unless it is nil. Blank lines are
+// skipped and results carry line numbers.
+func (s *LogService) IngestStream(r io.Reader, allowedSources []string, emit func(models.StreamIngestResult) error) (*models.StreamIngestSummary, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), s.cfg.StreamMaxLineBytes)
+
+	lineNumber := 0
+	next := func() (IngestItem, error) {
+		for scanner.Scan() {
+			lineNumber++
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+
+			item := IngestItem{Line: lineNumber}
+			if err := json.Unmarshal(line, &item.Req); err != nil {
+				item.Err = fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+			}
+			item.Req.AllowedSources = allowedSources
+			return item, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return IngestItem{}, fmt.Errorf("failed to read line %d: %w", lineNumber+1, err)
+		}
+		return IngestItem{}, io.EOF
+	}
+
+	return s.Ingest(next, emit)
+}
+
+// Ingest creates logs from the items returned by next until it returns
+// io.EOF. Items are created in chunks through CreateLogs, so the source is
+// only consumed as fast as logs are stored and memory stays bounded by the
+// chunk size. emit is called with the result of every item, in order, as
+// soon as its chunk is stored; an error from emit stops the ingest.
+func (s *LogService) Ingest(next func() (IngestItem, error), emit func(models.StreamIngestResult) error) (*models.StreamIngestSummary, error) {
+	chunkSize := s.cfg.StreamChunkSize
+	if chunkSize <= 0 || chunkSize > s.cfg.BatchMaxItems {
+		chunkSize = s.cfg.BatchMaxItems
+	}
+
+	summary := &models.StreamIngestSummary{}
+	var chunk []IngestItem
+	flush := func() error {
+		results, err := s.ingestChunk(chunk)
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			switch result.Status {
+			case models.BatchItemCreated:
+				summary.Created++
+			case models.BatchItemReplayed:
+				summary.Replayed++
+			default:
+				summary.Failed++
+			}
+			if err := emit(result); err != nil {
+				return err
+			}
+		}
+		chunk = chunk[:0]
+		return nil
+	}
+
+	for {
+		item, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return summary, err
+		}
+		summary.Lines++
+
+		chunk = append(chunk, item)
+		if len(chunk) >= chunkSize {
+			if err := flush(); err != nil {
+				return summary, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return summary, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"lines":    summary.Lines,
+		"created":  summary.Created,
+		"replayed": summary.Replayed,
+		"failed":   summary.Failed,
+	}).Info("Log stream ingested successfully")
+
+	return summary, nil
+}
+
+// ingestChunk creates the decodable items of a chunk through CreateLogs and
+// returns a result per item in order
+func (s *LogService) ingestChunk(chunk []IngestItem) ([]models.StreamIngestResult, error) {
+	results := make([]models.StreamIngestResult, len(chunk))
+
+	var reqs []models.CreateLogRequest
+	var positions []int
+	for i, item := range chunk {
+		results[i] = models.StreamIngestResult{Line: item.Line, Status: models.BatchItemFailed}
+		if item.Err != nil {
+			results[i].Error = item.Err.Error()
+			continue
+		}
+		reqs = append(reqs, item.Req)
+		positions = append(positions, i)
+	}
+	if len(reqs) == 0 {
+		return results, nil
+	}
+
+	// A concurrent create of one of the client IDs fails the whole chunk
+	response, err := s.CreateLogs(reqs)
+	if err != nil && !errors.Is(err, ErrIdempotencyConflict) {
+		return nil, err
+	}
+	for n, i := range positions {
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		item := response.Results[n]
+		results[i].Status = item.Status
+		results[i].Error = item.Error
+		if item.Log != nil {
+			results[i].LogID = &item.Log.ID
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/banking-audit-ledger/backend/internal/tsa"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// TimestampClient obtains verified RFC 3161 time-stamp tokens for SHA-256 digests
+type TimestampClient interface {
+	Timestamp(digest []byte) ([]byte, *tsa.TokenInfo, error)
+}
+
+// Timestamper obtains RFC 3161 time-stamp tokens from a TSA for anchored
+// hashes: the hash of every individually anchored log and the root of every
+// anchor batch. Batched logs are covered by their batch root's token.
+type Timestamper struct {
+	db     *gorm.DB
+	client TimestampClient
+	cfg    config.TSAConfig
+	logger *logrus.Logger
+}
+
+// NewTimestamper creates a new timestamper
+func NewTimestamper(db *gorm.DB, client TimestampClient, cfg config.TSAConfig, logger *logrus.Logger) *Timestamper {
+	return &Timestamper{
+		db:     db,
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run timestamps pending hashes on the configured interval until the context
+// is cancelled
+func (t *Timestamper) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.cfg.Interval)
+	defer ticker.Stop()
+
+	t.logger.WithFields(logrus.Fields{
+		"component": "timestamper",
+		"interval":  t.cfg.Interval,
+	}).Info("Timestamper started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			t.logger.WithField("component", "timestamper").Info("Timestamper stopped")
+			return
+		case <-ticker.C:
+			stamped, err := t.StampPending()
+			if err != nil {
+				t.logger.WithError(err).WithField("component", "timestamper").Error("Timestamping failed")
+			}
+			if stamped > 0 {
+				t.logger.WithFields(logrus.Fields{
+					"component": "timestamper",
+					"stamped":   stamped,
+				}).Info("Hashes timestamped")
+			}
+		}
+	}
+}
+
+// StampPending obtains tokens for up to the configured number of batch roots
+// and committed logs that have none yet, oldest first, skipping hashes whose
+// last failure is still backing off. A hash the TSA refuses is recorded and
+// the round continues; it stops only when the TSA cannot be reached or the
+// database fails. It returns how many hashes were stamped.
+func (t *Timestamper) StampPending() (int, error) {
+	now := time.Now()
+
+	var batches []models.AnchorBatch
+	if err := t.db.
+		Where("NOT EXISTS (SELECT 1 FROM timestamp_tokens WHERE timestamp_tokens.batch_id = anchor_batches.id)").
+		Where("NOT EXISTS (SELECT 1 FROM timestamp_attempts WHERE timestamp_attempts.batch_id = anchor_batches.id AND timestamp_attempts.next_attempt_at > ?)", now).
+		Order("created_at").
+		Limit(t.cfg.BatchSize).
+		Find(&batches).Error; err != nil {
+		return 0, fmt.Errorf("failed to find batches to timestamp: %w", err)
+	}
+
+	// Only committed logs, since a pending log may still be added to a batch
+	var logs []models.Log
+	if err := t.db.
+		Where("batch_id IS NULL AND tx_id IS NOT NULL").
+		Where("NOT EXISTS (SELECT 1 FROM timestamp_tokens WHERE timestamp_tokens.log_id = logs.id)").
+		Where("NOT EXISTS (SELECT 1 FROM timestamp_attempts WHERE timestamp_attempts.log_id = logs.id AND timestamp_attempts.next_attempt_at > ?)", now).
+		Order("created_at").
+		Limit(t.cfg.BatchSize).
+		Find(&logs).Error; err != nil {
+		return 0, fmt.Errorf("failed to find logs to timestamp: %w", err)
+	}
+
+	stamped := 0
+	for i := range batches {
+		ok, err := t.stamp(nil, &batches[i].ID, batches[i].Root)
+		if err != nil {
+			return stamped, err
+		}
+		if ok {
+			stamped++
+		}
+	}
+	for i := range logs {
+		ok, err := t.stamp(&logs[i].ID, nil, logs[i].Hash)
+		if err != nil {
+			return stamped, err
+		}
+		if ok {
+			stamped++
+		}
+	}
+	return stamped, nil
+}
+
+// stamp obtains and stores a token for a log hash or batch root and reports
+// whether it did. A hash the TSA refuses or answers with an invalid token is
+// recorded as a failed attempt instead of returning an error.
+func (t *Timestamper) stamp(logID, batchID *uuid.UUID, hash string) (bool, error) {
+	digest, err := hex.DecodeString(hash)
+	if err != nil {
+		return false, t.recordFailure(logID, batchID, hash, fmt.Errorf("invalid hash: %w", err))
+	}
+	token, info, err := t.client.Timestamp(digest)
+	if errors.Is(err, tsa.ErrRejected) || errors.Is(err, tsa.ErrInvalidToken) {
+		return false, t.recordFailure(logID, batchID, hash, err)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to timestamp hash %s: %w", hash, err)
+	}
+
+	record := &models.TimestampToken{
+		ID:           uuid.New(),
+		LogID:        logID,
+		BatchID:      batchID,
+		Hash:         hash,
+		Token:        token,
+		GenTime:      info.GenTime,
+		SerialNumber: info.SerialNumber,
+		Policy:       info.Policy,
+	}
+	err = t.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(record).Error; err != nil {
+			return fmt.Errorf("failed to save timestamp token: %w", err)
+		}
+		if err := tx.Where(&models.TimestampAttempt{LogID: logID, BatchID: batchID}).Delete(&models.TimestampAttempt{}).Error; err != nil {
+			return fmt.Errorf("failed to clear timestamp attempts: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// recordFailure counts a failed attempt to timestamp a hash and schedules
+// the next one
+func (t *Timestamper) recordFailure(logID, batchID *uuid.UUID, hash string, cause error) error {
+	var attempt models.TimestampAttempt
+	result := t.db.Where(&models.TimestampAttempt{LogID: logID, BatchID: batchID}).Limit(1).Find(&attempt)
+	if result.Error != nil {
+		return fmt.Errorf("failed to load timestamp attempts: %w", result.Error)
+	}
+	isNew := result.RowsAffected == 0
+	if isNew {
+		attempt = models.TimestampAttempt{ID: uuid.New(), LogID: logID, BatchID: batchID}
+	}
+	attempt.Attempts++
+	attempt.LastError = cause.Error()
+	attempt.NextAttemptAt = time.Now().Add(t.backoff(attempt.Attempts))
+
+	save := t.db.Save
+	if isNew {
+		save = t.db.Create
+	}
+	if err := save(&attempt).Error; err != nil {
+		return fmt.Errorf("failed to record timestamp attempt: %w", err)
+	}
+
+	t.logger.WithError(cause).WithFields(logrus.Fields{
+		"component":       "timestamper",
+		"hash":            hash,
+		"attempts":        attempt.Attempts,
+		"next_attempt_at": attempt.NextAttemptAt,
+	}).Warn("TSA did not timestamp hash")
+	return nil
+}
+
+// backoff returns the delay before the next attempt, doubling per attempt up to the configured maximum
+func (t *Timestamper) backoff(attempts int) time.Duration {
+	delay := t.cfg.BaseBackoff
+	for i := 1; i < attempts && delay < t.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > t.cfg.MaxBackoff {
+		delay = t.cfg.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/banking-audit-ledger/backend/internal/tsa"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeTSA answers every digest with a token unless an error is set for it
+type fakeTSA struct {
+	errs      map[string]error
+	requested []string
+}
+
+func (f *fakeTSA) Timestamp(digest []byte) ([]byte, *tsa.TokenInfo, error) {
+	hash := hex.EncodeToString(digest)
+	f.requested = append(f.requested, hash)
+	if err := f.errs[hash]; err != nil {
+		return nil, nil, err
+	}
+	return []byte("token " + hash), &tsa.TokenInfo{GenTime: time.Now(), SerialNumber: "1", Policy: "1.2.3"}, nil
+}
+
+// within matches a time argument in [from, to]
+type within struct{ from, to time.Time }
+
+func (w within) Match(v driver.Value) bool {
+	t, ok := v.(time.Time)
+	return ok && !t.Before(w.from) && !t.After(w.to)
+}
+
+func newTestTimestamper(t *testing.T, client TimestampClient) (*Timestamper, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm: %v", err)
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	cfg := config.TSAConfig{BatchSize: 10, BaseBackoff: time.Minute, MaxBackoff: time.Hour}
+	return NewTimestamper(db, client, cfg, log), mock
+}
+
+func testHash(s string) string {
+	return fmt.Sprintf("%064x", s)
+}
+
+func TestStampPendingContinuesPastRefusedHashes(t *testing.T) {
+	batchID, refusedID, invalidID, okID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	attemptID := uuid.New()
+	client := &fakeTSA{errs: map[string]error{
+		testHash("refused"): fmt.Errorf("%w: status 2 bad request", tsa.ErrRejected),
+		testHash("invalid"): fmt.Errorf("%w: signature does not verify", tsa.ErrInvalidToken),
+	}}
+	timestamper, mock := newTestTimestamper(t, client)
+	start := time.Now()
+
+	mock.ExpectQuery(`SELECT \* FROM "anchor_batches" WHERE .*timestamp_attempts.next_attempt_at > \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "root"}).AddRow(batchID, testHash("root")))
+	mock.ExpectQuery(`SELECT \* FROM "logs" WHERE .*timestamp_attempts.next_attempt_at > \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hash"}).
+			AddRow(refusedID, testHash("refused")).
+			AddRow(invalidID, testHash("invalid")).
+			AddRow(okID, testHash("ok")))
+
+	// The batch root is stamped
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "timestamp_tokens"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM "timestamp_attempts" WHERE "timestamp_attempts"."batch_id" = \$1`).
+		WithArgs(batchID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	// A hash that failed twice before backs off for four base delays
+	mock.ExpectQuery(`SELECT \* FROM "timestamp_attempts" WHERE "timestamp_attempts"."log_id" = \$1`).
+		WithArgs(refusedID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "log_id", "attempts", "last_error", "next_attempt_at"}).
+			AddRow(attemptID, refusedID, 2, "earlier", start.Add(-time.Minute)))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "timestamp_attempts" SET`).
+		WithArgs(refusedID, nil, 3, sqlmock.AnyArg(), within{start.Add(4 * time.Minute), time.Now().Add(5 * time.Minute)}, sqlmock.AnyArg(), attemptID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// A hash failing for the first time backs off for one base delay
+	mock.ExpectQuery(`SELECT \* FROM "timestamp_attempts" WHERE "timestamp_attempts"."log_id" = \$1`).
+		WithArgs(invalidID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "timestamp_attempts"`).
+		WithArgs(sqlmock.AnyArg(), invalidID, nil, 1, sqlmock.AnyArg(), within{start.Add(time.Minute), time.Now().Add(2 * time.Minute)}, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// The log after them is still stamped
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "timestamp_tokens"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM "timestamp_attempts" WHERE "timestamp_attempts"."log_id" = \$1`).
+		WithArgs(okID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	stamped, err := timestamper.StampPending()
+	if err != nil {
+		t.Fatalf("StampPending: %v", err)
+	}
+	if stamped != 2 {
+		t.Errorf("stamped = %d, want 2", stamped)
+	}
+	if len(client.requested) != 4 {
+		t.Errorf("requested %d hashes, want 4", len(client.requested))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStampPendingStopsWhenTSAUnreachable(t *testing.T) {
+	firstID, secondID := uuid.New(), uuid.New()
+	client := &fakeTSA{errs: map[string]error{
+		testHash("first"): errors.New("failed to reach TSA: connection refused"),
+	}}
+	timestamper, mock := newTestTimestamper(t, client)
+
+	mock.ExpectQuery(`SELECT \* FROM "anchor_batches"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT \* FROM "logs"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hash"}).
+			AddRow(firstID, testHash("first")).
+			AddRow(secondID, testHash("second")))
+
+	// Nothing is recorded against the hash, since the TSA never answered
+	stamped, err := timestamper.StampPending()
+	if err == nil {
+		t.Fatal("StampPending succeeded, want the transport error")
+	}
+	if stamped != 0 {
+		t.Errorf("stamped = %d, want 0", stamped)
+	}
+	if len(client.requested) != 1 {
+		t.Errorf("requested %d hashes, want only the first", len(client.requested))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStampPendingStopsWhenAttemptCannotBeRecorded(t *testing.T) {
+	firstID, secondID := uuid.New(), uuid.New()
+	client := &fakeTSA{errs: map[string]error{
+		testHash("first"): fmt.Errorf("%w: status 2", tsa.ErrRejected),
+	}}
+	timestamper, mock := newTestTimestamper(t, client)
+
+	mock.ExpectQuery(`SELECT \* FROM "anchor_batches"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT \* FROM "logs"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hash"}).
+			AddRow(firstID, testHash("first")).
+			AddRow(secondID, testHash("second")))
+	mock.ExpectQuery(`SELECT \* FROM "timestamp_attempts"`).WillReturnError(errors.New("connection reset"))
+
+	if _, err := timestamper.StampPending(); err == nil {
+		t.Fatal("StampPending succeeded, want the database error")
+	}
+	if len(client.requested) != 1 {
+		t.Errorf("requested %d hashes, want only the first", len(client.requested))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTimestamperBackoff(t *testing.T) {
+	timestamper := &Timestamper{cfg: config.TSAConfig{BaseBackoff: time.Minute, MaxBackoff: 10 * time.Minute}}
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{4, 8 * time.Minute},
+		{5, 10 * time.Minute},
+		{50, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := timestamper.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
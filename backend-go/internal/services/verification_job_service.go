@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidFilter is returned when a verification job filter is inconsistent
+var ErrInvalidFilter = errors.New("invalid verification filter")
+
+// VerificationJobService runs verification over ranges of logs in the
+// background and keeps per-job progress, counts and failure reports
+type VerificationJobService struct {
+	ctx      context.Context
+	db       *gorm.DB
+	verifier *VerificationService
+	cfg      config.VerificationJobConfig
+	logger   *logrus.Logger
+
+	// slots holds a token for each running job
+	slots chan struct{}
+}
+
+// NewVerificationJobService creates a new verification job service. Jobs are
+// stopped when ctx is cancelled.
+func NewVerificationJobService(ctx context.Context, db *gorm.DB, verifier *VerificationService, cfg config.VerificationJobConfig, logger *logrus.Logger) *VerificationJobService {
+	maxRunning := cfg.MaxRunning
+	if maxRunning < 1 {
+		maxRunning = 1
+	}
+	return &VerificationJobService{
+		ctx:      ctx,
+		db:       db,
+		verifier: verifier,
+		cfg:      cfg,
+		logger:   logger,
+		slots:    make(chan struct{}, maxRunning),
+	}
+}
+
+// StartJob records a new job for the given filter and starts it. The job
+// stays pending until fewer than the configured maximum of jobs are running.
+func (s *VerificationJobService) StartJob(req *models.CreateVerificationJobRequest) (*models.VerificationJob, error) {
+	if req.From != nil && req.To != nil && req.From.After(*req.To) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidFilter)
+	}
+
+	job := &models.VerificationJob{
+		ID:        uuid.New(),
+		Status:    models.VerificationJobPending,
+		From:      req.From,
+		To:        req.To,
+		Source:    req.Source,
+		EventType: req.EventType,
+	}
+	if err := s.filter(job).Count(&job.Total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count logs: %w", err)
+	}
+	if err := s.db.Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to save verification job: %w", err)
+	}
+
+	go s.run(job)
+
+	return job, nil
+}
+
+// GetJob retrieves a verification job by ID
+func (s *VerificationJobService) GetJob(id string) (*models.VerificationJob, error) {
+	var job models.VerificationJob
+	if err := s.db.Where("id = ?", id).First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("verification job not found")
+		}
+		return nil, fmt.Errorf("failed to get verification job: %w", err)
+	}
+	return &job, nil
+}
+
+// WriteReportCSV writes the failures of a job as CSV
+func (s *VerificationJobService) WriteReportCSV(jobID uuid.UUID, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"log_id", "status", "hash_offchain", "hash_onchain", "hash_recomputed", "details"}); err != nil {
+		return err
+	}
+
+	err := s.eachFailure(jobID, func(failure *models.VerificationJobFailure) error {
+		return writer.Write([]string{
+			failure.LogID.String(),
+			failure.Status,
+			failure.HashOffChain,
+			failure.HashOnChain,
+			failure.HashRecomputed,
+			failure.Details,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteReportJSON writes the failures of a job as a JSON array
+func (s *VerificationJobService) WriteReportJSON(jobID uuid.UUID, w io.Writer) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	first := true
+	encoder := json.NewEncoder(w)
+	err := s.eachFailure(jobID, func(failure *models.VerificationJobFailure) error {
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		return encoder.Encode(failure)
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]")
+	return err
+}
+
+// RecoverInterrupted marks jobs left running by a previous process as failed
+func (s *VerificationJobService) RecoverInterrupted() error {
+	message := "interrupted by server restart"
+	return s.db.Model(&models.VerificationJob{}).
+		Where("status IN ?", []string{models.VerificationJobPending, models.VerificationJobRunning}).
+		Updates(map[string]interface{}{
+			"status":       models.VerificationJobFailed,
+			"error":        message,
+			"completed_at": time.Now(),
+		}).Error
+}
+
+// run waits for a free slot, then verifies every log matching the job filter
+// with bounded concurrency
+func (s *VerificationJobService) run(job *models.VerificationJob) {
+	logger := s.logger.WithFields(logrus.Fields{"component": "verification-job", "jobID": job.ID})
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-s.ctx.Done():
+		// Left pending, the job is marked interrupted on the next start
+		return
+	}
+
+	startedAt := time.Now()
+	job.Status = models.VerificationJobRunning
+	job.StartedAt = &startedAt
+	if err := s.db.Save(job).Error; err != nil {
+		logger.WithError(err).Error("Failed to start verification job")
+		return
+	}
+	logger.WithField("total", job.Total).Info("Verification job started")
+
+	err := s.verifyAll(job)
+
+	completedAt := time.Now()
+	job.CompletedAt = &completedAt
+	job.Status = models.VerificationJobCompleted
+	if err != nil {
+		message := err.Error()
+		job.Status = models.VerificationJobFailed
+		job.Error = &message
+		logger.WithError(err).Error("Verification job failed")
+	}
+	if err := s.db.Save(job).Error; err != nil {
+		logger.WithError(err).Error("Failed to save verification job")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"processed":  job.Processed,
+		"valid":      job.Valid,
+		"invalid":    job.Invalid,
+		"unanchored": job.Unanchored,
+		"errored":    job.Errored,
+	}).Info("Verification job finished")
+}
+
+// verifyAll pages through matching logs and verifies each page concurrently
+func (s *VerificationJobService) verifyAll(job *models.VerificationJob) error {
+	var cursor *models.Log
+	for {
+		if err := s.ctx.Err(); err != nil {
+			return fmt.Errorf("verification job cancelled: %w", err)
+		}
+
+		// Page on (created_at, id) so logs inserted during the job cannot shift pages
+		query := s.filter(job)
+		if cursor != nil {
+			query = query.Where("(created_at, id) > (?, ?)", cursor.CreatedAt, cursor.ID)
+		}
+		var logs []models.Log
+		if err := query.Order("created_at, id").Limit(s.cfg.PageSize).Find(&logs).Error; err != nil {
+			return fmt.Errorf("failed to load logs: %w", err)
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+		cursor = &logs[len(logs)-1]
+
+		results := s.verifier.verifyMany(logs, s.cfg.Concurrency)
+
+		var failures []models.VerificationJobFailure
+		for _, result := range results {
+			job.Processed++
+			switch result.Status {
+			case models.VerificationStatusValid:
+				job.Valid++
+				continue
+			case models.VerificationStatusNotAnchored:
+				job.Unanchored++
+			case models.VerificationStatusLedgerUnreachable:
+				job.Errored++
+			default:
+				job.Invalid++
+			}
+			failures = append(failures, models.VerificationJobFailure{
+				JobID:          job.ID,
+				LogID:          result.ID,
+				Status:         result.Status,
+				HashOffChain:   result.HashOffChain,
+				HashOnChain:    result.HashOnChain,
+				HashRecomputed: result.HashRecomputed,
+				Details:        result.Details,
+			})
+		}
+
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if len(failures) > 0 {
+				if err := tx.Create(&failures).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Save(job).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save verification progress: %w", err)
+		}
+
+		if len(logs) < s.cfg.PageSize {
+			return nil
+		}
+	}
+}
+
+// filter builds the log query selected by a job
+func (s *VerificationJobService) filter(job *models.VerificationJob) *gorm.DB {
+	query := s.db.Model(&models.Log{})
+	if job.From != nil {
+		query = query.Where("created_at >= ?", *job.From)
+	}
+	if job.To != nil {
+		query = query.Where("created_at < ?", *job.To)
+	}
+	if job.Source != "" {
+		query = query.Where("source = ?", job.Source)
+	}
+	if job.EventType != "" {
+		query = query.Where("event_type = ?", job.EventType)
+	}
+	return query
+}
+
+// eachFailure calls fn for every failure recorded for a job, in pages
+func (s *VerificationJobService) eachFailure(jobID uuid.UUID, fn func(*models.VerificationJobFailure) error) error {
+	var lastID uint
+	for {
+		var failures []models.VerificationJobFailure
+		if err := s.db.Where("job_id = ? AND id > ?", jobID, lastID).
+			Order("id").
+			Limit(s.cfg.PageSize).
+			Find(&failures).Error; err != nil {
+			return fmt.Errorf("failed to load verification failures: %w", err)
+		}
+
+		for i := range failures {
+			if err := fn(&failures[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(failures) < s.cfg.PageSize {
+			return nil
+		}
+		lastID = failures[len(failures)-1].ID
+	}
+}
@@ -1,31 +1,52 @@
 package services
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
+	"github.com/banking-audit-ledger/backend/internal/fabric"
+	"github.com/banking-audit-ledger/backend/internal/merkle"
 	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/banking-audit-ledger/backend/internal/tsa"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+// ErrLogNotBatched is returned when an inclusion proof is requested for a log
+// that was anchored on its own rather than in a Merkle batch
+var ErrLogNotBatched = errors.New("log is not anchored in a batch")
+
+// ErrTimestampNotFound is returned when no time-stamp token covers a log yet
+var ErrTimestampNotFound = errors.New("timestamp token not found")
+
 // VerificationService handles log verification operations
 type VerificationService struct {
-	db     *gorm.DB
-	fabric FabricClient
-	logger *logrus.Logger
+	db         *gorm.DB
+	fabric     FabricClient
+	timestamps *tsa.Client
+	logger     *logrus.Logger
 }
 
-// NewVerificationService creates a new verification service
-func NewVerificationService(db *gorm.DB, fabricClient FabricClient, logger *logrus.Logger) *VerificationService {
+// NewVerificationService creates a new verification service. Time-stamp
+// tokens are only checked when timestamps is not nil.
+func NewVerificationService(db *gorm.DB, fabricClient FabricClient, timestamps *tsa.Client, logger *logrus.Logger) *VerificationService {
 	return &VerificationService{
-		db:     db,
-		fabric: fabricClient,
-		logger: logger,
+		db:         db,
+		fabric:     fabricClient,
+		timestamps: timestamps,
+		logger:     logger,
 	}
 }
 
-// VerifyLog verifies the integrity of a log by comparing off-chain and on-chain hashes
+// VerifyLog verifies the integrity of a log. It recomputes the hash from the
+// stored payload, compares it with the hash column and the ledger, checks the
+// anchored metadata, and classifies any discrepancy it finds.
 func (s *VerificationService) VerifyLog(id string) (*models.VerificationResponse, error) {
 	// Get log from database
 	var log models.Log
@@ -36,39 +57,405 @@ func (s *VerificationService) VerifyLog(id string) (*models.VerificationResponse
 		return nil, fmt.Errorf("failed to get log: %w", err)
 	}
 
-	// Get hash from blockchain
-	blockchainLogHash, err := s.fabric.GetLogHash(id)
-	if err != nil {
-		s.logger.WithError(err).WithField("logID", id).Error("Failed to get hash from blockchain")
-		return &models.VerificationResponse{
-			ID:           log.ID,
-			HashOffChain: log.Hash,
-			HashOnChain:  "",
-			IsValid:      false,
-			VerifiedAt:   time.Now(),
-		}, nil
-	}
-
-	// Compare hashes
-	isValid := log.Hash == blockchainLogHash.Hash
+	verification := s.verify(&log, nil)
 
 	s.logger.WithFields(logrus.Fields{
-		"logID":        id,
-		"hashOffChain": log.Hash,
-		"hashOnChain":  blockchainLogHash.Hash,
-		"isValid":      isValid,
+		"logID":          id,
+		"hashOffChain":   verification.HashOffChain,
+		"hashOnChain":    verification.HashOnChain,
+		"hashRecomputed": verification.HashRecomputed,
+		"status":         verification.Status,
 	}).Info("Log verification completed")
 
-	return &models.VerificationResponse{
+	return verification, nil
+}
+
+// verify runs the full verification of a loaded log. Batched logs share the
+// batches loaded in cache, which may be nil to load them for this log alone.
+func (s *VerificationService) verify(log *models.Log, cache *batchCache) *models.VerificationResponse {
+	verification := &models.VerificationResponse{
 		ID:           log.ID,
 		HashOffChain: log.Hash,
-		HashOnChain:  blockchainLogHash.Hash,
-		IsValid:      isValid,
-		VerifiedAt:   time.Now(),
-	}, nil
+		HashVersion:  log.HashVersion,
+	}
+
+	// A payload that can no longer be hashed has been altered
+	recomputed, err := recomputeLogHash(log)
+	if err != nil {
+		verification.Details = err.Error()
+	}
+	verification.HashRecomputed = recomputed
+
+	if log.BatchID != nil && log.LeafIndex != nil {
+		s.verifyBatched(log, cache, verification)
+	} else {
+		s.verifySingle(log, verification)
+	}
+	s.verifySigner(log, verification)
+	s.verifyTimestamp(log, verification)
+
+	verification.IsValid = verification.Status == models.VerificationStatusValid
+	verification.VerifiedAt = time.Now()
+	return verification
+}
+
+// verifySigner reports who signed a signed log and checks the signature
+// against the registered key. An anchored log whose signature no longer
+// verifies is reported as signature_invalid.
+func (s *VerificationService) verifySigner(log *models.Log, verification *models.VerificationResponse) {
+	if log.SignatureKeyID == nil {
+		return
+	}
+	signer := &models.SignerInfo{KeyID: *log.SignatureKeyID}
+	verification.Signer = signer
+
+	var key models.SigningKey
+	if err := s.db.Where("id = ?", *log.SignatureKeyID).First(&key).Error; err != nil {
+		signer.Details = fmt.Sprintf("failed to load signing key: %v", err)
+	} else {
+		signer.Source = key.Source
+		signer.Algorithm = key.Algorithm
+		signer.Fingerprint = key.Fingerprint
+		if key.Source != log.Source {
+			signer.Details = fmt.Sprintf("key is registered for source %q", key.Source)
+		} else if err := verifyLogSignature(&key, log); err != nil {
+			signer.Details = err.Error()
+		} else {
+			signer.Valid = true
+		}
+	}
+
+	if !signer.Valid && verification.Status == models.VerificationStatusValid {
+		verification.Status = models.VerificationStatusSignatureInvalid
+	}
+}
+
+// verifyTimestamp reports the trusted timestamp of a log and checks its
+// token against the log hash, or the batch root for batched logs. An
+// anchored log whose token no longer verifies is reported as
+// timestamp_invalid; logs not yet stamped have no timestamp.
+func (s *VerificationService) verifyTimestamp(log *models.Log, verification *models.VerificationResponse) {
+	record, hash, err := s.timestampToken(log)
+	if err != nil {
+		if !errors.Is(err, ErrTimestampNotFound) {
+			verification.Timestamp = &models.TimestampInfo{Details: err.Error()}
+		}
+		return
+	}
+	timestamp := &models.TimestampInfo{
+		GenTime:      record.GenTime,
+		SerialNumber: record.SerialNumber,
+		Policy:       record.Policy,
+		Hash:         hash,
+		BatchID:      record.BatchID,
+	}
+	verification.Timestamp = timestamp
+
+	if s.timestamps == nil {
+		timestamp.Details = "trusted timestamps are not configured, token not checked"
+		return
+	}
+	digest, err := hex.DecodeString(hash)
+	if err != nil {
+		timestamp.Details = fmt.Sprintf("failed to decode hash: %v", err)
+	} else if info, err := s.timestamps.Verify(record.Token, digest); err != nil {
+		timestamp.Details = err.Error()
+	} else {
+		timestamp.GenTime = info.GenTime
+		timestamp.Authority = info.Authority
+		timestamp.Valid = true
+	}
+
+	if !timestamp.Valid && verification.Status == models.VerificationStatusValid {
+		verification.Status = models.VerificationStatusTimestampInvalid
+	}
+}
+
+// timestampToken returns the token covering a log and the hash it must
+// cover: the log hash, or the stored root of the log's batch
+func (s *VerificationService) timestampToken(log *models.Log) (*models.TimestampToken, string, error) {
+	query := s.db.Where("log_id = ?", log.ID)
+	hash := log.Hash
+	if log.BatchID != nil {
+		var batch models.AnchorBatch
+		if err := s.db.Where("id = ?", *log.BatchID).First(&batch).Error; err != nil {
+			return nil, "", fmt.Errorf("failed to get batch: %w", err)
+		}
+		query = s.db.Where("batch_id = ?", batch.ID)
+		hash = batch.Root
+	}
+
+	var record models.TimestampToken
+	if err := query.First(&record).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, "", ErrTimestampNotFound
+		}
+		return nil, "", fmt.Errorf("failed to get timestamp token: %w", err)
+	}
+	return &record, hash, nil
+}
+
+// GetTimestampToken returns the DER time-stamp token covering a log, for
+// verification with standard RFC 3161 tools
+func (s *VerificationService) GetTimestampToken(id string) ([]byte, error) {
+	var log models.Log
+	if err := s.db.Where("id = ?", id).First(&log).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("log not found")
+		}
+		return nil, fmt.Errorf("failed to get log: %w", err)
+	}
+
+	record, _, err := s.timestampToken(&log)
+	if err != nil {
+		return nil, err
+	}
+	return record.Token, nil
+}
+
+// verifyMany verifies loaded logs using up to concurrency workers and returns
+// the results in the order of the logs. Each batch the logs belong to is
+// loaded, rebuilt and looked up on the ledger once.
+func (s *VerificationService) verifyMany(logs []models.Log, concurrency int) []*models.VerificationResponse {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	cache := newBatchCache()
+
+	results := make([]*models.VerificationResponse, len(logs))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = s.verify(&logs[i], cache)
+			}
+		}()
+	}
+
+	for i := range logs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// verifySingle checks a log anchored with its own ledger entry. The anchored
+// metadata is compared alongside the hashes: envelope logs hash their
+// metadata, so an edited field also changes the recomputed hash, and the log
+// is reported as metadata_mismatch rather than payload_tampered when its
+// payload still hashes to the anchored hash under the anchored metadata.
+func (s *VerificationService) verifySingle(log *models.Log, verification *models.VerificationResponse) {
+	onChain, err := s.fabric.GetLogHash(log.ID.String())
+	if err != nil {
+		verification.Status, verification.Details = ledgerErrorStatus(err)
+		return
+	}
+	verification.HashOnChain = onChain.Hash
+
+	verification.Status = classifyHashes(
+		log.Hash == onChain.Hash,
+		verification.HashRecomputed == log.Hash,
+		verification.HashRecomputed == onChain.Hash,
+	)
+	mismatches := compareMetadata(expectedMetadata(log, onChain.Metadata), onChain.Metadata)
+	if len(mismatches) == 0 {
+		return
+	}
+
+	switch verification.Status {
+	case models.VerificationStatusValid:
+	case models.VerificationStatusPayloadTampered, models.VerificationStatusHashMismatch:
+		if !payloadMatchesAnchor(log, onChain.Metadata, onChain.Hash) {
+			return
+		}
+		if log.Hash != onChain.Hash {
+			verification.Details = "the hash column also differs from the ledger"
+		}
+	default:
+		return
+	}
+	verification.Status = models.VerificationStatusMetadataMismatch
+	verification.MetadataMismatches = mismatches
+}
+
+// verifyBatched checks a log anchored through the Merkle root of its batch
+func (s *VerificationService) verifyBatched(log *models.Log, cache *batchCache, verification *models.VerificationResponse) {
+	anchored := cache.load(s, *log.BatchID)
+	if anchored.ledgerErr != nil {
+		verification.Status, verification.Details = ledgerErrorStatus(anchored.ledgerErr)
+		return
+	}
+	onChain := anchored.onChain
+	verification.HashOnChain = onChain.Root
+
+	// Siblings on the path never depend on the leaf itself, so they can be
+	// used to test both the stored and the recomputed hash
+	if anchored.treeErr != nil {
+		verification.Status = models.VerificationStatusHashMismatch
+		verification.Details = anchored.treeErr.Error()
+		return
+	}
+	siblings, err := anchored.tree.Proof(*log.LeafIndex)
+	if err != nil {
+		verification.Status = models.VerificationStatusHashMismatch
+		verification.Details = err.Error()
+		return
+	}
+
+	storedIncluded, _ := merkle.VerifyProof(log.Hash, siblings, onChain.Root)
+	recomputedIncluded := false
+	if verification.HashRecomputed != "" {
+		recomputedIncluded, _ = merkle.VerifyProof(verification.HashRecomputed, siblings, onChain.Root)
+	}
+
+	verification.Status = classifyHashes(
+		storedIncluded,
+		verification.HashRecomputed == log.Hash,
+		recomputedIncluded,
+	)
+
+	// Batches anchor no per-log metadata, so an envelope log whose payload
+	// still matches its content hash can only have had its metadata edited,
+	// but which field changed cannot be told
+	if verification.Status == models.VerificationStatusPayloadTampered &&
+		log.HashVersion >= models.HashVersionEnvelope && log.ContentHash != "" {
+		if hash, err := contentHash([]byte(log.RawPayload)); err == nil && hash == log.ContentHash {
+			verification.Status = models.VerificationStatusMetadataMismatch
+			verification.Details = "the payload matches its content hash but the metadata hashed into the batch leaf was changed"
+		}
+	}
+}
+
+// payloadMatchesAnchor reports whether the stored payload of an envelope log
+// is the payload that was anchored. A payload whose content hash was anchored
+// is compared with it directly; otherwise the log hash is recomputed with the
+// anchored metadata in place of the stored metadata.
+func payloadMatchesAnchor(log *models.Log, anchored map[string]string, anchoredHash string) bool {
+	if log.HashVersion < models.HashVersionEnvelope {
+		return false
+	}
+	if want := anchored["content_hash"]; want != "" {
+		hash, err := contentHash([]byte(log.RawPayload))
+		return err == nil && hash == want
+	}
+
+	original := *log
+	if value, ok := anchored["source"]; ok {
+		original.Source = value
+	}
+	if value, ok := anchored["event_type"]; ok {
+		original.EventType = value
+	}
+	// The ledger keeps whole seconds, so the stored time is kept when it
+	// still denotes the anchored second
+	if value, ok := anchored["created_at"]; ok && !metadataValuesEqual("created_at", log.CreatedAt.Format(time.RFC3339), value) {
+		if createdAt, err := time.Parse(time.RFC3339, value); err == nil {
+			original.CreatedAt = createdAt
+		}
+	}
+	original.EventTime = nil
+	if value, ok := anchored["event_time"]; ok {
+		if eventTime, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			original.EventTime = &eventTime
+		}
+	}
+	original.SignatureKeyID = nil
+	if value, ok := anchored["signer_key_id"]; ok {
+		original.SignatureKeyID = &value
+	}
+	if value, ok := anchored["prev_hash"]; ok {
+		original.PrevHash = &value
+	}
+	if value, ok := anchored["sequence"]; ok {
+		if sequence, err := strconv.ParseInt(value, 10, 64); err == nil {
+			original.Sequence = &sequence
+		}
+	}
+
+	hash, err := recomputeLogHash(&original)
+	return err == nil && hash == anchoredHash
+}
+
+// classifyHashes names the discrepancy between the stored hash, the hash
+// recomputed from the payload, and the ledger
+func classifyHashes(storedMatchesLedger, recomputedMatchesStored, recomputedMatchesLedger bool) string {
+	switch {
+	case storedMatchesLedger && recomputedMatchesStored:
+		return models.VerificationStatusValid
+	case storedMatchesLedger:
+		return models.VerificationStatusPayloadTampered
+	case recomputedMatchesLedger:
+		return models.VerificationStatusHashColumnTampered
+	default:
+		return models.VerificationStatusHashMismatch
+	}
 }
 
-// VerifyLogWithHash verifies a log with a provided hash
+// ledgerErrorStatus classifies a failed ledger lookup
+func ledgerErrorStatus(err error) (string, string) {
+	if errors.Is(err, fabric.ErrLogHashNotFound) || errors.Is(err, fabric.ErrBatchRootNotFound) {
+		return models.VerificationStatusNotAnchored, err.Error()
+	}
+	return models.VerificationStatusLedgerUnreachable, err.Error()
+}
+
+// expectedMetadata returns the metadata a log should have anchored. Logs
+// anchored before content hashes were recorded had theirs backfilled later,
+// so a content hash is only expected when the ledger holds one.
+func expectedMetadata(log *models.Log, onChain map[string]string) map[string]string {
+	expected := commitMetadata(log)
+	if _, ok := onChain["content_hash"]; !ok {
+		delete(expected, "content_hash")
+	}
+	return expected
+}
+
+// compareMetadata compares the metadata a log should have anchored with the
+// metadata found on-chain
+func compareMetadata(expected, onChain map[string]string) []models.FieldMismatch {
+	fields := make([]string, 0, len(expected))
+	for field := range expected {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var mismatches []models.FieldMismatch
+	for _, field := range fields {
+		offChainValue := expected[field]
+		onChainValue, ok := onChain[field]
+		if ok && metadataValuesEqual(field, offChainValue, onChainValue) {
+			continue
+		}
+		mismatches = append(mismatches, models.FieldMismatch{
+			Field:    field,
+			OffChain: offChainValue,
+			OnChain:  onChainValue,
+		})
+	}
+	return mismatches
+}
+
+// metadataValuesEqual compares metadata values, treating timestamps as
+// equal when they denote the same instant in any time zone
+func metadataValuesEqual(field, a, b string) bool {
+	if field != "created_at" {
+		return a == b
+	}
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return ta.Equal(tb)
+}
+
+// VerifyLogWithHash checks a caller-supplied hash of a log against the ledger
 func (s *VerificationService) VerifyLogWithHash(id, providedHash string) (*models.VerificationResponse, error) {
 	// Get log from database
 	var log models.Log
@@ -79,31 +466,244 @@ func (s *VerificationService) VerifyLogWithHash(id, providedHash string) (*model
 		return nil, fmt.Errorf("failed to get log: %w", err)
 	}
 
-	// Verify with blockchain
-	isValid, err := s.fabric.VerifyLogHash(id, providedHash)
+	return s.verifyProvidedHash(&log, providedHash), nil
+}
+
+// VerifyLogWithPayload hashes a caller-supplied copy of a log's payload the
+// same way CreateLog did and checks the result against the ledger
+func (s *VerificationService) VerifyLogWithPayload(id string, payload []byte) (*models.VerificationResponse, error) {
+	// Get log from database
+	var log models.Log
+	if err := s.db.Where("id = ?", id).First(&log).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("log not found")
+		}
+		return nil, fmt.Errorf("failed to get log: %w", err)
+	}
+
+	hash, err := hashPayloadAs(&log, payload)
 	if err != nil {
-		s.logger.WithError(err).WithField("logID", id).Error("Failed to verify hash with blockchain")
-		return &models.VerificationResponse{
-			ID:           log.ID,
-			HashOffChain: log.Hash,
-			HashOnChain:  "",
-			IsValid:      false,
-			VerifiedAt:   time.Now(),
-		}, nil
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
 	}
 
+	return s.verifyProvidedHash(&log, hash), nil
+}
+
+// verifyProvidedHash checks whether hash is the hash anchored for log. Logs
+// anchored on their own are checked by the chaincode; batched logs by an
+// inclusion proof against the anchored batch root.
+func (s *VerificationService) verifyProvidedHash(log *models.Log, hash string) *models.VerificationResponse {
+	verification := &models.VerificationResponse{
+		ID:           log.ID,
+		HashOffChain: log.Hash,
+		HashProvided: hash,
+		HashVersion:  log.HashVersion,
+		Status:       models.VerificationStatusHashMismatch,
+	}
+
+	if log.BatchID != nil && log.LeafIndex != nil {
+		onChain, err := s.fabric.GetBatchRoot(log.BatchID.String())
+		if err != nil {
+			verification.Status, verification.Details = ledgerErrorStatus(err)
+		} else {
+			verification.HashOnChain = onChain.Root
+			if included, err := s.proveLeaf(log, hash, onChain.Root); err != nil {
+				verification.Details = err.Error()
+			} else if included {
+				verification.Status = models.VerificationStatusValid
+			}
+		}
+	} else {
+		matches, err := s.fabric.VerifyLogHash(log.ID.String(), hash)
+		switch {
+		case err != nil:
+			verification.Status, verification.Details = ledgerErrorStatus(err)
+		case matches:
+			verification.HashOnChain = hash
+			verification.Status = models.VerificationStatusValid
+		}
+	}
+	s.verifySigner(log, verification)
+	s.verifyTimestamp(log, verification)
+
+	verification.IsValid = verification.Status == models.VerificationStatusValid
+	verification.VerifiedAt = time.Now()
+
 	s.logger.WithFields(logrus.Fields{
-		"logID":        id,
+		"logID":        log.ID,
 		"hashOffChain": log.Hash,
-		"providedHash": providedHash,
-		"isValid":      isValid,
+		"providedHash": hash,
+		"status":       verification.Status,
 	}).Info("Log verification with provided hash completed")
 
-	return &models.VerificationResponse{
-		ID:           log.ID,
-		HashOffChain: log.Hash,
-		HashOnChain:  providedHash,
-		IsValid:      isValid,
-		VerifiedAt:   time.Now(),
-	}, nil
+	return verification
+}
+
+// proveLeaf reports whether hash sits at the log's leaf index under root
+func (s *VerificationService) proveLeaf(log *models.Log, hash, root string) (bool, error) {
+	var batch models.AnchorBatch
+	if err := s.db.Where("id = ?", *log.BatchID).First(&batch).Error; err != nil {
+		return false, fmt.Errorf("failed to get batch: %w", err)
+	}
+
+	tree, err := s.buildBatchTree(&batch)
+	if err != nil {
+		return false, err
+	}
+	siblings, err := tree.Proof(*log.LeafIndex)
+	if err != nil {
+		return false, err
+	}
+
+	return merkle.VerifyProof(hash, siblings, root)
+}
+
+// batchCache holds the batches loaded while verifying one page of logs, so
+// that logs of the same batch share its rebuilt tree and anchored root
+// instead of each reloading every leaf and querying the ledger
+type batchCache struct {
+	mu      sync.Mutex
+	batches map[uuid.UUID]*anchoredBatch
+}
+
+// anchoredBatch is a batch's root on the ledger and its tree rebuilt from the
+// database, or the errors met loading them
+type anchoredBatch struct {
+	once      sync.Once
+	onChain   *fabric.BatchRoot
+	ledgerErr error
+	tree      *merkle.Tree
+	treeErr   error
+}
+
+// newBatchCache creates an empty batch cache
+func newBatchCache() *batchCache {
+	return &batchCache{batches: map[uuid.UUID]*anchoredBatch{}}
+}
+
+// load returns a batch, loading it on first use. A nil cache loads the batch
+// without keeping it.
+func (c *batchCache) load(s *VerificationService, batchID uuid.UUID) *anchoredBatch {
+	if c == nil {
+		batch := &anchoredBatch{}
+		batch.fill(s, batchID)
+		return batch
+	}
+
+	c.mu.Lock()
+	batch, ok := c.batches[batchID]
+	if !ok {
+		batch = &anchoredBatch{}
+		c.batches[batchID] = batch
+	}
+	c.mu.Unlock()
+
+	batch.once.Do(func() { batch.fill(s, batchID) })
+	return batch
+}
+
+// fill looks up the batch root on the ledger and rebuilds its tree
+func (b *anchoredBatch) fill(s *VerificationService, batchID uuid.UUID) {
+	b.onChain, b.ledgerErr = s.fabric.GetBatchRoot(batchID.String())
+	if b.ledgerErr != nil {
+		return
+	}
+
+	var batch models.AnchorBatch
+	if err := s.db.Where("id = ?", batchID).First(&batch).Error; err != nil {
+		b.treeErr = fmt.Errorf("failed to get batch: %v", err)
+		return
+	}
+	b.tree, b.treeErr = s.buildBatchTree(&batch)
+}
+
+// GetInclusionProof builds a Merkle inclusion proof linking a batched log to
+// the batch root anchored on-chain
+func (s *VerificationService) GetInclusionProof(id string) (*models.InclusionProofResponse, error) {
+	// Get log from database
+	var log models.Log
+	if err := s.db.Where("id = ?", id).First(&log).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("log not found")
+		}
+		return nil, fmt.Errorf("failed to get log: %w", err)
+	}
+
+	if log.BatchID == nil || log.LeafIndex == nil {
+		return nil, ErrLogNotBatched
+	}
+
+	var batch models.AnchorBatch
+	if err := s.db.Where("id = ?", *log.BatchID).First(&batch).Error; err != nil {
+		return nil, fmt.Errorf("failed to get batch: %w", err)
+	}
+
+	tree, err := s.buildBatchTree(&batch)
+	if err != nil {
+		return nil, err
+	}
+	if tree.Root() != batch.Root {
+		return nil, fmt.Errorf("batch %s leaves do not match the stored root", batch.ID)
+	}
+
+	siblings, err := tree.Proof(*log.LeafIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build inclusion proof: %w", err)
+	}
+
+	proof := &models.InclusionProofResponse{
+		LogID:       log.ID,
+		LeafHash:    log.Hash,
+		LeafIndex:   *log.LeafIndex,
+		Siblings:    siblings,
+		BatchID:     batch.ID,
+		LeafCount:   batch.LeafCount,
+		BatchRoot:   batch.Root,
+		TxID:        batch.TxID,
+		CommittedAt: batch.CommittedAt,
+		GeneratedAt: time.Now(),
+	}
+
+	// Check the proof against the root on the ledger rather than our own copy
+	onChain, err := s.fabric.GetBatchRoot(batch.ID.String())
+	if err != nil {
+		s.logger.WithError(err).WithField("batchID", batch.ID).Error("Failed to get batch root from blockchain")
+		return proof, nil
+	}
+	proof.RootOnChain = onChain.Root
+	proof.TxID = &onChain.TxID
+
+	proof.IsValid, err = merkle.VerifyProof(log.Hash, siblings, onChain.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify inclusion proof: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"logID":   id,
+		"batchID": batch.ID,
+		"isValid": proof.IsValid,
+	}).Info("Inclusion proof generated")
+
+	return proof, nil
+}
+
+// buildBatchTree rebuilds the Merkle tree of a batch from the hashes of all
+// its logs, including soft-deleted ones
+func (s *VerificationService) buildBatchTree(batch *models.AnchorBatch) (*merkle.Tree, error) {
+	var leaves []string
+	if err := s.db.Unscoped().Model(&models.Log{}).
+		Where("batch_id = ?", batch.ID).
+		Order("leaf_index").
+		Pluck("hash", &leaves).Error; err != nil {
+		return nil, fmt.Errorf("failed to get batch leaves: %w", err)
+	}
+	if len(leaves) != batch.LeafCount {
+		return nil, fmt.Errorf("batch %s has %d leaves in the database, expected %d", batch.ID, len(leaves), batch.LeafCount)
+	}
+
+	tree, err := merkle.New(leaves)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build merkle tree: %w", err)
+	}
+	return tree, nil
 }
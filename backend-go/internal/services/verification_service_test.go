@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/fabric"
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeFabric is an in-memory ledger. When err is set every lookup fails with it.
+type fakeFabric struct {
+	logs    map[string]*fabric.LogHash
+	batches map[string]*fabric.BatchRoot
+	err     error
+}
+
+func newFakeFabric() *fakeFabric {
+	return &fakeFabric{logs: map[string]*fabric.LogHash{}, batches: map[string]*fabric.BatchRoot{}}
+}
+
+// anchor records a log on the ledger as CreateLog would have
+func (f *fakeFabric) anchor(log *models.Log) *fabric.LogHash {
+	entry := &fabric.LogHash{LogID: log.ID.String(), Hash: log.Hash, TxID: "tx-" + log.ID.String(), Metadata: commitMetadata(log)}
+	f.logs[entry.LogID] = entry
+	return entry
+}
+
+func (f *fakeFabric) CommitLogHash(logID, hash string, metadata map[string]string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.logs[logID] = &fabric.LogHash{LogID: logID, Hash: hash, TxID: "tx-" + logID, Metadata: metadata}
+	return "tx-" + logID, nil
+}
+
+func (f *fakeFabric) GetLogHash(logID string) (*fabric.LogHash, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	entry, ok := f.logs[logID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", fabric.ErrLogHashNotFound, logID)
+	}
+	return entry, nil
+}
+
+func (f *fakeFabric) VerifyLogHash(logID, providedHash string) (bool, error) {
+	entry, err := f.GetLogHash(logID)
+	if err != nil {
+		return false, err
+	}
+	return entry.Hash == providedHash, nil
+}
+
+func (f *fakeFabric) GetLogHashesByHash(hash string) ([]fabric.LogHash, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var entries []fabric.LogHash
+	for _, entry := range f.logs {
+		if entry.Hash == hash {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries, nil
+}
+
+func (f *fakeFabric) CommitBatchRoot(batchID, root string, leafCount int, metadata map[string]string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.batches[batchID] = &fabric.BatchRoot{BatchID: batchID, Root: root, LeafCount: leafCount, TxID: "tx-" + batchID, Metadata: metadata}
+	return "tx-" + batchID, nil
+}
+
+func (f *fakeFabric) GetBatchRoot(batchID string) (*fabric.BatchRoot, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	entry, ok := f.batches[batchID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", fabric.ErrBatchRootNotFound, batchID)
+	}
+	return entry, nil
+}
+
+func (f *fakeFabric) Close() {}
+
+func newTestVerificationService(ledger FabricClient) *VerificationService {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return NewVerificationService(nil, ledger, nil, log)
+}
+
+// newTestLog returns a hashed log under the given hash version, with the
+// content hash recorded for JCS and envelope logs
+func newTestLog(t *testing.T, version int) *models.Log {
+	t.Helper()
+	raw := `{"account": "ACC-1", "amount": 100.5, "currency": "EUR"}`
+	sequence := int64(7)
+	prevHash := fmt.Sprintf("%064x", 6)
+	log := &models.Log{
+		ID:          uuid.New(),
+		CreatedAt:   time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC),
+		Source:      "core-banking",
+		EventType:   "transfer.completed",
+		Sequence:    &sequence,
+		PrevHash:    &prevHash,
+		Payload:     raw,
+		RawPayload:  raw,
+		HashVersion: version,
+	}
+	hash, err := recomputeLogHash(log)
+	if err != nil {
+		t.Fatalf("recomputeLogHash: %v", err)
+	}
+	log.Hash = hash
+	if version >= models.HashVersionJCS {
+		if log.ContentHash, err = contentHash([]byte(raw)); err != nil {
+			t.Fatalf("contentHash: %v", err)
+		}
+	}
+	return log
+}
+
+// verifyTestLog runs the single-anchor checks of a log
+func verifyTestLog(s *VerificationService, log *models.Log) *models.VerificationResponse {
+	verification := &models.VerificationResponse{ID: log.ID, HashOffChain: log.Hash}
+	recomputed, err := recomputeLogHash(log)
+	if err != nil {
+		verification.Details = err.Error()
+	}
+	verification.HashRecomputed = recomputed
+	s.verifySingle(log, verification)
+	return verification
+}
+
+func TestVerifyBackfilledContentHashIsNotExpectedOnChain(t *testing.T) {
+	ledger := newFakeFabric()
+	service := newTestVerificationService(ledger)
+
+	// Anchored before content hashes were recorded, then backfilled
+	log := newTestLog(t, models.HashVersionEnvelope)
+	backfilled := log.ContentHash
+	log.ContentHash = ""
+	ledger.anchor(log)
+	log.ContentHash = backfilled
+
+	verification := verifyTestLog(service, log)
+	if verification.Status != models.VerificationStatusValid {
+		t.Fatalf("Status = %s (%+v), want valid", verification.Status, verification.MetadataMismatches)
+	}
+
+	// A content hash that was anchored is still compared
+	anchored := ledger.anchor(log)
+	anchored.Metadata["content_hash"] = fmt.Sprintf("%064x", 1)
+	verification = verifyTestLog(service, log)
+	if verification.Status != models.VerificationStatusMetadataMismatch {
+		t.Errorf("Status = %s, want metadata_mismatch when the anchored content hash differs", verification.Status)
+	}
+}
+
+func TestVerifySingleStatuses(t *testing.T) {
+	tampered := `{"account": "ACC-1", "amount": 1000.5, "currency": "EUR"}`
+	otherHash := fmt.Sprintf("%064x", 99)
+
+	tests := []struct {
+		name   string
+		anchor bool
+		edit   func(log *models.Log, ledger *fakeFabric)
+		want   string
+	}{
+		{"valid", true, func(*models.Log, *fakeFabric) {}, models.VerificationStatusValid},
+		{"payload tampered", true, func(log *models.Log, _ *fakeFabric) {
+			log.Payload, log.RawPayload = tampered, tampered
+		}, models.VerificationStatusPayloadTampered},
+		{"hash column tampered", true, func(log *models.Log, _ *fakeFabric) {
+			log.Hash = otherHash
+		}, models.VerificationStatusHashColumnTampered},
+		{"hash mismatch", true, func(log *models.Log, _ *fakeFabric) {
+			log.Payload, log.RawPayload = tampered, tampered
+			log.Hash = otherHash
+		}, models.VerificationStatusHashMismatch},
+		{"metadata mismatch", true, func(log *models.Log, _ *fakeFabric) {
+			log.Source = "cards"
+		}, models.VerificationStatusMetadataMismatch},
+		{"not anchored", false, func(*models.Log, *fakeFabric) {}, models.VerificationStatusNotAnchored},
+		{"ledger unreachable", true, func(_ *models.Log, ledger *fakeFabric) {
+			ledger.err = errors.New("connection refused")
+		}, models.VerificationStatusLedgerUnreachable},
+	}
+
+	for _, version := range []int{models.HashVersionLegacy, models.HashVersionJCS, models.HashVersionEnvelope} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("v%d/%s", version, tt.name), func(t *testing.T) {
+				ledger := newFakeFabric()
+				service := newTestVerificationService(ledger)
+				log := newTestLog(t, version)
+				if tt.anchor {
+					ledger.anchor(log)
+				}
+				tt.edit(log, ledger)
+
+				verification := verifyTestLog(service, log)
+				if verification.Status != tt.want {
+					t.Errorf("Status = %s (%s), want %s", verification.Status, verification.Details, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestVerifySingleMetadataMismatchNamesField(t *testing.T) {
+	ledger := newFakeFabric()
+	service := newTestVerificationService(ledger)
+	log := newTestLog(t, models.HashVersionEnvelope)
+	ledger.anchor(log)
+	log.EventType = "transfer.failed"
+
+	verification := verifyTestLog(service, log)
+	if verification.Status != models.VerificationStatusMetadataMismatch {
+		t.Fatalf("Status = %s, want metadata_mismatch", verification.Status)
+	}
+	want := []models.FieldMismatch{{Field: "event_type", OffChain: "transfer.failed", OnChain: "transfer.completed"}}
+	if !reflect.DeepEqual(verification.MetadataMismatches, want) {
+		t.Errorf("MetadataMismatches = %+v, want %+v", verification.MetadataMismatches, want)
+	}
+}
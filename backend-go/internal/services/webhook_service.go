@@ -0,0 +1,306 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Headers a webhook is signed with unless it is registered with others
+const (
+	DefaultWebhookSignatureHeader = "X-Webhook-Signature"
+	DefaultWebhookTimestampHeader = "X-Webhook-Timestamp"
+)
+
+var (
+	// ErrWebhookNotFound is returned for a delivery to an unregistered webhook
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrInvalidWebhook is returned when a webhook registration is invalid
+	ErrInvalidWebhook = errors.New("invalid webhook")
+	// ErrInvalidSignature is returned when a delivery's signature is missing or wrong
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrStaleDelivery is returned when a delivery's timestamp is outside the replay window
+	ErrStaleDelivery = errors.New("webhook delivery outside replay window")
+	// ErrDeliveryTooLarge is returned when a delivery body exceeds the configured size
+	ErrDeliveryTooLarge = errors.New("webhook delivery too large")
+)
+
+// webhookLogIDNamespace derives log IDs from delivery signatures, so a
+// delivery replayed within the window returns the log it created
+var webhookLogIDNamespace = uuid.MustParse("c7a4e2d9-3b61-4f0a-9d85-6e1b2f7c4a90")
+
+// webhookNamePattern restricts webhook names to what is safe in a URL path
+var webhookNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,100}$`)
+
+// webhookPlaceholder matches a {path} placeholder of a mapping template
+var webhookPlaceholder = regexp.MustCompile(`\{[^{}]*\}`)
+
+// WebhookService registers named inbound webhooks and turns their signed
+// deliveries into logs
+type WebhookService struct {
+	db         *gorm.DB
+	logService *LogService
+	cfg        config.WebhookConfig
+	logger     *logrus.Logger
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(db *gorm.DB, logService *LogService, cfg config.WebhookConfig, logger *logrus.Logger) *WebhookService {
+	return &WebhookService{
+		db:         db,
+		logService: logService,
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+// RegisterWebhook creates a webhook, or replaces the one with the same name
+func (s *WebhookService) RegisterWebhook(req *models.RegisterWebhookRequest) (*models.Webhook, error) {
+	webhook := &models.Webhook{
+		Name:                req.Name,
+		Secret:              req.Secret,
+		SignatureHeader:     req.SignatureHeader,
+		TimestampHeader:     req.TimestampHeader,
+		ReplayWindowSeconds: req.ReplayWindowSeconds,
+		SourceTemplate:      req.SourceTemplate,
+		AllowedSources:      req.AllowedSources,
+		EventTypeTemplate:   req.EventTypeTemplate,
+		PayloadPath:         req.PayloadPath,
+		PayloadFields:       req.PayloadFields,
+	}
+	if webhook.SignatureHeader == "" {
+		webhook.SignatureHeader = DefaultWebhookSignatureHeader
+	}
+	if webhook.TimestampHeader == "" {
+		webhook.TimestampHeader = DefaultWebhookTimestampHeader
+	}
+	if webhook.ReplayWindowSeconds == 0 {
+		webhook.ReplayWindowSeconds = int(s.cfg.ReplayWindow.Seconds())
+	}
+
+	if !webhookNamePattern.MatchString(webhook.Name) {
+		return nil, fmt.Errorf("%w: name must be 1 to 100 letters, digits, '-' or '_'", ErrInvalidWebhook)
+	}
+	if len(webhook.Secret) < 16 {
+		return nil, fmt.Errorf("%w: secret must be at least 16 characters", ErrInvalidWebhook)
+	}
+	if webhook.ReplayWindowSeconds < 0 {
+		return nil, fmt.Errorf("%w: replay window must not be negative", ErrInvalidWebhook)
+	}
+	// A secret must not let its holder write under every source
+	if len(webhook.AllowedSources) == 0 && webhookPlaceholder.MatchString(webhook.SourceTemplate) {
+		return nil, fmt.Errorf("%w: a source template with placeholders needs allowed_sources", ErrInvalidWebhook)
+	}
+	if webhook.PayloadPath != "" && len(webhook.PayloadFields) > 0 {
+		return nil, fmt.Errorf("%w: payload_path and payload_fields are mutually exclusive", ErrInvalidWebhook)
+	}
+
+	if err := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(webhook).Error; err != nil {
+		return nil, fmt.Errorf("failed to save webhook: %w", err)
+	}
+
+	s.logger.WithField("webhook", webhook.Name).Info("Webhook registered")
+	return webhook, nil
+}
+
+// ListWebhooks returns every registered webhook
+func (s *WebhookService) ListWebhooks() ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	if err := s.db.Order("name").Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook removes a webhook
+func (s *WebhookService) DeleteWebhook(name string) error {
+	result := s.db.Where("name = ?", name).Delete(&models.Webhook{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete webhook: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// Receive verifies a delivery to the named webhook and creates a log from it.
+// The signature is the hex HMAC-SHA256, optionally prefixed with "sha256=",
+// of the timestamp header, a dot and the raw body; the timestamp is in Unix
+// seconds and must be within the webhook's replay window.
+func (s *WebhookService) Receive(name string, header http.Header, r io.Reader) (*models.LogResponse, error) {
+	body, err := io.ReadAll(io.LimitReader(r, s.cfg.MaxBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook delivery: %w", err)
+	}
+	if int64(len(body)) > s.cfg.MaxBodyBytes {
+		return nil, fmt.Errorf("%w: at most %d bytes allowed", ErrDeliveryTooLarge, s.cfg.MaxBodyBytes)
+	}
+
+	var webhook models.Webhook
+	if err := s.db.Where("name = ?", name).First(&webhook).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	timestamp := header.Get(webhook.TimestampHeader)
+	signature, err := hex.DecodeString(strings.TrimPrefix(header.Get(webhook.SignatureHeader), "sha256="))
+	if err != nil || len(signature) == 0 {
+		return nil, fmt.Errorf("%w: missing or malformed %s header", ErrInvalidSignature, webhook.SignatureHeader)
+	}
+
+	mac := hmac.New(sha256.New, []byte(webhook.Secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrInvalidSignature
+	}
+
+	// The timestamp is checked after the signature so it cannot be forged
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed %s header", ErrStaleDelivery, webhook.TimestampHeader)
+	}
+	if skew := time.Since(time.Unix(sentAt, 0)); math.Abs(skew.Seconds()) > float64(webhook.ReplayWindowSeconds) {
+		return nil, fmt.Errorf("%w: sent %s ago", ErrStaleDelivery, skew.Round(time.Second))
+	}
+
+	req, err := mapWebhookDelivery(&webhook, body)
+	if err != nil {
+		return nil, err
+	}
+	req.LogID = uuid.NewSHA1(webhookLogIDNamespace, []byte(webhook.Name+"\x00"+hex.EncodeToString(signature))).String()
+
+	return s.logService.CreateLog(req)
+}
+
+// mapWebhookDelivery applies a webhook's mapping to a delivery body
+func mapWebhookDelivery(webhook *models.Webhook, body []byte) (*models.CreateLogRequest, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("%w: body is not JSON: %v", ErrInvalidPayload, err)
+	}
+
+	source := renderWebhookTemplate(webhook.SourceTemplate, document)
+	eventType := renderWebhookTemplate(webhook.EventTypeTemplate, document)
+	if source == "" || eventType == "" {
+		return nil, fmt.Errorf("%w: mapping produced an empty source or event type", ErrInvalidPayload)
+	}
+
+	var payload json.RawMessage
+	switch {
+	case len(webhook.PayloadFields) > 0:
+		fields := map[string]interface{}{}
+		for _, path := range webhook.PayloadFields {
+			if value, ok := lookupJSONPath(document, path); ok {
+				fields[path] = value
+			}
+		}
+		marshalled, err := json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		payload = marshalled
+	case webhook.PayloadPath != "":
+		value, ok := lookupJSONPath(document, webhook.PayloadPath)
+		if !ok {
+			return nil, fmt.Errorf("%w: payload path %q not found", ErrInvalidPayload, webhook.PayloadPath)
+		}
+		marshalled, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		payload = marshalled
+	default:
+		// Keep the delivered bytes so the payload hashes as the producer sent it
+		payload = body
+	}
+
+	return &models.CreateLogRequest{
+		Source:         source,
+		EventType:      eventType,
+		Payload:        payload,
+		AllowedSources: webhookAllowedSources(webhook),
+	}, nil
+}
+
+// webhookAllowedSources returns the sources a webhook may write under: its
+// allowed sources, or the fixed source of a template without placeholders.
+// A webhook with neither may write under none.
+func webhookAllowedSources(webhook *models.Webhook) []string {
+	if len(webhook.AllowedSources) > 0 {
+		return webhook.AllowedSources
+	}
+	if !webhookPlaceholder.MatchString(webhook.SourceTemplate) {
+		return []string{strings.TrimSpace(webhook.SourceTemplate)}
+	}
+	return []string{}
+}
+
+// renderWebhookTemplate replaces each {path} placeholder with the value at
+// that path of the document. Missing values render as nothing.
+func renderWebhookTemplate(template string, document interface{}) string {
+	return strings.TrimSpace(webhookPlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		value, ok := lookupJSONPath(document, placeholder[1:len(placeholder)-1])
+		if !ok || value == nil {
+			return ""
+		}
+		switch v := value.(type) {
+		case string:
+			return v
+		case json.Number, bool:
+			return fmt.Sprint(v)
+		default:
+			marshalled, _ := json.Marshal(v)
+			return string(marshalled)
+		}
+	}))
+}
+
+// lookupJSONPath returns the value at a dot-separated path, where numeric
+// segments index arrays
+func lookupJSONPath(document interface{}, path string) (interface{}, bool) {
+	value := document
+	for _, segment := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
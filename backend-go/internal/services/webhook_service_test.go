@@ -0,0 +1,281 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/banking-audit-ledger/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestWebhookService() *WebhookService {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return NewWebhookService(nil, nil, config.WebhookConfig{ReplayWindow: 5 * time.Minute, MaxBodyBytes: 1 << 20}, log)
+}
+
+func TestRegisterWebhookRequiresSourceRestriction(t *testing.T) {
+	service := newTestWebhookService()
+	_, err := service.RegisterWebhook(&models.RegisterWebhookRequest{
+		Name:              "stripe",
+		Secret:            "0123456789abcdef",
+		SourceTemplate:    "{data.object.account}",
+		EventTypeTemplate: "{type}",
+	})
+	if !errors.Is(err, ErrInvalidWebhook) {
+		t.Fatalf("RegisterWebhook = %v, want ErrInvalidWebhook for a templated source without allowed_sources", err)
+	}
+}
+
+func TestMapWebhookDeliveryAllowedSources(t *testing.T) {
+	body := []byte(`{"type": "charge.succeeded", "data": {"object": {"account": "cards"}}}`)
+	tests := []struct {
+		name    string
+		webhook models.Webhook
+		want    []string
+	}{
+		{
+			name:    "listed sources",
+			webhook: models.Webhook{SourceTemplate: "{data.object.account}", AllowedSources: []string{"cards", "deposits"}},
+			want:    []string{"cards", "deposits"},
+		},
+		{
+			name:    "fixed source",
+			webhook: models.Webhook{SourceTemplate: "payments"},
+			want:    []string{"payments"},
+		},
+		{
+			// Registered before sources were restricted: nothing is allowed
+			name:    "templated source without a list",
+			webhook: models.Webhook{SourceTemplate: "{data.object.account}"},
+			want:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.webhook.EventTypeTemplate = "{type}"
+			req, err := mapWebhookDelivery(&tt.webhook, body)
+			if err != nil {
+				t.Fatalf("mapWebhookDelivery: %v", err)
+			}
+			if req.AllowedSources == nil || !reflect.DeepEqual(req.AllowedSources, tt.want) {
+				t.Errorf("AllowedSources = %#v, want %#v", req.AllowedSources, tt.want)
+			}
+		})
+	}
+}
+
+const testWebhookSecret = "0123456789abcdef"
+
+// errDeliveryAccepted stops a verified delivery at the lookup of its log
+var errDeliveryAccepted = errors.New("delivery accepted")
+
+// captureArg matches any query argument and records it
+type captureArg struct {
+	value *driver.Value
+}
+
+func (a captureArg) Match(v driver.Value) bool {
+	*a.value = v
+	return true
+}
+
+// newTestWebhookReceiver returns a webhook service whose database is mocked
+func newTestWebhookReceiver(t *testing.T) (*WebhookService, sqlmock.Sqlmock) {
+	t.Helper()
+	logService, mock := newTestLogService(t)
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return NewWebhookService(logService.db, logService, config.WebhookConfig{ReplayWindow: 5 * time.Minute, MaxBodyBytes: 1 << 10}, log), mock
+}
+
+func expectWebhook(mock sqlmock.Sqlmock, name string) {
+	mock.ExpectQuery(`SELECT \* FROM "webhooks" WHERE name = \$1`).
+		WithArgs(name).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "secret", "signature_header", "timestamp_header", "replay_window_seconds", "source_template", "event_type_template"}).
+			AddRow(name, testWebhookSecret, DefaultWebhookSignatureHeader, DefaultWebhookTimestampHeader, 300, "payments", "{type}"))
+}
+
+// expectLogLookup expects a verified delivery to look up the log it maps to,
+// recording the log ID into id
+func expectLogLookup(mock sqlmock.Sqlmock, id *driver.Value) {
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "signing_keys"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT \* FROM "logs" WHERE id = \$1`).
+		WithArgs(captureArg{id}).
+		WillReturnError(errDeliveryAccepted)
+}
+
+func signDelivery(secret, timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func deliveryHeader(timestamp, signature string) http.Header {
+	header := http.Header{}
+	header.Set(DefaultWebhookTimestampHeader, timestamp)
+	header.Set(DefaultWebhookSignatureHeader, signature)
+	return header
+}
+
+func TestReceiveVerifiesSignature(t *testing.T) {
+	body := `{"type": "charge.succeeded"}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := signDelivery(testWebhookSecret, now, body)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		body      string
+		valid     bool
+	}{
+		{"hex signature", now, signature, body, true},
+		{"prefixed signature", now, "sha256=" + signature, body, true},
+		{"uppercase signature", now, strings.ToUpper(signature), body, true},
+		{"wrong secret", now, signDelivery("fedcba9876543210", now, body), body, false},
+		{"tampered body", now, signature, `{"type": "charge.refunded"}`, false},
+		{"tampered timestamp", strconv.FormatInt(time.Now().Unix()-1, 10), signature, body, false},
+		{"missing signature", now, "", body, false},
+		{"malformed signature", now, "sha256=not-hex", body, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newTestWebhookReceiver(t)
+			expectWebhook(mock, "stripe")
+			var id driver.Value
+			if tt.valid {
+				expectLogLookup(mock, &id)
+			}
+
+			_, err := service.Receive("stripe", deliveryHeader(tt.timestamp, tt.signature), strings.NewReader(tt.body))
+			if tt.valid && !errors.Is(err, errDeliveryAccepted) {
+				t.Errorf("Receive = %v, want the delivery accepted", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Receive = %v, want ErrInvalidSignature", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestReceiveRejectsDeliveriesOutsideReplayWindow(t *testing.T) {
+	body := `{"type": "charge.succeeded"}`
+	sentAt := func(offset time.Duration) string {
+		return strconv.FormatInt(time.Now().Add(offset).Unix(), 10)
+	}
+
+	tests := []struct {
+		name      string
+		timestamp string
+		valid     bool
+	}{
+		{"within the window", sentAt(-4 * time.Minute), true},
+		{"slightly in the future", sentAt(4 * time.Minute), true},
+		{"replayed after the window", sentAt(-6 * time.Minute), false},
+		{"too far in the future", sentAt(6 * time.Minute), false},
+		{"malformed timestamp", "2025-03-04T05:06:07Z", false},
+		{"missing timestamp", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newTestWebhookReceiver(t)
+			expectWebhook(mock, "stripe")
+			var id driver.Value
+			if tt.valid {
+				expectLogLookup(mock, &id)
+			}
+
+			header := deliveryHeader(tt.timestamp, signDelivery(testWebhookSecret, tt.timestamp, body))
+			_, err := service.Receive("stripe", header, strings.NewReader(body))
+			if tt.valid && !errors.Is(err, errDeliveryAccepted) {
+				t.Errorf("Receive = %v, want the delivery accepted", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrStaleDelivery) {
+				t.Errorf("Receive = %v, want ErrStaleDelivery", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestReceiveDerivesLogIDFromDelivery(t *testing.T) {
+	service, mock := newTestWebhookReceiver(t)
+	body := `{"type": "charge.succeeded"}`
+	now := time.Now().Unix()
+
+	deliver := func(name string, sentAt int64) string {
+		t.Helper()
+		timestamp := strconv.FormatInt(sentAt, 10)
+		var id driver.Value
+		expectWebhook(mock, name)
+		expectLogLookup(mock, &id)
+		_, err := service.Receive(name, deliveryHeader(timestamp, signDelivery(testWebhookSecret, timestamp, body)), strings.NewReader(body))
+		if !errors.Is(err, errDeliveryAccepted) {
+			t.Fatalf("Receive = %v, want the delivery accepted", err)
+		}
+		logID, ok := id.(string)
+		if !ok {
+			t.Fatalf("log ID = %#v, want a string", id)
+		}
+		if parsed, err := uuid.Parse(logID); err != nil || parsed.Version() != 5 {
+			t.Fatalf("log ID = %s, want a UUIDv5", logID)
+		}
+		return logID
+	}
+
+	first := deliver("stripe", now)
+	// A retried delivery maps to the log the first one created
+	if retried := deliver("stripe", now); retried != first {
+		t.Errorf("retried delivery maps to %s, want %s", retried, first)
+	}
+	// A new delivery of the same body is a new log
+	if next := deliver("stripe", now+1); next == first {
+		t.Error("a later delivery maps to the same log")
+	}
+	// The same signature delivered to another webhook is a new log
+	if other := deliver("github", now); other == first {
+		t.Error("a delivery to another webhook maps to the same log")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReceiveRejectsUnknownWebhooksAndLargeBodies(t *testing.T) {
+	service, mock := newTestWebhookReceiver(t)
+	mock.ExpectQuery(`SELECT \* FROM "webhooks" WHERE name = \$1`).
+		WithArgs("unknown").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	if _, err := service.Receive("unknown", http.Header{}, strings.NewReader(`{}`)); !errors.Is(err, ErrWebhookNotFound) {
+		t.Errorf("Receive = %v, want ErrWebhookNotFound", err)
+	}
+
+	// The body is refused before the webhook is looked up
+	body := `{"data": "` + strings.Repeat("x", 1<<10) + `"}`
+	if _, err := service.Receive("stripe", http.Header{}, strings.NewReader(body)); !errors.Is(err, ErrDeliveryTooLarge) {
+		t.Errorf("Receive = %v, want ErrDeliveryTooLarge", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
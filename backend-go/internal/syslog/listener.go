@@ -0,0 +1,347 @@
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/auth"
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/banking-audit-ledger/backend/internal/services"
+	"github.com/sirupsen/logrus"
+)
+
+// handshakeTimeout bounds the TLS handshake of an authenticated connection
+const handshakeTimeout = 10 * time.Second
+
+// Listener receives syslog messages over UDP, TCP and TLS and creates a log
+// for each of them through the log service. With an authenticator only TLS
+// is served, and each connection writes under the sources of the principal
+// its client certificate maps to.
+type Listener struct {
+	cfg           config.SyslogConfig
+	mapping       Mapping
+	logService    *services.LogService
+	authenticator auth.Authenticator
+	logger        *logrus.Logger
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// NewListener creates a new syslog listener. Connections are authenticated
+// when authenticator is not nil.
+func NewListener(cfg config.SyslogConfig, logService *services.LogService, authenticator auth.Authenticator, logger *logrus.Logger) *Listener {
+	return &Listener{
+		cfg: cfg,
+		mapping: Mapping{
+			SourceTemplate:    cfg.SourceTemplate,
+			EventTypeTemplate: cfg.EventTypeTemplate,
+			DefaultSource:     cfg.DefaultSource,
+			DefaultEventType:  cfg.DefaultEventType,
+		},
+		logService:    logService,
+		authenticator: authenticator,
+		logger:        logger,
+		conns:         map[net.Conn]struct{}{},
+	}
+}
+
+// Start binds every configured address and serves them until ctx is
+// cancelled. Binding errors are returned so misconfiguration fails startup.
+func (l *Listener) Start(ctx context.Context) error {
+	// Plaintext senders cannot be identified, so they could write under any
+	// source their APP-NAME names
+	if l.authenticator != nil {
+		if l.cfg.UDPAddr != "" || l.cfg.TCPAddr != "" {
+			return errors.New("syslog over UDP and TCP cannot be authenticated; unset SYSLOG_UDP_ADDR and SYSLOG_TCP_ADDR when AUTH_ENABLED is set")
+		}
+		if l.cfg.TLSAddr != "" && l.cfg.TLSClientCAFile == "" {
+			return errors.New("syslog over TLS needs SYSLOG_TLS_CLIENT_CA_FILE when AUTH_ENABLED is set")
+		}
+	}
+
+	var closers []io.Closer
+	fail := func(err error) error {
+		for _, closer := range closers {
+			closer.Close()
+		}
+		return err
+	}
+
+	if l.cfg.UDPAddr != "" {
+		conn, err := net.ListenPacket("udp", l.cfg.UDPAddr)
+		if err != nil {
+			return fail(fmt.Errorf("failed to listen for syslog on udp %s: %w", l.cfg.UDPAddr, err))
+		}
+		closers = append(closers, conn)
+		go l.serveUDP(conn)
+	}
+
+	if l.cfg.TCPAddr != "" {
+		listener, err := net.Listen("tcp", l.cfg.TCPAddr)
+		if err != nil {
+			return fail(fmt.Errorf("failed to listen for syslog on tcp %s: %w", l.cfg.TCPAddr, err))
+		}
+		closers = append(closers, listener)
+		go l.serveStream(listener, "tcp")
+	}
+
+	if l.cfg.TLSAddr != "" {
+		tlsConfig, err := l.tlsConfig()
+		if err != nil {
+			return fail(err)
+		}
+		listener, err := tls.Listen("tcp", l.cfg.TLSAddr, tlsConfig)
+		if err != nil {
+			return fail(fmt.Errorf("failed to listen for syslog on tls %s: %w", l.cfg.TLSAddr, err))
+		}
+		closers = append(closers, listener)
+		go l.serveStream(listener, "tls")
+	}
+
+	l.logger.WithFields(logrus.Fields{
+		"component": "syslog",
+		"udp":       l.cfg.UDPAddr,
+		"tcp":       l.cfg.TCPAddr,
+		"tls":       l.cfg.TLSAddr,
+	}).Info("Syslog listener started")
+
+	go func() {
+		<-ctx.Done()
+		for _, closer := range closers {
+			closer.Close()
+		}
+		l.mu.Lock()
+		for conn := range l.conns {
+			conn.Close()
+		}
+		l.mu.Unlock()
+		l.logger.WithField("component", "syslog").Info("Syslog listener stopped")
+	}()
+
+	return nil
+}
+
+// tlsConfig loads the server certificate and, when configured, the CA that
+// client certificates must chain to
+func (l *Listener) tlsConfig() (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(l.cfg.TLSCertFile, l.cfg.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load syslog TLS certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if l.cfg.TLSClientCAFile != "" {
+		caPEM, err := os.ReadFile(l.cfg.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read syslog client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in syslog client CA %s", l.cfg.TLSClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// serveUDP handles one message per datagram
+func (l *Listener) serveUDP(conn net.PacketConn) {
+	buf := make([]byte, l.cfg.MaxMessageBytes)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			l.logger.WithError(err).WithField("component", "syslog").Error("Failed to read syslog datagram")
+			continue
+		}
+		l.handle(buf[:n], "udp", addr, nil)
+	}
+}
+
+// serveStream accepts TCP or TLS connections and serves each one
+func (l *Listener) serveStream(listener net.Listener, transport string) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			l.logger.WithError(err).WithField("component", "syslog").Error("Failed to accept syslog connection")
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		go l.serveConn(conn, transport)
+	}
+}
+
+// serveConn reads framed messages from a connection until it is closed.
+// Messages are handled one at a time, so a slow store slows the sender down.
+func (l *Listener) serveConn(conn net.Conn, transport string) {
+	l.mu.Lock()
+	l.conns[conn] = struct{}{}
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		delete(l.conns, conn)
+		l.mu.Unlock()
+		conn.Close()
+	}()
+
+	var allowedSources []string
+	if l.authenticator != nil {
+		principal, err := l.authenticate(conn)
+		if err != nil {
+			l.logger.WithError(err).WithFields(logrus.Fields{
+				"component": "syslog",
+				"remote":    conn.RemoteAddr().String(),
+			}).Warn("Rejected syslog connection")
+			return
+		}
+		allowedSources = principal.AllowedSources()
+	}
+
+	reader := bufio.NewReaderSize(conn, 64*1024)
+	for {
+		frame, err := l.readFrame(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				l.logger.WithError(err).WithFields(logrus.Fields{
+					"component": "syslog",
+					"remote":    conn.RemoteAddr().String(),
+				}).Warn("Closing syslog connection")
+			}
+			return
+		}
+		if len(frame) > 0 {
+			l.handle(frame, transport, conn.RemoteAddr(), allowedSources)
+		}
+	}
+}
+
+// authenticate maps the verified client certificate of a TLS connection to
+// a principal that may create logs
+func (l *Listener) authenticate(conn net.Conn) (*auth.Principal, error) {
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return nil, fmt.Errorf("%w: connection is not TLS", auth.ErrNoCredentials)
+	}
+	tlsConn.SetDeadline(time.Now().Add(handshakeTimeout))
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, fmt.Errorf("TLS handshake failed: %w", err)
+	}
+	tlsConn.SetDeadline(time.Time{})
+
+	var creds auth.Credentials
+	if chains := tlsConn.ConnectionState().VerifiedChains; len(chains) > 0 && len(chains[0]) > 0 {
+		creds.ClientCertificate = chains[0][0]
+	}
+	principal, err := l.authenticator.Authenticate(creds)
+	if err != nil {
+		return nil, err
+	}
+	if !principal.HasRole(auth.RoleIngester) {
+		return nil, fmt.Errorf("%w: %s may not create logs", auth.ErrInvalidCredentials, principal.Subject)
+	}
+	return principal, nil
+}
+
+// readFrame reads one message using RFC 6587 octet counting when the frame
+// starts with a digit and newline-delimited framing otherwise
+func (l *Listener) readFrame(reader *bufio.Reader) ([]byte, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if first[0] >= '0' && first[0] <= '9' {
+		// The octet count cannot have more digits than the largest allowed length
+		maxDigits := len(strconv.Itoa(l.cfg.MaxMessageBytes))
+		var prefix []byte
+		for {
+			b, err := reader.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			if b == ' ' {
+				break
+			}
+			prefix = append(prefix, b)
+			if b < '0' || b > '9' || len(prefix) > maxDigits {
+				return nil, fmt.Errorf("invalid syslog frame length %q", prefix)
+			}
+		}
+		length, err := strconv.Atoi(string(prefix))
+		if err != nil || length < 1 || length > l.cfg.MaxMessageBytes {
+			return nil, fmt.Errorf("invalid syslog frame length %q", prefix)
+		}
+		frame := make([]byte, length)
+		if _, err := io.ReadFull(reader, frame); err != nil {
+			return nil, err
+		}
+		return frame, nil
+	}
+
+	var frame []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		frame = append(frame, chunk...)
+		if len(frame) > l.cfg.MaxMessageBytes {
+			return nil, fmt.Errorf("syslog message exceeds %d bytes", l.cfg.MaxMessageBytes)
+		}
+		if err == nil {
+			return frame, nil
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			if errors.Is(err, io.EOF) && len(frame) > 0 {
+				return frame, nil
+			}
+			return nil, err
+		}
+	}
+}
+
+// handle parses a message and creates a log for it under one of
+// allowedSources, or any source when it is nil
+func (l *Listener) handle(data []byte, transport string, remote net.Addr, allowedSources []string) {
+	logger := l.logger.WithFields(logrus.Fields{
+		"component": "syslog",
+		"transport": transport,
+		"remote":    remote.String(),
+	})
+
+	msg, err := Parse(data, time.Now())
+	if err != nil {
+		logger.WithError(err).Warn("Dropping unparseable syslog message")
+		return
+	}
+
+	req, err := l.mapping.Request(msg)
+	if err != nil {
+		logger.WithError(err).Error("Failed to map syslog message")
+		return
+	}
+	req.AllowedSources = allowedSources
+
+	if _, err := l.logService.CreateLog(req); err != nil {
+		logger.WithError(err).Error("Failed to create log from syslog message")
+	}
+}
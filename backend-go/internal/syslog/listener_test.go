@@ -0,0 +1,84 @@
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/banking-audit-ledger/backend/internal/auth"
+	"github.com/banking-audit-ledger/backend/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+func TestStartRefusesUnauthenticatedTransportsWithAuth(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	authenticator := auth.Chain{}
+
+	tests := []struct {
+		name string
+		cfg  config.SyslogConfig
+		want string
+	}{
+		{"udp", config.SyslogConfig{UDPAddr: "127.0.0.1:0"}, "SYSLOG_UDP_ADDR"},
+		{"tcp", config.SyslogConfig{TCPAddr: "127.0.0.1:0"}, "SYSLOG_TCP_ADDR"},
+		{"tls without client CA", config.SyslogConfig{TLSAddr: "127.0.0.1:0"}, "SYSLOG_TLS_CLIENT_CA_FILE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewListener(tt.cfg, nil, authenticator, logger).Start(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Start = %v, want an error naming %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFrame(t *testing.T) {
+	listener := &Listener{cfg: config.SyslogConfig{MaxMessageBytes: 64}}
+
+	tests := []struct {
+		name    string
+		stream  string
+		want    []string
+		wantErr string
+	}{
+		{"octet counting", "5 <13>a6 <13>bc", []string{"<13>a", "<13>bc"}, ""},
+		{"octet counting keeps newlines", "8 <13>a\nb\n", []string{"<13>a\nb\n"}, ""},
+		{"non-transparent framing", "<13>a\n<13>b\n", []string{"<13>a\n", "<13>b\n"}, ""},
+		{"last message without newline", "<13>a\n<13>b", []string{"<13>a\n", "<13>b"}, ""},
+		{"length at the limit", "64 " + strings.Repeat("x", 64), []string{strings.Repeat("x", 64)}, ""},
+		{"length over the limit", "65 " + strings.Repeat("x", 65), nil, "invalid syslog frame length"},
+		{"prefix longer than the limit allows", "00064 x", nil, "invalid syslog frame length"},
+		{"prefix without a space", strings.Repeat("9", 1<<20), nil, "invalid syslog frame length"},
+		{"non-digit in prefix", "1x <13>a", nil, "invalid syslog frame length"},
+		{"zero length", "0 <13>a", nil, "invalid syslog frame length"},
+		{"truncated frame", "10 <13>a", nil, "unexpected EOF"},
+		{"line over the limit", strings.Repeat("x", 65) + "\n", nil, "exceeds 64 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := bufio.NewReaderSize(strings.NewReader(tt.stream), 16)
+			for i, want := range tt.want {
+				frame, err := listener.readFrame(reader)
+				if err != nil {
+					t.Fatalf("frame %d: %v", i, err)
+				}
+				if string(frame) != want {
+					t.Errorf("frame %d = %q, want %q", i, frame, want)
+				}
+			}
+
+			_, err := listener.readFrame(reader)
+			if tt.wantErr == "" {
+				if err != io.EOF {
+					t.Errorf("after the last frame err = %v, want EOF", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
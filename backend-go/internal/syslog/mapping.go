@@ -0,0 +1,80 @@
+package syslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/banking-audit-ledger/backend/internal/models"
+)
+
+// placeholderPattern matches the {field} placeholders of a mapping template
+var placeholderPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// Mapping turns parsed syslog messages into log create requests. Source and
+// event type are rendered from templates such as "{hostname}/{app_name}";
+// a template that renders empty falls back to its default.
+type Mapping struct {
+	SourceTemplate    string
+	EventTypeTemplate string
+	DefaultSource     string
+	DefaultEventType  string
+}
+
+// Request builds the create request for a message. The payload is the whole
+// parsed message, so nothing the sender provided is lost.
+func (m Mapping) Request(msg *Message) (*models.CreateLogRequest, error) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal syslog message: %w", err)
+	}
+
+	return &models.CreateLogRequest{
+		Source:    render(m.SourceTemplate, msg, m.DefaultSource),
+		EventType: render(m.EventTypeTemplate, msg, m.DefaultEventType),
+		Payload:   payload,
+	}, nil
+}
+
+// render fills the placeholders of a template from a message. Supported
+// fields are hostname, app_name, proc_id, msg_id, facility, severity, format
+// and sd.<SD-ID>.<PARAM-NAME> for structured data parameters.
+func render(template string, msg *Message, fallback string) string {
+	rendered := placeholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		return field(msg, placeholder[1:len(placeholder)-1])
+	})
+	if strings.Trim(rendered, " /.-_:") == "" {
+		return fallback
+	}
+	return rendered
+}
+
+// field returns the value of a named message field
+func field(msg *Message, name string) string {
+	switch name {
+	case "hostname":
+		return msg.Hostname
+	case "app_name":
+		return msg.AppName
+	case "proc_id":
+		return msg.ProcID
+	case "msg_id":
+		return msg.MsgID
+	case "facility":
+		return strconv.Itoa(msg.Facility)
+	case "severity":
+		return strconv.Itoa(msg.Severity)
+	case "format":
+		return msg.Format
+	}
+
+	if sd, ok := strings.CutPrefix(name, "sd."); ok {
+		// Split at the last dot, so SD-IDs may contain dots but parameter names may not
+		if dot := strings.LastIndexByte(sd, '.'); dot > 0 {
+			return msg.StructuredData[sd[:dot]][sd[dot+1:]]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package syslog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMappingRequest(t *testing.T) {
+	msg := &Message{
+		Format:   FormatRFC5424,
+		Facility: 20,
+		Severity: 5,
+		Hostname: "core01",
+		AppName:  "payments",
+		ProcID:   "4242",
+		MsgID:    "ID47",
+		StructuredData: map[string]map[string]string{
+			"meta@32473":    {"account": "ACC-1"},
+			"origin.x@1234": {"ip": "10.0.0.1"},
+		},
+		Message: "transfer completed",
+	}
+
+	tests := []struct {
+		name      string
+		mapping   Mapping
+		source    string
+		eventType string
+	}{
+		{
+			name:      "header fields",
+			mapping:   Mapping{SourceTemplate: "{hostname}/{app_name}", EventTypeTemplate: "syslog.{msg_id}"},
+			source:    "core01/payments",
+			eventType: "syslog.ID47",
+		},
+		{
+			name:      "numeric fields",
+			mapping:   Mapping{SourceTemplate: "{format}-{proc_id}", EventTypeTemplate: "{facility}.{severity}"},
+			source:    "rfc5424-4242",
+			eventType: "20.5",
+		},
+		{
+			name:      "structured data",
+			mapping:   Mapping{SourceTemplate: "{sd.meta@32473.account}", EventTypeTemplate: "{sd.origin.x@1234.ip}"},
+			source:    "ACC-1",
+			eventType: "10.0.0.1",
+		},
+		{
+			name: "empty render falls back",
+			mapping: Mapping{
+				SourceTemplate: "{sd.meta@32473.missing}/{unknown}", EventTypeTemplate: "{sd.nodot}",
+				DefaultSource: "syslog", DefaultEventType: "syslog.message",
+			},
+			source:    "syslog",
+			eventType: "syslog.message",
+		},
+		{
+			name:      "literal template",
+			mapping:   Mapping{SourceTemplate: "firewall", EventTypeTemplate: "", DefaultEventType: "syslog.message"},
+			source:    "firewall",
+			eventType: "syslog.message",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := tt.mapping.Request(msg)
+			if err != nil {
+				t.Fatalf("Request: %v", err)
+			}
+			if req.Source != tt.source {
+				t.Errorf("Source = %q, want %q", req.Source, tt.source)
+			}
+			if req.EventType != tt.eventType {
+				t.Errorf("EventType = %q, want %q", req.EventType, tt.eventType)
+			}
+		})
+	}
+}
+
+func TestMappingRequestPayloadIsTheParsedMessage(t *testing.T) {
+	msg, err := Parse([]byte(`<165>1 2025-03-04T05:06:07Z core01 payments - - [meta account="ACC-1"] done`), time.Now())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	req, err := Mapping{DefaultSource: "syslog", DefaultEventType: "syslog.message"}.Request(msg)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+
+	var payload Message
+	if err := json.Unmarshal(req.Payload, &payload); err != nil {
+		t.Fatalf("payload is not a message: %v", err)
+	}
+	if payload.Hostname != "core01" || payload.Message != "done" || payload.StructuredData["meta"]["account"] != "ACC-1" {
+		t.Errorf("payload = %+v, want the parsed message", payload)
+	}
+	if payload.Timestamp == nil || !payload.Timestamp.Equal(*msg.Timestamp) {
+		t.Errorf("payload timestamp = %v, want %v", payload.Timestamp, msg.Timestamp)
+	}
+}
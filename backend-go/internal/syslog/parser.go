@@ -0,0 +1,227 @@
+package syslog
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Syslog message formats
+const (
+	FormatRFC5424 = "rfc5424"
+	FormatRFC3164 = "rfc3164"
+)
+
+// nilValue marks an absent RFC 5424 header field
+const nilValue = "-"
+
+// Message is a parsed syslog message
+type Message struct {
+	Format         string                       `json:"format"`
+	Facility       int                          `json:"facility"`
+	Severity       int                          `json:"severity"`
+	Timestamp      *time.Time                   `json:"timestamp,omitempty"`
+	Hostname       string                       `json:"hostname,omitempty"`
+	AppName        string                       `json:"app_name,omitempty"`
+	ProcID         string                       `json:"proc_id,omitempty"`
+	MsgID          string                       `json:"msg_id,omitempty"`
+	StructuredData map[string]map[string]string `json:"structured_data,omitempty"`
+	Message        string                       `json:"message"`
+}
+
+// Parse parses an RFC 5424 or RFC 3164 syslog message. Messages with a
+// version number after the priority are parsed as RFC 5424, anything else
+// as RFC 3164. now is used to complete the year of RFC 3164 timestamps.
+func Parse(data []byte, now time.Time) (*Message, error) {
+	data = bytes.TrimRight(data, "\r\n\x00")
+	if !utf8.Valid(data) {
+		data = bytes.ToValidUTF8(data, []byte("\ufffd"))
+	}
+
+	priority, rest, err := parsePriority(string(data))
+	if err != nil {
+		return nil, err
+	}
+	msg := &Message{Facility: priority / 8, Severity: priority % 8}
+
+	if strings.HasPrefix(rest, "1 ") {
+		msg.Format = FormatRFC5424
+		if err := parse5424(msg, rest[2:]); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+
+	msg.Format = FormatRFC3164
+	parse3164(msg, rest, now)
+	return msg, nil
+}
+
+// parsePriority parses the <PRI> prefix of a message
+func parsePriority(data string) (int, string, error) {
+	if !strings.HasPrefix(data, "<") {
+		return 0, "", fmt.Errorf("missing priority")
+	}
+	end := strings.IndexByte(data, '>')
+	if end < 2 || end > 4 {
+		return 0, "", fmt.Errorf("invalid priority")
+	}
+	priority, err := strconv.Atoi(data[1:end])
+	if err != nil || priority < 0 || priority > 191 {
+		return 0, "", fmt.Errorf("invalid priority %q", data[1:end])
+	}
+	return priority, data[end+1:], nil
+}
+
+// parse5424 parses the part of an RFC 5424 message after the version
+func parse5424(msg *Message, rest string) error {
+	fields := make([]string, 5)
+	for i := range fields {
+		field, remainder, ok := strings.Cut(rest, " ")
+		if !ok {
+			return fmt.Errorf("truncated RFC 5424 header")
+		}
+		fields[i], rest = field, remainder
+	}
+
+	if fields[0] != nilValue {
+		timestamp, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %q", fields[0])
+		}
+		msg.Timestamp = &timestamp
+	}
+	msg.Hostname = headerValue(fields[1])
+	msg.AppName = headerValue(fields[2])
+	msg.ProcID = headerValue(fields[3])
+	msg.MsgID = headerValue(fields[4])
+
+	structuredData, rest, err := parseStructuredData(rest)
+	if err != nil {
+		return err
+	}
+	msg.StructuredData = structuredData
+
+	rest = strings.TrimPrefix(rest, " ")
+	msg.Message = strings.TrimPrefix(rest, "\ufeff")
+	return nil
+}
+
+// headerValue returns an RFC 5424 header field, empty when it is NILVALUE
+func headerValue(field string) string {
+	if field == nilValue {
+		return ""
+	}
+	return field
+}
+
+// parseStructuredData parses RFC 5424 STRUCTURED-DATA and returns the rest of the message
+func parseStructuredData(data string) (map[string]map[string]string, string, error) {
+	if strings.HasPrefix(data, nilValue) {
+		return nil, data[len(nilValue):], nil
+	}
+
+	elements := map[string]map[string]string{}
+	for strings.HasPrefix(data, "[") {
+		data = data[1:]
+
+		end := strings.IndexAny(data, " ]")
+		if end < 1 {
+			return nil, "", fmt.Errorf("invalid structured data element")
+		}
+		id := data[:end]
+		data = data[end:]
+		params := map[string]string{}
+
+		for strings.HasPrefix(data, " ") {
+			data = data[1:]
+			name, remainder, ok := strings.Cut(data, "=\"")
+			if !ok || name == "" {
+				return nil, "", fmt.Errorf("invalid structured data parameter in %q", id)
+			}
+			value, remainder, err := parseParamValue(remainder)
+			if err != nil {
+				return nil, "", fmt.Errorf("%w in %q", err, id)
+			}
+			params[name] = value
+			data = remainder
+		}
+
+		if !strings.HasPrefix(data, "]") {
+			return nil, "", fmt.Errorf("unterminated structured data element %q", id)
+		}
+		data = data[1:]
+		elements[id] = params
+	}
+
+	if len(elements) == 0 {
+		return nil, "", fmt.Errorf("invalid structured data")
+	}
+	return elements, data, nil
+}
+
+// parseParamValue parses a quoted parameter value up to its closing quote,
+// resolving the \" \\ and \] escapes
+func parseParamValue(data string) (string, string, error) {
+	var value strings.Builder
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			if i+1 < len(data) && strings.IndexByte(`"\]`, data[i+1]) >= 0 {
+				i++
+			}
+			value.WriteByte(data[i])
+		case '"':
+			return value.String(), data[i+1:], nil
+		default:
+			value.WriteByte(data[i])
+		}
+	}
+	return "", "", fmt.Errorf("unterminated parameter value")
+}
+
+// parse3164 parses the part of an RFC 3164 message after the priority. The
+// format is loosely specified, so anything that does not fit is kept in the
+// message rather than rejected.
+func parse3164(msg *Message, rest string, now time.Time) {
+	if len(rest) >= 15 {
+		if timestamp, err := time.ParseInLocation(time.Stamp, rest[:15], now.Location()); err == nil {
+			// The timestamp has no year; a date far in the future belongs to last year
+			timestamp = timestamp.AddDate(now.Year(), 0, 0)
+			if timestamp.After(now.Add(24 * time.Hour)) {
+				timestamp = timestamp.AddDate(-1, 0, 0)
+			}
+			msg.Timestamp = &timestamp
+			rest = strings.TrimPrefix(rest[15:], " ")
+
+			// The hostname follows the timestamp unless the next word is already the tag
+			if word, remainder, ok := strings.Cut(rest, " "); ok && !strings.ContainsAny(word, ":[") {
+				msg.Hostname = word
+				rest = remainder
+			}
+		}
+	}
+
+	// TAG[PID]: CONTENT
+	end := strings.IndexAny(rest, ":[ ")
+	if end > 0 && end <= 32 && rest[end] != ' ' {
+		msg.AppName = rest[:end]
+		remainder := rest[end:]
+		if strings.HasPrefix(remainder, "[") {
+			if pid, after, ok := strings.Cut(remainder[1:], "]"); ok {
+				msg.ProcID = pid
+				remainder = after
+			}
+		}
+		if strings.HasPrefix(remainder, ":") {
+			rest = strings.TrimPrefix(remainder[1:], " ")
+		} else {
+			msg.AppName, msg.ProcID = "", ""
+		}
+	}
+
+	msg.Message = rest
+}
@@ -0,0 +1,143 @@
+package syslog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	at := func(value string) *time.Time {
+		timestamp, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			t.Fatalf("parse %s: %v", value, err)
+		}
+		return &timestamp
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want Message
+	}{
+		{
+			name: "rfc5424",
+			data: "<165>1 2025-03-04T05:06:07.123Z core01 payments 4242 ID47 - transfer completed\n",
+			want: Message{
+				Format: FormatRFC5424, Facility: 20, Severity: 5,
+				Timestamp: at("2025-03-04T05:06:07.123Z"),
+				Hostname:  "core01", AppName: "payments", ProcID: "4242", MsgID: "ID47",
+				Message: "transfer completed",
+			},
+		},
+		{
+			name: "rfc5424 nil values",
+			data: "<13>1 - - - - - -",
+			want: Message{Format: FormatRFC5424, Facility: 1, Severity: 5},
+		},
+		{
+			name: "rfc5424 structured data",
+			data: `<13>1 - host app - - [exampleSDID@32473 iut="3" eventSource="App\"lication\]"][meta account="ACC-1"] ` + "\ufeffmessage",
+			want: Message{
+				Format: FormatRFC5424, Facility: 1, Severity: 5, Hostname: "host", AppName: "app",
+				StructuredData: map[string]map[string]string{
+					"exampleSDID@32473": {"iut": "3", "eventSource": `App"lication]`},
+					"meta":              {"account": "ACC-1"},
+				},
+				Message: "message",
+			},
+		},
+		{
+			name: "rfc5424 structured data without parameters",
+			data: "<13>1 - - - - - [empty]",
+			want: Message{Format: FormatRFC5424, Facility: 1, Severity: 5, StructuredData: map[string]map[string]string{"empty": {}}},
+		},
+		{
+			name: "rfc5424 invalid utf-8",
+			data: "<13>1 - - - - - - bad \xff byte",
+			want: Message{Format: FormatRFC5424, Facility: 1, Severity: 5, Message: "bad \ufffd byte"},
+		},
+		{
+			name: "rfc3164",
+			data: "<34>Mar  4 05:06:07 core01 sshd[811]: session opened\r\n",
+			want: Message{
+				Format: FormatRFC3164, Facility: 4, Severity: 2,
+				Timestamp: at("2025-03-04T05:06:07Z"),
+				Hostname:  "core01", AppName: "sshd", ProcID: "811",
+				Message: "session opened",
+			},
+		},
+		{
+			name: "rfc3164 without hostname",
+			data: "<34>Mar  4 05:06:07 sshd: session opened",
+			want: Message{
+				Format: FormatRFC3164, Facility: 4, Severity: 2,
+				Timestamp: at("2025-03-04T05:06:07Z"),
+				AppName:   "sshd", Message: "session opened",
+			},
+		},
+		{
+			name: "rfc3164 from last year",
+			data: "<34>Dec 31 23:59:59 core01 cron: done",
+			want: Message{
+				Format: FormatRFC3164, Facility: 4, Severity: 2,
+				Timestamp: at("2024-12-31T23:59:59Z"),
+				Hostname:  "core01", AppName: "cron", Message: "done",
+			},
+		},
+		{
+			name: "rfc3164 without timestamp or tag",
+			data: "<13>just some text",
+			want: Message{Format: FormatRFC3164, Facility: 1, Severity: 5, Message: "just some text"},
+		},
+		{
+			name: "rfc3164 tag without colon stays in the message",
+			data: "<13>sshd[811] session opened",
+			want: Message{Format: FormatRFC3164, Facility: 1, Severity: 5, Message: "sshd[811] session opened"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := Parse([]byte(tt.data), now)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(*msg, tt.want) {
+				t.Errorf("Parse =\n%+v\nwant\n%+v", *msg, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsMalformedMessages(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"empty", "", "missing priority"},
+		{"missing priority", "1 - - - - - -", "missing priority"},
+		{"empty priority", "<>1 - - - - - -", "invalid priority"},
+		{"unterminated priority", "<13", "invalid priority"},
+		{"priority too long", "<0013>text", "invalid priority"},
+		{"priority out of range", "<192>text", "invalid priority"},
+		{"non-numeric priority", "<1a>text", "invalid priority"},
+		{"truncated header", "<13>1 2025-03-04T05:06:07Z host app", "truncated RFC 5424 header"},
+		{"invalid timestamp", "<13>1 yesterday host app - - -", "invalid timestamp"},
+		{"missing structured data", "<13>1 - - - - - message", "invalid structured data"},
+		{"empty SD-ID", "<13>1 - - - - - []", "invalid structured data element"},
+		{"parameter without value", "<13>1 - - - - - [id name]", "invalid structured data parameter"},
+		{"unterminated value", `<13>1 - - - - - [id name="value]`, "unterminated parameter value"},
+		{"unterminated element", `<13>1 - - - - - [id name="value"`, "unterminated structured data element"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data), time.Now())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse(%q) = %v, want %q", tt.data, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package tsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/banking-audit-ledger/backend/internal/config"
+)
+
+// maxResponseBytes bounds the size of a time-stamp response
+const maxResponseBytes = 1 << 20
+
+var (
+	// ErrRejected is returned when the TSA does not grant a request
+	ErrRejected = errors.New("time-stamp request rejected")
+	// ErrInvalidToken is returned when a token does not verify
+	ErrInvalidToken = errors.New("invalid time-stamp token")
+)
+
+// TokenInfo describes a verified time-stamp token
+type TokenInfo struct {
+	GenTime      time.Time
+	SerialNumber string
+	Policy       string
+	// Authority is the subject of the certificate that signed the token
+	Authority string
+}
+
+// Client obtains and verifies RFC 3161 time-stamp tokens over SHA-256
+// hashes. Tokens are verified against the configured TSA roots at the time
+// they were issued, so they stay verifiable after the TSA certificate expires.
+type Client struct {
+	url    string
+	policy asn1.ObjectIdentifier
+	roots  *x509.CertPool
+	http   *http.Client
+}
+
+// NewClient creates a client for the TSA in cfg. Tokens are verified against
+// the certificates in cfg.CAFile, or the system roots when it is not set.
+func NewClient(cfg config.TSAConfig) (*Client, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("TSA URL is not set")
+	}
+
+	var roots *x509.CertPool
+	if cfg.CAFile != "" {
+		caPEM, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TSA CA: %w", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in TSA CA %s", cfg.CAFile)
+		}
+	} else {
+		var err error
+		if roots, err = x509.SystemCertPool(); err != nil {
+			return nil, fmt.Errorf("failed to load system roots: %w", err)
+		}
+	}
+
+	var policy asn1.ObjectIdentifier
+	if cfg.PolicyOID != "" {
+		var err error
+		if policy, err = parseOID(cfg.PolicyOID); err != nil {
+			return nil, fmt.Errorf("invalid TSA policy: %w", err)
+		}
+	}
+
+	return New(cfg.URL, policy, roots, cfg.Timeout), nil
+}
+
+// New creates a client for the TSA at url that requests policy, if set, and
+// trusts tokens chaining to roots
+func New(url string, policy asn1.ObjectIdentifier, roots *x509.CertPool, timeout time.Duration) *Client {
+	return &Client{
+		url:    url,
+		policy: policy,
+		roots:  roots,
+		http:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Timestamp requests a token for a SHA-256 digest and returns its DER
+// encoding once it has been verified
+func (c *Client) Timestamp(digest []byte) ([]byte, *TokenInfo, error) {
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 63))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	request, err := asn1.Marshal(timeStampReq{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+			HashedMessage: digest,
+		},
+		ReqPolicy: c.policy,
+		Nonce:     nonce,
+		CertReq:   true,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode time-stamp request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(request))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create time-stamp request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/timestamp-query")
+	httpReq.Header.Set("Accept", "application/timestamp-reply")
+
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to reach TSA: %w", err)
+	}
+	defer httpResp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read time-stamp response: %w", err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("TSA answered %s", httpResp.Status)
+	}
+
+	var response timeStampResp
+	if rest, err := asn1.Unmarshal(body, &response); err != nil || len(rest) > 0 {
+		return nil, nil, fmt.Errorf("%w: malformed time-stamp response", ErrInvalidToken)
+	}
+	if status := response.Status.Status; status != statusGranted && status != statusGrantedWithMods {
+		return nil, nil, fmt.Errorf("%w: status %d %s", ErrRejected, status, strings.Join(response.Status.StatusString, "; "))
+	}
+	token := response.TimeStampToken.FullBytes
+	if len(token) == 0 {
+		return nil, nil, fmt.Errorf("%w: response has no token", ErrInvalidToken)
+	}
+
+	info, tst, err := c.verify(token, digest)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tst.Nonce == nil || tst.Nonce.Cmp(nonce) != 0 {
+		return nil, nil, fmt.Errorf("%w: nonce does not match the request", ErrInvalidToken)
+	}
+	if c.policy != nil && !tst.Policy.Equal(c.policy) {
+		return nil, nil, fmt.Errorf("%w: token policy %s was not requested", ErrInvalidToken, tst.Policy)
+	}
+	return token, info, nil
+}
+
+// Verify checks that a token covers digest, that its signature is valid and
+// that its signer chains to the TSA roots with the time-stamping usage
+func (c *Client) Verify(token, digest []byte) (*TokenInfo, error) {
+	info, _, err := c.verify(token, digest)
+	return info, err
+}
+
+// verify verifies a token and returns it decoded
+func (c *Client) verify(token, digest []byte) (*TokenInfo, *tstInfo, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidToken, fmt.Sprintf(format, args...))
+	}
+
+	var content contentInfo
+	if rest, err := asn1.Unmarshal(token, &content); err != nil || len(rest) > 0 {
+		return nil, nil, invalid("malformed content info")
+	}
+	if !content.ContentType.Equal(oidSignedData) {
+		return nil, nil, invalid("content is not signed data")
+	}
+	var signed signedData
+	if _, err := asn1.Unmarshal(content.Content.Bytes, &signed); err != nil {
+		return nil, nil, invalid("malformed signed data: %v", err)
+	}
+	if !signed.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+		return nil, nil, invalid("content is not a TSTInfo")
+	}
+
+	var tst tstInfo
+	if rest, err := asn1.Unmarshal(signed.EncapContentInfo.EContent, &tst); err != nil || len(rest) > 0 {
+		return nil, nil, invalid("malformed TSTInfo")
+	}
+	// Only SHA-256 imprints are requested, so a token over another
+	// algorithm does not cover the hash even if the bytes match
+	if !tst.MessageImprint.HashAlgorithm.Algorithm.Equal(oidSHA256) {
+		return nil, nil, invalid("imprint algorithm %s is not SHA-256", tst.MessageImprint.HashAlgorithm.Algorithm)
+	}
+	if !bytes.Equal(tst.MessageImprint.HashedMessage, digest) {
+		return nil, nil, invalid("token does not cover the hash")
+	}
+
+	if len(signed.SignerInfos) != 1 {
+		return nil, nil, invalid("token has %d signers", len(signed.SignerInfos))
+	}
+	signer := signed.SignerInfos[0]
+
+	var certificates []*x509.Certificate
+	if len(signed.Certificates.Bytes) > 0 {
+		var err error
+		if certificates, err = x509.ParseCertificates(signed.Certificates.Bytes); err != nil {
+			return nil, nil, invalid("malformed certificates: %v", err)
+		}
+	}
+	certificate, err := signerCertificate(signer.SID, certificates)
+	if err != nil {
+		return nil, nil, invalid("%v", err)
+	}
+
+	if err := checkSignedAttributes(signer, signed.EncapContentInfo.EContent, certificate); err != nil {
+		return nil, nil, invalid("%v", err)
+	}
+	algorithm, err := signatureAlgorithm(certificate, signer)
+	if err != nil {
+		return nil, nil, invalid("%v", err)
+	}
+	signedAttrs := append([]byte{0x31}, signer.SignedAttrs.FullBytes[1:]...)
+	if err := certificate.CheckSignature(algorithm, signedAttrs, signer.Signature); err != nil {
+		return nil, nil, invalid("signature does not verify: %v", err)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certificates {
+		intermediates.AddCert(cert)
+	}
+	if _, err := certificate.Verify(x509.VerifyOptions{
+		Roots:         c.roots,
+		Intermediates: intermediates,
+		CurrentTime:   tst.GenTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}); err != nil {
+		return nil, nil, invalid("untrusted TSA certificate: %v", err)
+	}
+
+	return &TokenInfo{
+		GenTime:      tst.GenTime,
+		SerialNumber: tst.SerialNumber.Text(16),
+		Policy:       tst.Policy.String(),
+		Authority:    certificate.Subject.String(),
+	}, &tst, nil
+}
+
+// signerCertificate finds the certificate a signer identifier names, by
+// issuer and serial number or by subject key identifier
+func signerCertificate(sid asn1.RawValue, certificates []*x509.Certificate) (*x509.Certificate, error) {
+	switch {
+	case sid.Class == asn1.ClassUniversal && sid.Tag == asn1.TagSequence:
+		var id issuerAndSerialNumber
+		if _, err := asn1.Unmarshal(sid.FullBytes, &id); err != nil {
+			return nil, fmt.Errorf("malformed signer identifier: %v", err)
+		}
+		for _, cert := range certificates {
+			if bytes.Equal(cert.RawIssuer, id.Issuer.FullBytes) && cert.SerialNumber.Cmp(id.SerialNumber) == 0 {
+				return cert, nil
+			}
+		}
+	case sid.Class == asn1.ClassContextSpecific && sid.Tag == 0:
+		for _, cert := range certificates {
+			if bytes.Equal(cert.SubjectKeyId, sid.Bytes) {
+				return cert, nil
+			}
+		}
+	}
+	return nil, errors.New("signer certificate not included in token")
+}
+
+// checkSignedAttributes checks that the signed attributes name the TSTInfo
+// content type, carry its digest and identify the signer certificate with
+// an ESS signing certificate (RFC 3161 section 2.4.1)
+func checkSignedAttributes(signer signerInfo, content []byte, certificate *x509.Certificate) error {
+	if len(signer.SignedAttrs.FullBytes) == 0 {
+		return errors.New("token has no signed attributes")
+	}
+	var attributes []attribute
+	if _, err := asn1.UnmarshalWithParams(signer.SignedAttrs.FullBytes, &attributes, "tag:0"); err != nil {
+		return fmt.Errorf("malformed signed attributes: %v", err)
+	}
+
+	hash, ok := hashForOID(signer.DigestAlgorithm.Algorithm)
+	if !ok {
+		return fmt.Errorf("unsupported digest algorithm %s", signer.DigestAlgorithm.Algorithm)
+	}
+	digest := hash.New()
+	digest.Write(content)
+
+	var contentTypeMatches, digestMatches, certificateMatches bool
+	for _, attr := range attributes {
+		if len(attr.Values) != 1 {
+			continue
+		}
+		switch {
+		case attr.Type.Equal(oidContentType):
+			var contentType asn1.ObjectIdentifier
+			if _, err := asn1.Unmarshal(attr.Values[0].FullBytes, &contentType); err == nil {
+				contentTypeMatches = contentType.Equal(oidTSTInfo)
+			}
+		case attr.Type.Equal(oidMessageDigest):
+			var value []byte
+			if _, err := asn1.Unmarshal(attr.Values[0].FullBytes, &value); err == nil {
+				digestMatches = bytes.Equal(value, digest.Sum(nil))
+			}
+		case attr.Type.Equal(oidSigningCertificateV2):
+			var signingCertificate signingCertificateV2
+			if _, err := asn1.Unmarshal(attr.Values[0].FullBytes, &signingCertificate); err == nil && len(signingCertificate.Certs) > 0 {
+				certificateMatches = certificateHashMatches(signingCertificate.Certs[0], certificate)
+			}
+		}
+	}
+	if !contentTypeMatches {
+		return errors.New("signed content type is not TSTInfo")
+	}
+	if !digestMatches {
+		return errors.New("TSTInfo does not match its signed digest")
+	}
+	if !certificateMatches {
+		return errors.New("signing certificate attribute does not name the signer certificate")
+	}
+	return nil
+}
+
+// certificateHashMatches reports whether an ESS certificate identifier holds
+// the hash of certificate
+func certificateHashMatches(id essCertIDv2, certificate *x509.Certificate) bool {
+	hash := crypto.SHA256
+	if id.HashAlgorithm.Algorithm != nil {
+		var ok bool
+		if hash, ok = hashForOID(id.HashAlgorithm.Algorithm); !ok {
+			return false
+		}
+	}
+	digest := hash.New()
+	digest.Write(certificate.Raw)
+	return bytes.Equal(id.CertHash, digest.Sum(nil))
+}
+
+// signatureAlgorithm returns the x509 algorithm of a signer's signature,
+// from its certificate key and digest algorithm
+func signatureAlgorithm(certificate *x509.Certificate, signer signerInfo) (x509.SignatureAlgorithm, error) {
+	hash, ok := hashForOID(signer.DigestAlgorithm.Algorithm)
+	if !ok {
+		return x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported digest algorithm %s", signer.DigestAlgorithm.Algorithm)
+	}
+	index := map[crypto.Hash]int{crypto.SHA256: 0, crypto.SHA384: 1, crypto.SHA512: 2}[hash]
+
+	switch certificate.PublicKeyAlgorithm {
+	case x509.RSA:
+		if signer.SignatureAlgorithm.Algorithm.Equal(oidRSASSAPSS) {
+			return []x509.SignatureAlgorithm{x509.SHA256WithRSAPSS, x509.SHA384WithRSAPSS, x509.SHA512WithRSAPSS}[index], nil
+		}
+		return []x509.SignatureAlgorithm{x509.SHA256WithRSA, x509.SHA384WithRSA, x509.SHA512WithRSA}[index], nil
+	case x509.ECDSA:
+		return []x509.SignatureAlgorithm{x509.ECDSAWithSHA256, x509.ECDSAWithSHA384, x509.ECDSAWithSHA512}[index], nil
+	case x509.Ed25519:
+		return x509.PureEd25519, nil
+	}
+	return x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported TSA key algorithm %s", certificate.PublicKeyAlgorithm)
+}
+
+// parseOID parses a dotted object identifier
+func parseOID(s string) (asn1.ObjectIdentifier, error) {
+	var oid asn1.ObjectIdentifier
+	for _, part := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%q is not an object identifier", s)
+		}
+		oid = append(oid, n)
+	}
+	if len(oid) < 2 {
+		return nil, fmt.Errorf("%q is not an object identifier", s)
+	}
+	return oid, nil
+}
@@ -0,0 +1,223 @@
+package tsa
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// oidServerAuth is the TLS server extended key usage
+var oidServerAuth = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 1}
+
+// newTestClient serves stub over httptest and returns a client trusting it
+func newTestClient(t *testing.T, stub *Stub) *Client {
+	t.Helper()
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+	return New(server.URL, nil, stub.Roots(), 5*time.Second)
+}
+
+func mustStub(t *testing.T, usages ...asn1.ObjectIdentifier) *Stub {
+	t.Helper()
+	stub, err := newStub(usages...)
+	if err != nil {
+		t.Fatalf("newStub: %v", err)
+	}
+	return stub
+}
+
+func testDigest(s string) []byte {
+	sum := sha256.Sum256([]byte(s))
+	return sum[:]
+}
+
+// expectInvalid fails unless err is ErrInvalidToken mentioning reason
+func expectInvalid(t *testing.T, err error, reason string) {
+	t.Helper()
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("err = %v, want ErrInvalidToken", err)
+	}
+	if !strings.Contains(err.Error(), reason) {
+		t.Errorf("err = %v, want it to mention %q", err, reason)
+	}
+}
+
+func TestTimestampIssuesAndVerifies(t *testing.T) {
+	stub := mustStub(t, oidTimeStamping)
+	client := newTestClient(t, stub)
+	digest := testDigest("log")
+
+	token, info, err := client.Timestamp(digest)
+	if err != nil {
+		t.Fatalf("Timestamp: %v", err)
+	}
+	if info.Policy != stubPolicy.String() {
+		t.Errorf("Policy = %s, want %s", info.Policy, stubPolicy)
+	}
+	if info.Authority != stub.certificate.Subject.String() {
+		t.Errorf("Authority = %s, want %s", info.Authority, stub.certificate.Subject)
+	}
+	if time.Since(info.GenTime) > time.Minute {
+		t.Errorf("GenTime = %s, want about now", info.GenTime)
+	}
+
+	verified, err := client.Verify(token, digest)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if verified.SerialNumber != info.SerialNumber || !verified.GenTime.Equal(info.GenTime) {
+		t.Errorf("Verify = %+v, want %+v", verified, info)
+	}
+}
+
+func TestVerifyRejectsWrongDigest(t *testing.T) {
+	client := newTestClient(t, mustStub(t, oidTimeStamping))
+	token, _, err := client.Timestamp(testDigest("log"))
+	if err != nil {
+		t.Fatalf("Timestamp: %v", err)
+	}
+
+	_, err = client.Verify(token, testDigest("other log"))
+	expectInvalid(t, err, "does not cover the hash")
+}
+
+func TestVerifyRejectsTamperedToken(t *testing.T) {
+	client := newTestClient(t, mustStub(t, oidTimeStamping))
+	digest := testDigest("log")
+	token, _, err := client.Timestamp(digest)
+	if err != nil {
+		t.Fatalf("Timestamp: %v", err)
+	}
+
+	t.Run("signature", func(t *testing.T) {
+		tampered := rewriteSignedData(t, token, func(signed *signedData) {
+			signature := append([]byte(nil), signed.SignerInfos[0].Signature...)
+			signature[len(signature)-1] ^= 0x01
+			signed.SignerInfos[0].Signature = signature
+		})
+		_, err := client.Verify(tampered, digest)
+		expectInvalid(t, err, "signature does not verify")
+	})
+
+	t.Run("content", func(t *testing.T) {
+		tampered := rewriteSignedData(t, token, func(signed *signedData) {
+			var tst tstInfo
+			if _, err := asn1.Unmarshal(signed.EncapContentInfo.EContent, &tst); err != nil {
+				t.Fatalf("unmarshal TSTInfo: %v", err)
+			}
+			tst.GenTime = tst.GenTime.Add(-time.Hour)
+			content, err := asn1.Marshal(tst)
+			if err != nil {
+				t.Fatalf("marshal TSTInfo: %v", err)
+			}
+			signed.EncapContentInfo.EContent = content
+		})
+		_, err := client.Verify(tampered, digest)
+		expectInvalid(t, err, "does not match its signed digest")
+	})
+}
+
+func TestVerifyRejectsUntrustedSigner(t *testing.T) {
+	stub := mustStub(t, oidTimeStamping)
+	token, _, err := newTestClient(t, stub).Timestamp(testDigest("log"))
+	if err != nil {
+		t.Fatalf("Timestamp: %v", err)
+	}
+
+	// A client trusting another TSA does not accept the token
+	other := mustStub(t, oidTimeStamping)
+	client := New("http://unused", nil, other.Roots(), time.Second)
+	_, err = client.Verify(token, testDigest("log"))
+	expectInvalid(t, err, "untrusted TSA certificate")
+}
+
+func TestVerifyRejectsSignerWithoutTimeStampingUsage(t *testing.T) {
+	client := newTestClient(t, mustStub(t, oidServerAuth))
+
+	_, _, err := client.Timestamp(testDigest("log"))
+	expectInvalid(t, err, "untrusted TSA certificate")
+}
+
+func TestVerifyRejectsSigningCertificateOfAnotherCertificate(t *testing.T) {
+	stub := mustStub(t, oidTimeStamping)
+	stub.essCertificate = mustStub(t, oidTimeStamping).certificate
+	client := newTestClient(t, stub)
+
+	_, _, err := client.Timestamp(testDigest("log"))
+	expectInvalid(t, err, "signing certificate attribute")
+}
+
+func TestVerifyRejectsOtherImprintAlgorithm(t *testing.T) {
+	stub := mustStub(t, oidTimeStamping)
+	client := newTestClient(t, stub)
+
+	// A SHA-384 token over bytes that happen to be the expected digest
+	digest := sha512.Sum384([]byte("log"))
+	response, err := stub.respond(&timeStampReq{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA384, Parameters: asn1.NullRawValue},
+			HashedMessage: digest[:],
+		},
+		CertReq: true,
+	})
+	if err != nil {
+		t.Fatalf("respond: %v", err)
+	}
+	var resp timeStampResp
+	if _, err := asn1.Unmarshal(response, &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+
+	_, err = client.Verify(resp.TimeStampToken.FullBytes, digest[:])
+	expectInvalid(t, err, "is not SHA-256")
+}
+
+func TestTimestampRejectedStatus(t *testing.T) {
+	stub := mustStub(t, oidTimeStamping)
+	stub.status = 2
+	client := newTestClient(t, stub)
+
+	_, _, err := client.Timestamp(testDigest("log"))
+	if !errors.Is(err, ErrRejected) {
+		t.Fatalf("err = %v, want ErrRejected", err)
+	}
+	if !strings.Contains(err.Error(), "request refused") {
+		t.Errorf("err = %v, want the TSA's status string", err)
+	}
+}
+
+// rewriteSignedData decodes a token, applies edit to its signed data and
+// encodes it again
+func rewriteSignedData(t *testing.T, token []byte, edit func(*signedData)) []byte {
+	t.Helper()
+	var content contentInfo
+	if _, err := asn1.Unmarshal(token, &content); err != nil {
+		t.Fatalf("unmarshal content info: %v", err)
+	}
+	var signed signedData
+	if _, err := asn1.Unmarshal(content.Content.Bytes, &signed); err != nil {
+		t.Fatalf("unmarshal signed data: %v", err)
+	}
+
+	edit(&signed)
+
+	signedDER, err := asn1.Marshal(signed)
+	if err != nil {
+		t.Fatalf("marshal signed data: %v", err)
+	}
+	rewritten, err := asn1.Marshal(contentInfo{
+		ContentType: content.ContentType,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedDER},
+	})
+	if err != nil {
+		t.Fatalf("marshal content info: %v", err)
+	}
+	return rewritten
+}
@@ -0,0 +1,218 @@
+package tsa
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// stubPolicy is the policy of stub tokens, under the documentation-only
+// enterprise number of RFC 5612
+var stubPolicy = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 32473, 1}
+
+// Stub is an in-process time-stamping authority for tests. It signs
+// SHA-256, SHA-384 and SHA-512 requests with a self-signed ECDSA
+// certificate; serve it with net/http/httptest and point a client built with
+// Roots at it.
+type Stub struct {
+	key         *ecdsa.PrivateKey
+	certificate *x509.Certificate
+	serial      atomic.Int64
+
+	// status, when not granted, is answered to every request without a token
+	status int
+	// essCertificate is the certificate the signing certificate attribute
+	// names, the stub's own when nil
+	essCertificate *x509.Certificate
+}
+
+// NewStub creates a stub TSA with a fresh key and certificate
+func NewStub() (*Stub, error) {
+	return newStub(oidTimeStamping)
+}
+
+// newStub creates a stub TSA whose certificate carries the given extended
+// key usages
+func newStub(usages ...asn1.ObjectIdentifier) (*Stub, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate stub TSA key: %w", err)
+	}
+	// RFC 3161 requires the time-stamping extended key usage to be critical
+	extKeyUsage, err := asn1.Marshal(usages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode stub TSA key usage: %w", err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(1),
+		Subject:         pkix.Name{CommonName: "Audit Ledger Stub TSA"},
+		NotBefore:       now.Add(-time.Hour),
+		NotAfter:        now.Add(24 * time.Hour),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtraExtensions: []pkix.Extension{{Id: oidExtKeyUsage, Critical: true, Value: extKeyUsage}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stub TSA certificate: %w", err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stub TSA certificate: %w", err)
+	}
+	return &Stub{key: key, certificate: certificate}, nil
+}
+
+// Roots returns a pool trusting the stub's certificate
+func (s *Stub) Roots() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(s.certificate)
+	return pool
+}
+
+// ServeHTTP answers an RFC 3161 time-stamp query
+func (s *Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var request timeStampReq
+	if _, err := asn1.Unmarshal(body, &request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := s.respond(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/timestamp-reply")
+	w.Write(response)
+}
+
+// respond builds the DER time-stamp response to a request
+func (s *Stub) respond(request *timeStampReq) ([]byte, error) {
+	if s.status != statusGranted {
+		return asn1.Marshal(timeStampResp{Status: pkiStatusInfo{
+			Status:       s.status,
+			StatusString: []string{"request refused"},
+		}})
+	}
+
+	hash, ok := hashForOID(request.MessageImprint.HashAlgorithm.Algorithm)
+	if !ok || len(request.MessageImprint.HashedMessage) != hash.Size() {
+		// badAlg is bit 0 of the failure info
+		return asn1.Marshal(timeStampResp{Status: pkiStatusInfo{
+			Status:       2,
+			StatusString: []string{"unsupported hash algorithm"},
+			FailInfo:     asn1.BitString{Bytes: []byte{0x80}, BitLength: 1},
+		}})
+	}
+
+	policy := stubPolicy
+	if request.ReqPolicy != nil {
+		policy = request.ReqPolicy
+	}
+	content, err := asn1.Marshal(tstInfo{
+		Version:        1,
+		Policy:         policy,
+		MessageImprint: request.MessageImprint,
+		SerialNumber:   big.NewInt(s.serial.Add(1)),
+		GenTime:        time.Now().UTC().Truncate(time.Second),
+		Accuracy:       accuracy{Seconds: 1},
+		Nonce:          request.Nonce,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	contentDigest := sha256.Sum256(content)
+	contentType, err := asn1.Marshal(oidTSTInfo)
+	if err != nil {
+		return nil, err
+	}
+	messageDigest, err := asn1.Marshal(contentDigest[:])
+	if err != nil {
+		return nil, err
+	}
+	// The ESS signing certificate binds the signature to the certificate
+	essCertificate := s.certificate
+	if s.essCertificate != nil {
+		essCertificate = s.essCertificate
+	}
+	certificateHash := sha256.Sum256(essCertificate.Raw)
+	signingCertificate, err := asn1.Marshal(signingCertificateV2{Certs: []essCertIDv2{{CertHash: certificateHash[:]}}})
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := asn1.MarshalWithParams([]attribute{
+		{Type: oidContentType, Values: []asn1.RawValue{{FullBytes: contentType}}},
+		{Type: oidMessageDigest, Values: []asn1.RawValue{{FullBytes: messageDigest}}},
+		{Type: oidSigningCertificateV2, Values: []asn1.RawValue{{FullBytes: signingCertificate}}},
+	}, "set")
+	if err != nil {
+		return nil, err
+	}
+	attributesDigest := sha256.Sum256(attributes)
+	signature, err := ecdsa.SignASN1(rand.Reader, s.key, attributesDigest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	sid, err := asn1.Marshal(issuerAndSerialNumber{
+		Issuer:       asn1.RawValue{FullBytes: s.certificate.RawIssuer},
+		SerialNumber: s.certificate.SerialNumber,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The signed attributes are sent as [0] IMPLICIT but signed as a SET
+	signedAttrs := append([]byte{0xa0}, attributes[1:]...)
+	sha256Algorithm := pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue}
+
+	signed := signedData{
+		Version:          3,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{sha256Algorithm},
+		EncapContentInfo: encapsulatedContentInfo{EContentType: oidTSTInfo, EContent: content},
+		SignerInfos: []signerInfo{{
+			Version:            1,
+			SID:                asn1.RawValue{FullBytes: sid},
+			DigestAlgorithm:    sha256Algorithm,
+			SignedAttrs:        asn1.RawValue{FullBytes: signedAttrs},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA256},
+			Signature:          signature,
+		}},
+	}
+	if request.CertReq {
+		signed.Certificates = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: s.certificate.Raw}
+	}
+	signedDER, err := asn1.Marshal(signed)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := asn1.Marshal(contentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedDER},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(timeStampResp{
+		Status:         pkiStatusInfo{Status: statusGranted},
+		TimeStampToken: asn1.RawValue{FullBytes: token},
+	})
+}
@@ -0,0 +1,241 @@
+package jcs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Canonicalize returns the RFC 8785 JSON Canonicalization Scheme form of a
+// JSON document. Input that is not I-JSON is rejected: invalid UTF-8,
+// duplicate object keys, and numbers that cannot be represented exactly as
+// an IEEE 754 double. Such numbers, for example large account balances,
+// must be sent as strings so that the canonical form stays lossless.
+func Canonicalize(data []byte) ([]byte, error) {
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("invalid UTF-8 in JSON document")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+	if err := writeValue(&buf, dec); err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON document")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeValue reads one JSON value from the decoder and writes its canonical form
+func writeValue(buf *bytes.Buffer, dec *json.Decoder) error {
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	switch v := token.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			return writeObject(buf, dec)
+		case '[':
+			return writeArray(buf, dec)
+		}
+		return fmt.Errorf("invalid JSON: unexpected %q", rune(v))
+	case string:
+		writeString(buf, v)
+	case json.Number:
+		number, err := formatNumber(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(number)
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case nil:
+		buf.WriteString("null")
+	}
+
+	return nil
+}
+
+// writeObject writes the members of an object sorted by the UTF-16 code units of their keys
+func writeObject(buf *bytes.Buffer, dec *json.Decoder) error {
+	members := map[string][]byte{}
+	keys := []string{}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("invalid JSON: object key is not a string")
+		}
+		if _, exists := members[key]; exists {
+			return fmt.Errorf("duplicate object key %q", key)
+		}
+
+		var value bytes.Buffer
+		if err := writeValue(&value, dec); err != nil {
+			return err
+		}
+		members[key] = value.Bytes()
+		keys = append(keys, key)
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return lessUTF16(keys[i], keys[j])
+	})
+
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeString(buf, key)
+		buf.WriteByte(':')
+		buf.Write(members[key])
+	}
+	buf.WriteByte('}')
+
+	return nil
+}
+
+// writeArray writes the elements of an array in their original order
+func writeArray(buf *bytes.Buffer, dec *json.Decoder) error {
+	buf.WriteByte('[')
+	for i := 0; dec.More(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := writeValue(buf, dec); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	buf.WriteByte(']')
+
+	return nil
+}
+
+// writeString writes a string using the minimal escaping of ECMAScript JSON.stringify
+func writeString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// formatNumber serializes a number as ECMAScript Number.prototype.toString
+// does, rejecting numbers whose value would change in the process
+func formatNumber(n json.Number) (string, error) {
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil || math.IsInf(f, 0) {
+		return "", fmt.Errorf("number %s cannot be represented exactly, send it as a string", n)
+	}
+
+	formatted := formatES6(f)
+
+	exact, ok := new(big.Rat).SetString(string(n))
+	if !ok {
+		return "", fmt.Errorf("invalid number %s", n)
+	}
+	canonical, _ := new(big.Rat).SetString(formatted)
+	if exact.Cmp(canonical) != 0 {
+		return "", fmt.Errorf("number %s cannot be represented exactly, send it as a string", n)
+	}
+
+	return formatted, nil
+}
+
+// formatES6 formats a finite double using the ECMAScript shortest round-trip algorithm
+func formatES6(f float64) string {
+	if f == 0 {
+		return "0"
+	}
+
+	sign := ""
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// Shortest digits d1.d2d3...e±x give value 0.d1d2d3... * 10^n with n = x+1
+	mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	x, _ := strconv.Atoi(exponent)
+	n := x + 1
+	k := len(digits)
+
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits
+	}
+
+	expSign := "+"
+	if n-1 < 0 {
+		expSign = "-"
+	}
+	exp := strconv.Itoa(int(math.Abs(float64(n - 1))))
+	if k == 1 {
+		return sign + digits + "e" + expSign + exp
+	}
+	return sign + digits[:1] + "." + digits[1:] + "e" + expSign + exp
+}
+
+// lessUTF16 orders strings by their UTF-16 code units as RFC 8785 requires
+func lessUTF16(a, b string) bool {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
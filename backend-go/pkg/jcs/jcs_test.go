@@ -0,0 +1,129 @@
+package jcs
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			// RFC 8785 section 3.2.2, without 333333333.33333329 which is
+			// not exactly representable and is rejected below
+			name: "rfc 8785 primitives",
+			input: `{
+  "numbers": [1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`,
+			want: `{"literals":[null,true,false],"numbers":[1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			// RFC 8785 section 3.2.3, keys sorted by UTF-16 code units
+			name: "rfc 8785 sorting",
+			input: `{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`,
+			want: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001F600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{
+			name:  "nested objects are sorted and arrays keep their order",
+			input: ` { "b" : [ 3, {"z": 1, "a": 2}, 1 ], "a" : { "d": {}, "c": [] } } `,
+			want:  `{"a":{"c":[],"d":{}},"b":[3,{"a":2,"z":1},1]}`,
+		},
+		{
+			name:  "integers keep every digit up to 2^53",
+			input: `[9007199254740992, -9007199254740992, 100, -0, 0.0]`,
+			want:  `[9007199254740992,-9007199254740992,100,0,0]`,
+		},
+		{
+			name:  "scalar document",
+			input: `"plain"`,
+			want:  `"plain"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonicalize([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Canonicalize: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Canonicalize = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"inexact decimal", `[333333333.33333329]`},
+		{"integer beyond 2^53", `{"balance": 9007199254740993}`},
+		{"overflow", `[1e400]`},
+		{"duplicate key", `{"a": 1, "a": 2}`},
+		{"invalid utf-8", "{\"a\": \"\xff\"}"},
+		{"trailing data", `{"a": 1} {}`},
+		{"truncated", `{"a": [1, 2}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Canonicalize([]byte(tt.input)); err == nil {
+				t.Errorf("Canonicalize(%s) = %s, want error", tt.input, got)
+			}
+		})
+	}
+}
+
+func TestFormatES6(t *testing.T) {
+	// RFC 8785 appendix B, IEEE 754 bit patterns and their serialization
+	tests := []struct {
+		bits uint64
+		want string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+
+	for _, tt := range tests {
+		if got := formatES6(math.Float64frombits(tt.bits)); got != tt.want {
+			t.Errorf("formatES6(%016x) = %s, want %s", tt.bits, got, tt.want)
+		}
+	}
+}
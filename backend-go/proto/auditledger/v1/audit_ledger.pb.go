@@ -0,0 +1,1355 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: auditledger/v1/audit_ledger.proto
+
+package auditledgerv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateLogRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional client-supplied UUID; retries with the same ID do not create duplicates
+	LogId     string `protobuf:"bytes,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	Source    string `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	EventType string `protobuf:"bytes,3,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	// JSON document, hashed exactly as sent
+	Payload []byte `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
+	// Optional key whose stored response is replayed on retries
+	IdempotencyKey string `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Optional base64 producer signature, made with the registered key key_id
+	Signature     string `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`
+	KeyId         string `protobuf:"bytes,7,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateLogRequest) Reset() {
+	*x = CreateLogRequest{}
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLogRequest) ProtoMessage() {}
+
+func (x *CreateLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLogRequest.ProtoReflect.Descriptor instead.
+func (*CreateLogRequest) Descriptor() ([]byte, []int) {
+	return file_auditledger_v1_audit_ledger_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateLogRequest) GetLogId() string {
+	if x != nil {
+		return x.LogId
+	}
+	return ""
+}
+
+func (x *CreateLogRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *CreateLogRequest) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *CreateLogRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *CreateLogRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *CreateLogRequest) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *CreateLogRequest) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+type CreateLogResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Log   *Log                   `protobuf:"bytes,1,opt,name=log,proto3" json:"log,omitempty"`
+	// True when the response repeats the outcome of an earlier request
+	Replayed bool `protobuf:"varint,2,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	// Signed ingestion receipt (compact JWS) when receipts are enabled
+	Receipt       string `protobuf:"bytes,3,opt,name=receipt,proto3" json:"receipt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateLogResponse) Reset() {
+	*x = CreateLogResponse{}
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLogResponse) ProtoMessage() {}
+
+func (x *CreateLogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLogResponse.ProtoReflect.Descriptor instead.
+func (*CreateLogResponse) Descriptor() ([]byte, []int) {
+	return file_auditledger_v1_audit_ledger_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateLogResponse) GetLog() *Log {
+	if x != nil {
+		return x.Log
+	}
+	return nil
+}
+
+func (x *CreateLogResponse) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+func (x *CreateLogResponse) GetReceipt() string {
+	if x != nil {
+		return x.Receipt
+	}
+	return ""
+}
+
+type Log struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Source    string                 `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	EventType string                 `protobuf:"bytes,4,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	// JSON document as originally sent
+	Payload        []byte                 `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`
+	Hash           string                 `protobuf:"bytes,6,opt,name=hash,proto3" json:"hash,omitempty"`
+	ContentHash    string                 `protobuf:"bytes,7,opt,name=content_hash,json=contentHash,proto3" json:"content_hash,omitempty"`
+	HashVersion    int32                  `protobuf:"varint,8,opt,name=hash_version,json=hashVersion,proto3" json:"hash_version,omitempty"`
+	Sequence       *int64                 `protobuf:"varint,9,opt,name=sequence,proto3,oneof" json:"sequence,omitempty"`
+	PrevHash       string                 `protobuf:"bytes,10,opt,name=prev_hash,json=prevHash,proto3" json:"prev_hash,omitempty"`
+	TxId           string                 `protobuf:"bytes,11,opt,name=tx_id,json=txId,proto3" json:"tx_id,omitempty"`
+	CommittedAt    *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=committed_at,json=committedAt,proto3" json:"committed_at,omitempty"`
+	BatchId        string                 `protobuf:"bytes,13,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	LeafIndex      *int32                 `protobuf:"varint,14,opt,name=leaf_index,json=leafIndex,proto3,oneof" json:"leaf_index,omitempty"`
+	Signature      string                 `protobuf:"bytes,15,opt,name=signature,proto3" json:"signature,omitempty"`
+	SignatureKeyId string                 `protobuf:"bytes,16,opt,name=signature_key_id,json=signatureKeyId,proto3" json:"signature_key_id,omitempty"`
+	// When the event occurred at the producer, if it said so
+	EventTime     *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=event_time,json=eventTime,proto3" json:"event_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Log) Reset() {
+	*x = Log{}
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Log) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Log) ProtoMessage() {}
+
+func (x *Log) ProtoReflect() protoreflect.Message {
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Log.ProtoReflect.Descriptor instead.
+func (*Log) Descriptor() ([]byte, []int) {
+	return file_auditledger_v1_audit_ledger_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Log) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Log) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Log) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Log) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *Log) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Log) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Log) GetContentHash() string {
+	if x != nil {
+		return x.ContentHash
+	}
+	return ""
+}
+
+func (x *Log) GetHashVersion() int32 {
+	if x != nil {
+		return x.HashVersion
+	}
+	return 0
+}
+
+func (x *Log) GetSequence() int64 {
+	if x != nil && x.Sequence != nil {
+		return *x.Sequence
+	}
+	return 0
+}
+
+func (x *Log) GetPrevHash() string {
+	if x != nil {
+		return x.PrevHash
+	}
+	return ""
+}
+
+func (x *Log) GetTxId() string {
+	if x != nil {
+		return x.TxId
+	}
+	return ""
+}
+
+func (x *Log) GetCommittedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CommittedAt
+	}
+	return nil
+}
+
+func (x *Log) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *Log) GetLeafIndex() int32 {
+	if x != nil && x.LeafIndex != nil {
+		return *x.LeafIndex
+	}
+	return 0
+}
+
+func (x *Log) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *Log) GetSignatureKeyId() string {
+	if x != nil {
+		return x.SignatureKeyId
+	}
+	return ""
+}
+
+func (x *Log) GetEventTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EventTime
+	}
+	return nil
+}
+
+type GetLogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLogRequest) Reset() {
+	*x = GetLogRequest{}
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLogRequest) ProtoMessage() {}
+
+func (x *GetLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLogRequest.ProtoReflect.Descriptor instead.
+func (*GetLogRequest) Descriptor() ([]byte, []int) {
+	return file_auditledger_v1_audit_ledger_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetLogRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListLogsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Source        string                 `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	EventType     string                 `protobuf:"bytes,4,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLogsRequest) Reset() {
+	*x = ListLogsRequest{}
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLogsRequest) ProtoMessage() {}
+
+func (x *ListLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLogsRequest.ProtoReflect.Descriptor instead.
+func (*ListLogsRequest) Descriptor() ([]byte, []int) {
+	return file_auditledger_v1_audit_ledger_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListLogsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListLogsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListLogsRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ListLogsRequest) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+type ListLogsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Logs          []*Log                 `protobuf:"bytes,1,rep,name=logs,proto3" json:"logs,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	TotalPages    int32                  `protobuf:"varint,5,opt,name=total_pages,json=totalPages,proto3" json:"total_pages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLogsResponse) Reset() {
+	*x = ListLogsResponse{}
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLogsResponse) ProtoMessage() {}
+
+func (x *ListLogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLogsResponse.ProtoReflect.Descriptor instead.
+func (*ListLogsResponse) Descriptor() ([]byte, []int) {
+	return file_auditledger_v1_audit_ledger_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListLogsResponse) GetLogs() []*Log {
+	if x != nil {
+		return x.Logs
+	}
+	return nil
+}
+
+func (x *ListLogsResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListLogsResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListLogsResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListLogsResponse) GetTotalPages() int32 {
+	if x != nil {
+		return x.TotalPages
+	}
+	return 0
+}
+
+type VerifyLogRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Types that are valid to be assigned to Provided:
+	//
+	//	*VerifyLogRequest_Hash
+	//	*VerifyLogRequest_Payload
+	Provided      isVerifyLogRequest_Provided `protobuf_oneof:"provided"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyLogRequest) Reset() {
+	*x = VerifyLogRequest{}
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyLogRequest) ProtoMessage() {}
+
+func (x *VerifyLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyLogRequest.ProtoReflect.Descriptor instead.
+func (*VerifyLogRequest) Descriptor() ([]byte, []int) {
+	return file_auditledger_v1_audit_ledger_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *VerifyLogRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *VerifyLogRequest) GetProvided() isVerifyLogRequest_Provided {
+	if x != nil {
+		return x.Provided
+	}
+	return nil
+}
+
+func (x *VerifyLogRequest) GetHash() string {
+	if x != nil {
+		if x, ok := x.Provided.(*VerifyLogRequest_Hash); ok {
+			return x.Hash
+		}
+	}
+	return ""
+}
+
+func (x *VerifyLogRequest) GetPayload() []byte {
+	if x != nil {
+		if x, ok := x.Provided.(*VerifyLogRequest_Payload); ok {
+			return x.Payload
+		}
+	}
+	return nil
+}
+
+type isVerifyLogRequest_Provided interface {
+	isVerifyLogRequest_Provided()
+}
+
+type VerifyLogRequest_Hash struct {
+	// Hash held by the caller
+	Hash string `protobuf:"bytes,2,opt,name=hash,proto3,oneof"`
+}
+
+type VerifyLogRequest_Payload struct {
+	// Original JSON payload held by the caller, hashed as at creation
+	Payload []byte `protobuf:"bytes,3,opt,name=payload,proto3,oneof"`
+}
+
+func (*VerifyLogRequest_Hash) isVerifyLogRequest_Provided() {}
+
+func (*VerifyLogRequest_Payload) isVerifyLogRequest_Provided() {}
+
+type FieldMismatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Offchain      string                 `protobuf:"bytes,2,opt,name=offchain,proto3" json:"offchain,omitempty"`
+	Onchain       string                 `protobuf:"bytes,3,opt,name=onchain,proto3" json:"onchain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldMismatch) Reset() {
+	*x = FieldMismatch{}
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldMismatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldMismatch) ProtoMessage() {}
+
+func (x *FieldMismatch) ProtoReflect() protoreflect.Message {
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldMismatch.ProtoReflect.Descriptor instead.
+func (*FieldMismatch) Descriptor() ([]byte, []int) {
+	return file_auditledger_v1_audit_ledger_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FieldMismatch) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FieldMismatch) GetOffchain() string {
+	if x != nil {
+		return x.Offchain
+	}
+	return ""
+}
+
+func (x *FieldMismatch) GetOnchain() string {
+	if x != nil {
+		return x.Onchain
+	}
+	return ""
+}
+
+// Signer identifies the producer that signed a log
+type Signer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	KeyId         string                 `protobuf:"bytes,1,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Algorithm     string                 `protobuf:"bytes,3,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	Fingerprint   string                 `protobuf:"bytes,4,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	Valid         bool                   `protobuf:"varint,5,opt,name=valid,proto3" json:"valid,omitempty"`
+	Details       string                 `protobuf:"bytes,6,opt,name=details,proto3" json:"details,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Signer) Reset() {
+	*x = Signer{}
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Signer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Signer) ProtoMessage() {}
+
+func (x *Signer) ProtoReflect() protoreflect.Message {
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Signer.ProtoReflect.Descriptor instead.
+func (*Signer) Descriptor() ([]byte, []int) {
+	return file_auditledger_v1_audit_ledger_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Signer) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *Signer) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Signer) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *Signer) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+func (x *Signer) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *Signer) GetDetails() string {
+	if x != nil {
+		return x.Details
+	}
+	return ""
+}
+
+// TrustedTimestamp is the RFC 3161 timestamp covering a log hash or its
+// batch root
+type TrustedTimestamp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GenTime       *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=gen_time,json=genTime,proto3" json:"gen_time,omitempty"`
+	SerialNumber  string                 `protobuf:"bytes,2,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	Policy        string                 `protobuf:"bytes,3,opt,name=policy,proto3" json:"policy,omitempty"`
+	Authority     string                 `protobuf:"bytes,4,opt,name=authority,proto3" json:"authority,omitempty"`
+	Hash          string                 `protobuf:"bytes,5,opt,name=hash,proto3" json:"hash,omitempty"`
+	BatchId       string                 `protobuf:"bytes,6,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	Valid         bool                   `protobuf:"varint,7,opt,name=valid,proto3" json:"valid,omitempty"`
+	Details       string                 `protobuf:"bytes,8,opt,name=details,proto3" json:"details,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrustedTimestamp) Reset() {
+	*x = TrustedTimestamp{}
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrustedTimestamp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrustedTimestamp) ProtoMessage() {}
+
+func (x *TrustedTimestamp) ProtoReflect() protoreflect.Message {
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrustedTimestamp.ProtoReflect.Descriptor instead.
+func (*TrustedTimestamp) Descriptor() ([]byte, []int) {
+	return file_auditledger_v1_audit_ledger_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *TrustedTimestamp) GetGenTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.GenTime
+	}
+	return nil
+}
+
+func (x *TrustedTimestamp) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+func (x *TrustedTimestamp) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *TrustedTimestamp) GetAuthority() string {
+	if x != nil {
+		return x.Authority
+	}
+	return ""
+}
+
+func (x *TrustedTimestamp) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *TrustedTimestamp) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *TrustedTimestamp) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *TrustedTimestamp) GetDetails() string {
+	if x != nil {
+		return x.Details
+	}
+	return ""
+}
+
+type VerificationResult struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	HashOffchain       string                 `protobuf:"bytes,2,opt,name=hash_offchain,json=hashOffchain,proto3" json:"hash_offchain,omitempty"`
+	HashOnchain        string                 `protobuf:"bytes,3,opt,name=hash_onchain,json=hashOnchain,proto3" json:"hash_onchain,omitempty"`
+	HashRecomputed     string                 `protobuf:"bytes,4,opt,name=hash_recomputed,json=hashRecomputed,proto3" json:"hash_recomputed,omitempty"`
+	HashProvided       string                 `protobuf:"bytes,5,opt,name=hash_provided,json=hashProvided,proto3" json:"hash_provided,omitempty"`
+	HashVersion        int32                  `protobuf:"varint,6,opt,name=hash_version,json=hashVersion,proto3" json:"hash_version,omitempty"`
+	IsValid            bool                   `protobuf:"varint,7,opt,name=is_valid,json=isValid,proto3" json:"is_valid,omitempty"`
+	Status             string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`
+	MetadataMismatches []*FieldMismatch       `protobuf:"bytes,9,rep,name=metadata_mismatches,json=metadataMismatches,proto3" json:"metadata_mismatches,omitempty"`
+	Details            string                 `protobuf:"bytes,10,opt,name=details,proto3" json:"details,omitempty"`
+	VerifiedAt         *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=verified_at,json=verifiedAt,proto3" json:"verified_at,omitempty"`
+	Signer             *Signer                `protobuf:"bytes,12,opt,name=signer,proto3" json:"signer,omitempty"`
+	Timestamp          *TrustedTimestamp      `protobuf:"bytes,13,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *VerificationResult) Reset() {
+	*x = VerificationResult{}
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerificationResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerificationResult) ProtoMessage() {}
+
+func (x *VerificationResult) ProtoReflect() protoreflect.Message {
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerificationResult.ProtoReflect.Descriptor instead.
+func (*VerificationResult) Descriptor() ([]byte, []int) {
+	return file_auditledger_v1_audit_ledger_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *VerificationResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *VerificationResult) GetHashOffchain() string {
+	if x != nil {
+		return x.HashOffchain
+	}
+	return ""
+}
+
+func (x *VerificationResult) GetHashOnchain() string {
+	if x != nil {
+		return x.HashOnchain
+	}
+	return ""
+}
+
+func (x *VerificationResult) GetHashRecomputed() string {
+	if x != nil {
+		return x.HashRecomputed
+	}
+	return ""
+}
+
+func (x *VerificationResult) GetHashProvided() string {
+	if x != nil {
+		return x.HashProvided
+	}
+	return ""
+}
+
+func (x *VerificationResult) GetHashVersion() int32 {
+	if x != nil {
+		return x.HashVersion
+	}
+	return 0
+}
+
+func (x *VerificationResult) GetIsValid() bool {
+	if x != nil {
+		return x.IsValid
+	}
+	return false
+}
+
+func (x *VerificationResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *VerificationResult) GetMetadataMismatches() []*FieldMismatch {
+	if x != nil {
+		return x.MetadataMismatches
+	}
+	return nil
+}
+
+func (x *VerificationResult) GetDetails() string {
+	if x != nil {
+		return x.Details
+	}
+	return ""
+}
+
+func (x *VerificationResult) GetVerifiedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.VerifiedAt
+	}
+	return nil
+}
+
+func (x *VerificationResult) GetSigner() *Signer {
+	if x != nil {
+		return x.Signer
+	}
+	return nil
+}
+
+func (x *VerificationResult) GetTimestamp() *TrustedTimestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type IngestLogsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Received      int32                  `protobuf:"varint,1,opt,name=received,proto3" json:"received,omitempty"`
+	Created       int32                  `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	Replayed      int32                  `protobuf:"varint,3,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	Failed        int32                  `protobuf:"varint,4,opt,name=failed,proto3" json:"failed,omitempty"`
+	Errors        []*IngestError         `protobuf:"bytes,5,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestLogsResponse) Reset() {
+	*x = IngestLogsResponse{}
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestLogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestLogsResponse) ProtoMessage() {}
+
+func (x *IngestLogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestLogsResponse.ProtoReflect.Descriptor instead.
+func (*IngestLogsResponse) Descriptor() ([]byte, []int) {
+	return file_auditledger_v1_audit_ledger_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *IngestLogsResponse) GetReceived() int32 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *IngestLogsResponse) GetCreated() int32 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+func (x *IngestLogsResponse) GetReplayed() int32 {
+	if x != nil {
+		return x.Replayed
+	}
+	return 0
+}
+
+func (x *IngestLogsResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *IngestLogsResponse) GetErrors() []*IngestError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type IngestError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 1-based position of the message in the stream
+	Index         int32  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestError) Reset() {
+	*x = IngestError{}
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestError) ProtoMessage() {}
+
+func (x *IngestError) ProtoReflect() protoreflect.Message {
+	mi := &file_auditledger_v1_audit_ledger_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestError.ProtoReflect.Descriptor instead.
+func (*IngestError) Descriptor() ([]byte, []int) {
+	return file_auditledger_v1_audit_ledger_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *IngestError) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *IngestError) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_auditledger_v1_audit_ledger_proto protoreflect.FileDescriptor
+
+const file_auditledger_v1_audit_ledger_proto_rawDesc = "" +
+	"\n" +
+	"!auditledger/v1/audit_ledger.proto\x12\x0eauditledger.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd8\x01\n" +
+	"\x10CreateLogRequest\x12\x15\n" +
+	"\x06log_id\x18\x01 \x01(\tR\x05logId\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x03 \x01(\tR\teventType\x12\x18\n" +
+	"\apayload\x18\x04 \x01(\fR\apayload\x12'\n" +
+	"\x0fidempotency_key\x18\x05 \x01(\tR\x0eidempotencyKey\x12\x1c\n" +
+	"\tsignature\x18\x06 \x01(\tR\tsignature\x12\x15\n" +
+	"\x06key_id\x18\a \x01(\tR\x05keyId\"p\n" +
+	"\x11CreateLogResponse\x12%\n" +
+	"\x03log\x18\x01 \x01(\v2\x13.auditledger.v1.LogR\x03log\x12\x1a\n" +
+	"\breplayed\x18\x02 \x01(\bR\breplayed\x12\x18\n" +
+	"\areceipt\x18\x03 \x01(\tR\areceipt\"\xeb\x04\n" +
+	"\x03Log\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x04 \x01(\tR\teventType\x12\x18\n" +
+	"\apayload\x18\x05 \x01(\fR\apayload\x12\x12\n" +
+	"\x04hash\x18\x06 \x01(\tR\x04hash\x12!\n" +
+	"\fcontent_hash\x18\a \x01(\tR\vcontentHash\x12!\n" +
+	"\fhash_version\x18\b \x01(\x05R\vhashVersion\x12\x1f\n" +
+	"\bsequence\x18\t \x01(\x03H\x00R\bsequence\x88\x01\x01\x12\x1b\n" +
+	"\tprev_hash\x18\n" +
+	" \x01(\tR\bprevHash\x12\x13\n" +
+	"\x05tx_id\x18\v \x01(\tR\x04txId\x12=\n" +
+	"\fcommitted_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\vcommittedAt\x12\x19\n" +
+	"\bbatch_id\x18\r \x01(\tR\abatchId\x12\"\n" +
+	"\n" +
+	"leaf_index\x18\x0e \x01(\x05H\x01R\tleafIndex\x88\x01\x01\x12\x1c\n" +
+	"\tsignature\x18\x0f \x01(\tR\tsignature\x12(\n" +
+	"\x10signature_key_id\x18\x10 \x01(\tR\x0esignatureKeyId\x129\n" +
+	"\n" +
+	"event_time\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\teventTimeB\v\n" +
+	"\t_sequenceB\r\n" +
+	"\v_leaf_index\"\x1f\n" +
+	"\rGetLogRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"y\n" +
+	"\x0fListLogsRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x04 \x01(\tR\teventType\"\xa3\x01\n" +
+	"\x10ListLogsResponse\x12'\n" +
+	"\x04logs\x18\x01 \x03(\v2\x13.auditledger.v1.LogR\x04logs\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\x12\x1f\n" +
+	"\vtotal_pages\x18\x05 \x01(\x05R\n" +
+	"totalPages\"`\n" +
+	"\x10VerifyLogRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x04hash\x18\x02 \x01(\tH\x00R\x04hash\x12\x1a\n" +
+	"\apayload\x18\x03 \x01(\fH\x00R\apayloadB\n" +
+	"\n" +
+	"\bprovided\"[\n" +
+	"\rFieldMismatch\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x1a\n" +
+	"\boffchain\x18\x02 \x01(\tR\boffchain\x12\x18\n" +
+	"\aonchain\x18\x03 \x01(\tR\aonchain\"\xa7\x01\n" +
+	"\x06Signer\x12\x15\n" +
+	"\x06key_id\x18\x01 \x01(\tR\x05keyId\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x1c\n" +
+	"\talgorithm\x18\x03 \x01(\tR\talgorithm\x12 \n" +
+	"\vfingerprint\x18\x04 \x01(\tR\vfingerprint\x12\x14\n" +
+	"\x05valid\x18\x05 \x01(\bR\x05valid\x12\x18\n" +
+	"\adetails\x18\x06 \x01(\tR\adetails\"\x83\x02\n" +
+	"\x10TrustedTimestamp\x125\n" +
+	"\bgen_time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\agenTime\x12#\n" +
+	"\rserial_number\x18\x02 \x01(\tR\fserialNumber\x12\x16\n" +
+	"\x06policy\x18\x03 \x01(\tR\x06policy\x12\x1c\n" +
+	"\tauthority\x18\x04 \x01(\tR\tauthority\x12\x12\n" +
+	"\x04hash\x18\x05 \x01(\tR\x04hash\x12\x19\n" +
+	"\bbatch_id\x18\x06 \x01(\tR\abatchId\x12\x14\n" +
+	"\x05valid\x18\a \x01(\bR\x05valid\x12\x18\n" +
+	"\adetails\x18\b \x01(\tR\adetails\"\xa7\x04\n" +
+	"\x12VerificationResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
+	"\rhash_offchain\x18\x02 \x01(\tR\fhashOffchain\x12!\n" +
+	"\fhash_onchain\x18\x03 \x01(\tR\vhashOnchain\x12'\n" +
+	"\x0fhash_recomputed\x18\x04 \x01(\tR\x0ehashRecomputed\x12#\n" +
+	"\rhash_provided\x18\x05 \x01(\tR\fhashProvided\x12!\n" +
+	"\fhash_version\x18\x06 \x01(\x05R\vhashVersion\x12\x19\n" +
+	"\bis_valid\x18\a \x01(\bR\aisValid\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x12N\n" +
+	"\x13metadata_mismatches\x18\t \x03(\v2\x1d.auditledger.v1.FieldMismatchR\x12metadataMismatches\x12\x18\n" +
+	"\adetails\x18\n" +
+	" \x01(\tR\adetails\x12;\n" +
+	"\vverified_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"verifiedAt\x12.\n" +
+	"\x06signer\x18\f \x01(\v2\x16.auditledger.v1.SignerR\x06signer\x12>\n" +
+	"\ttimestamp\x18\r \x01(\v2 .auditledger.v1.TrustedTimestampR\ttimestamp\"\xb3\x01\n" +
+	"\x12IngestLogsResponse\x12\x1a\n" +
+	"\breceived\x18\x01 \x01(\x05R\breceived\x12\x18\n" +
+	"\acreated\x18\x02 \x01(\x05R\acreated\x12\x1a\n" +
+	"\breplayed\x18\x03 \x01(\x05R\breplayed\x12\x16\n" +
+	"\x06failed\x18\x04 \x01(\x05R\x06failed\x123\n" +
+	"\x06errors\x18\x05 \x03(\v2\x1b.auditledger.v1.IngestErrorR\x06errors\"9\n" +
+	"\vIngestError\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error2\x9c\x03\n" +
+	"\x12AuditLedgerService\x12P\n" +
+	"\tCreateLog\x12 .auditledger.v1.CreateLogRequest\x1a!.auditledger.v1.CreateLogResponse\x12<\n" +
+	"\x06GetLog\x12\x1d.auditledger.v1.GetLogRequest\x1a\x13.auditledger.v1.Log\x12M\n" +
+	"\bListLogs\x12\x1f.auditledger.v1.ListLogsRequest\x1a .auditledger.v1.ListLogsResponse\x12Q\n" +
+	"\tVerifyLog\x12 .auditledger.v1.VerifyLogRequest\x1a\".auditledger.v1.VerificationResult\x12T\n" +
+	"\n" +
+	"IngestLogs\x12 .auditledger.v1.CreateLogRequest\x1a\".auditledger.v1.IngestLogsResponse(\x01BLZJgithub.com/banking-audit-ledger/backend/proto/auditledger/v1;auditledgerv1b\x06proto3"
+
+var (
+	file_auditledger_v1_audit_ledger_proto_rawDescOnce sync.Once
+	file_auditledger_v1_audit_ledger_proto_rawDescData []byte
+)
+
+func file_auditledger_v1_audit_ledger_proto_rawDescGZIP() []byte {
+	file_auditledger_v1_audit_ledger_proto_rawDescOnce.Do(func() {
+		file_auditledger_v1_audit_ledger_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_auditledger_v1_audit_ledger_proto_rawDesc), len(file_auditledger_v1_audit_ledger_proto_rawDesc)))
+	})
+	return file_auditledger_v1_audit_ledger_proto_rawDescData
+}
+
+var file_auditledger_v1_audit_ledger_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_auditledger_v1_audit_ledger_proto_goTypes = []any{
+	(*CreateLogRequest)(nil),      // 0: auditledger.v1.CreateLogRequest
+	(*CreateLogResponse)(nil),     // 1: auditledger.v1.CreateLogResponse
+	(*Log)(nil),                   // 2: auditledger.v1.Log
+	(*GetLogRequest)(nil),         // 3: auditledger.v1.GetLogRequest
+	(*ListLogsRequest)(nil),       // 4: auditledger.v1.ListLogsRequest
+	(*ListLogsResponse)(nil),      // 5: auditledger.v1.ListLogsResponse
+	(*VerifyLogRequest)(nil),      // 6: auditledger.v1.VerifyLogRequest
+	(*FieldMismatch)(nil),         // 7: auditledger.v1.FieldMismatch
+	(*Signer)(nil),                // 8: auditledger.v1.Signer
+	(*TrustedTimestamp)(nil),      // 9: auditledger.v1.TrustedTimestamp
+	(*VerificationResult)(nil),    // 10: auditledger.v1.VerificationResult
+	(*IngestLogsResponse)(nil),    // 11: auditledger.v1.IngestLogsResponse
+	(*IngestError)(nil),           // 12: auditledger.v1.IngestError
+	(*timestamppb.Timestamp)(nil), // 13: google.protobuf.Timestamp
+}
+var file_auditledger_v1_audit_ledger_proto_depIdxs = []int32{
+	2,  // 0: auditledger.v1.CreateLogResponse.log:type_name -> auditledger.v1.Log
+	13, // 1: auditledger.v1.Log.created_at:type_name -> google.protobuf.Timestamp
+	13, // 2: auditledger.v1.Log.committed_at:type_name -> google.protobuf.Timestamp
+	13, // 3: auditledger.v1.Log.event_time:type_name -> google.protobuf.Timestamp
+	2,  // 4: auditledger.v1.ListLogsResponse.logs:type_name -> auditledger.v1.Log
+	13, // 5: auditledger.v1.TrustedTimestamp.gen_time:type_name -> google.protobuf.Timestamp
+	7,  // 6: auditledger.v1.VerificationResult.metadata_mismatches:type_name -> auditledger.v1.FieldMismatch
+	13, // 7: auditledger.v1.VerificationResult.verified_at:type_name -> google.protobuf.Timestamp
+	8,  // 8: auditledger.v1.VerificationResult.signer:type_name -> auditledger.v1.Signer
+	9,  // 9: auditledger.v1.VerificationResult.timestamp:type_name -> auditledger.v1.TrustedTimestamp
+	12, // 10: auditledger.v1.IngestLogsResponse.errors:type_name -> auditledger.v1.IngestError
+	0,  // 11: auditledger.v1.AuditLedgerService.CreateLog:input_type -> auditledger.v1.CreateLogRequest
+	3,  // 12: auditledger.v1.AuditLedgerService.GetLog:input_type -> auditledger.v1.GetLogRequest
+	4,  // 13: auditledger.v1.AuditLedgerService.ListLogs:input_type -> auditledger.v1.ListLogsRequest
+	6,  // 14: auditledger.v1.AuditLedgerService.VerifyLog:input_type -> auditledger.v1.VerifyLogRequest
+	0,  // 15: auditledger.v1.AuditLedgerService.IngestLogs:input_type -> auditledger.v1.CreateLogRequest
+	1,  // 16: auditledger.v1.AuditLedgerService.CreateLog:output_type -> auditledger.v1.CreateLogResponse
+	2,  // 17: auditledger.v1.AuditLedgerService.GetLog:output_type -> auditledger.v1.Log
+	5,  // 18: auditledger.v1.AuditLedgerService.ListLogs:output_type -> auditledger.v1.ListLogsResponse
+	10, // 19: auditledger.v1.AuditLedgerService.VerifyLog:output_type -> auditledger.v1.VerificationResult
+	11, // 20: auditledger.v1.AuditLedgerService.IngestLogs:output_type -> auditledger.v1.IngestLogsResponse
+	16, // [16:21] is the sub-list for method output_type
+	11, // [11:16] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_auditledger_v1_audit_ledger_proto_init() }
+func file_auditledger_v1_audit_ledger_proto_init() {
+	if File_auditledger_v1_audit_ledger_proto != nil {
+		return
+	}
+	file_auditledger_v1_audit_ledger_proto_msgTypes[2].OneofWrappers = []any{}
+	file_auditledger_v1_audit_ledger_proto_msgTypes[6].OneofWrappers = []any{
+		(*VerifyLogRequest_Hash)(nil),
+		(*VerifyLogRequest_Payload)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_auditledger_v1_audit_ledger_proto_rawDesc), len(file_auditledger_v1_audit_ledger_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_auditledger_v1_audit_ledger_proto_goTypes,
+		DependencyIndexes: file_auditledger_v1_audit_ledger_proto_depIdxs,
+		MessageInfos:      file_auditledger_v1_audit_ledger_proto_msgTypes,
+	}.Build()
+	File_auditledger_v1_audit_ledger_proto = out.File
+	file_auditledger_v1_audit_ledger_proto_goTypes = nil
+	file_auditledger_v1_audit_ledger_proto_depIdxs = nil
+}
@@ -0,0 +1,285 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: auditledger/v1/audit_ledger.proto
+
+package auditledgerv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AuditLedgerService_CreateLog_FullMethodName  = "/auditledger.v1.AuditLedgerService/CreateLog"
+	AuditLedgerService_GetLog_FullMethodName     = "/auditledger.v1.AuditLedgerService/GetLog"
+	AuditLedgerService_ListLogs_FullMethodName   = "/auditledger.v1.AuditLedgerService/ListLogs"
+	AuditLedgerService_VerifyLog_FullMethodName  = "/auditledger.v1.AuditLedgerService/VerifyLog"
+	AuditLedgerService_IngestLogs_FullMethodName = "/auditledger.v1.AuditLedgerService/IngestLogs"
+)
+
+// AuditLedgerServiceClient is the client API for AuditLedgerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AuditLedgerService exposes log ingestion, retrieval and verification over
+// gRPC. It is backed by the same services as the REST API.
+type AuditLedgerServiceClient interface {
+	// CreateLog creates a new audit log and anchors its hash
+	CreateLog(ctx context.Context, in *CreateLogRequest, opts ...grpc.CallOption) (*CreateLogResponse, error)
+	// GetLog retrieves a log by ID
+	GetLog(ctx context.Context, in *GetLogRequest, opts ...grpc.CallOption) (*Log, error)
+	// ListLogs lists logs with pagination and filters
+	ListLogs(ctx context.Context, in *ListLogsRequest, opts ...grpc.CallOption) (*ListLogsResponse, error)
+	// VerifyLog verifies a log against the ledger. With a hash or payload it
+	// checks the caller's copy instead of the stored one.
+	VerifyLog(ctx context.Context, in *VerifyLogRequest, opts ...grpc.CallOption) (*VerificationResult, error)
+	// IngestLogs creates logs sent as a stream, in chunks through the batch path
+	IngestLogs(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[CreateLogRequest, IngestLogsResponse], error)
+}
+
+type auditLedgerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAuditLedgerServiceClient(cc grpc.ClientConnInterface) AuditLedgerServiceClient {
+	return &auditLedgerServiceClient{cc}
+}
+
+func (c *auditLedgerServiceClient) CreateLog(ctx context.Context, in *CreateLogRequest, opts ...grpc.CallOption) (*CreateLogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateLogResponse)
+	err := c.cc.Invoke(ctx, AuditLedgerService_CreateLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auditLedgerServiceClient) GetLog(ctx context.Context, in *GetLogRequest, opts ...grpc.CallOption) (*Log, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Log)
+	err := c.cc.Invoke(ctx, AuditLedgerService_GetLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auditLedgerServiceClient) ListLogs(ctx context.Context, in *ListLogsRequest, opts ...grpc.CallOption) (*ListLogsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLogsResponse)
+	err := c.cc.Invoke(ctx, AuditLedgerService_ListLogs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auditLedgerServiceClient) VerifyLog(ctx context.Context, in *VerifyLogRequest, opts ...grpc.CallOption) (*VerificationResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerificationResult)
+	err := c.cc.Invoke(ctx, AuditLedgerService_VerifyLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auditLedgerServiceClient) IngestLogs(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[CreateLogRequest, IngestLogsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AuditLedgerService_ServiceDesc.Streams[0], AuditLedgerService_IngestLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CreateLogRequest, IngestLogsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AuditLedgerService_IngestLogsClient = grpc.ClientStreamingClient[CreateLogRequest, IngestLogsResponse]
+
+// AuditLedgerServiceServer is the server API for AuditLedgerService service.
+// All implementations must embed UnimplementedAuditLedgerServiceServer
+// for forward compatibility.
+//
+// AuditLedgerService exposes log ingestion, retrieval and verification over
+// gRPC. It is backed by the same services as the REST API.
+type AuditLedgerServiceServer interface {
+	// CreateLog creates a new audit log and anchors its hash
+	CreateLog(context.Context, *CreateLogRequest) (*CreateLogResponse, error)
+	// GetLog retrieves a log by ID
+	GetLog(context.Context, *GetLogRequest) (*Log, error)
+	// ListLogs lists logs with pagination and filters
+	ListLogs(context.Context, *ListLogsRequest) (*ListLogsResponse, error)
+	// VerifyLog verifies a log against the ledger. With a hash or payload it
+	// checks the caller's copy instead of the stored one.
+	VerifyLog(context.Context, *VerifyLogRequest) (*VerificationResult, error)
+	// IngestLogs creates logs sent as a stream, in chunks through the batch path
+	IngestLogs(grpc.ClientStreamingServer[CreateLogRequest, IngestLogsResponse]) error
+	mustEmbedUnimplementedAuditLedgerServiceServer()
+}
+
+// UnimplementedAuditLedgerServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAuditLedgerServiceServer struct{}
+
+func (UnimplementedAuditLedgerServiceServer) CreateLog(context.Context, *CreateLogRequest) (*CreateLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateLog not implemented")
+}
+func (UnimplementedAuditLedgerServiceServer) GetLog(context.Context, *GetLogRequest) (*Log, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLog not implemented")
+}
+func (UnimplementedAuditLedgerServiceServer) ListLogs(context.Context, *ListLogsRequest) (*ListLogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLogs not implemented")
+}
+func (UnimplementedAuditLedgerServiceServer) VerifyLog(context.Context, *VerifyLogRequest) (*VerificationResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyLog not implemented")
+}
+func (UnimplementedAuditLedgerServiceServer) IngestLogs(grpc.ClientStreamingServer[CreateLogRequest, IngestLogsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method IngestLogs not implemented")
+}
+func (UnimplementedAuditLedgerServiceServer) mustEmbedUnimplementedAuditLedgerServiceServer() {}
+func (UnimplementedAuditLedgerServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeAuditLedgerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AuditLedgerServiceServer will
+// result in compilation errors.
+type UnsafeAuditLedgerServiceServer interface {
+	mustEmbedUnimplementedAuditLedgerServiceServer()
+}
+
+func RegisterAuditLedgerServiceServer(s grpc.ServiceRegistrar, srv AuditLedgerServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAuditLedgerServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AuditLedgerService_ServiceDesc, srv)
+}
+
+func _AuditLedgerService_CreateLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuditLedgerServiceServer).CreateLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuditLedgerService_CreateLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuditLedgerServiceServer).CreateLog(ctx, req.(*CreateLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuditLedgerService_GetLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuditLedgerServiceServer).GetLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuditLedgerService_GetLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuditLedgerServiceServer).GetLog(ctx, req.(*GetLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuditLedgerService_ListLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuditLedgerServiceServer).ListLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuditLedgerService_ListLogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuditLedgerServiceServer).ListLogs(ctx, req.(*ListLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuditLedgerService_VerifyLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuditLedgerServiceServer).VerifyLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuditLedgerService_VerifyLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuditLedgerServiceServer).VerifyLog(ctx, req.(*VerifyLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuditLedgerService_IngestLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AuditLedgerServiceServer).IngestLogs(&grpc.GenericServerStream[CreateLogRequest, IngestLogsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AuditLedgerService_IngestLogsServer = grpc.ClientStreamingServer[CreateLogRequest, IngestLogsResponse]
+
+// AuditLedgerService_ServiceDesc is the grpc.ServiceDesc for AuditLedgerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AuditLedgerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "auditledger.v1.AuditLedgerService",
+	HandlerType: (*AuditLedgerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateLog",
+			Handler:    _AuditLedgerService_CreateLog_Handler,
+		},
+		{
+			MethodName: "GetLog",
+			Handler:    _AuditLedgerService_GetLog_Handler,
+		},
+		{
+			MethodName: "ListLogs",
+			Handler:    _AuditLedgerService_ListLogs_Handler,
+		},
+		{
+			MethodName: "VerifyLog",
+			Handler:    _AuditLedgerService_VerifyLog_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "IngestLogs",
+			Handler:       _AuditLedgerService_IngestLogs_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "auditledger/v1/audit_ledger.proto",
+}
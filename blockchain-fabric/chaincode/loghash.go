@@ -24,6 +24,24 @@ type LogHash struct {
 	Metadata  map[string]string `json:"metadata"`
 }
 
+// BatchRoot represents a Merkle root anchoring a batch of log hashes
+type BatchRoot struct {
+	BatchID   string            `json:"batchID"`
+	Root      string            `json:"root"`
+	LeafCount int               `json:"leafCount"`
+	TxID      string            `json:"txID"`
+	Timestamp string            `json:"timestamp"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
+// batchKeyPrefix is the composite key object type for batch roots, keeping
+// them out of the logID key space
+const batchKeyPrefix = "batch"
+
+// hashIndexPrefix is the composite key object type of the reverse index from
+// a hash to the logIDs anchored with it
+const hashIndexPrefix = "hash~logID"
+
 // Init is called during chaincode instantiation to initialize any
 // data. Note that chaincode upgrade also calls this function to reset
 // or to migrate data.
@@ -46,6 +64,12 @@ func (s *LogHashContract) Invoke(stub shim.ChaincodeStubInterface) peer.Response
 		result, err = s.GetLogHash(stub, args)
 	} else if fn == "VerifyLogHash" {
 		result, err = s.VerifyLogHash(stub, args)
+	} else if fn == "GetLogHashesByHash" {
+		result, err = s.GetLogHashesByHash(stub, args)
+	} else if fn == "CommitBatchRoot" {
+		result, err = s.CommitBatchRoot(stub, args)
+	} else if fn == "GetBatchRoot" {
+		result, err = s.GetBatchRoot(stub, args)
 	} else if fn == "ComputeHash" {
 		result, err = s.ComputeHash(stub, args)
 	} else if fn == "ListAllKeys" {
@@ -71,9 +95,6 @@ func (s *LogHashContract) CommitLogHash(stub shim.ChaincodeStubInterface, args [
 	logID := args[0]
 	hash := args[1]
 	metadataJSON := args[2]
-	
-	// Debug logging
-	fmt.Printf("CommitLogHash called with logID: %s, hash: %s\n", logID, hash)
 
 	// Validate inputs
 	if logID == "" || hash == "" {
@@ -93,15 +114,28 @@ func (s *LogHashContract) CommitLogHash(stub shim.ChaincodeStubInterface, args [
 		}
 	}
 
-	// Get transaction ID
+	// A log hash is committed once and never replaced
+	existing, err := stub.GetState(logID)
+	if err != nil {
+		return "", fmt.Errorf("failed to read log hash from world state: %v", err)
+	}
+	if existing != nil {
+		return "", fmt.Errorf("log hash %s already exists", logID)
+	}
+
+	// Get transaction ID and timestamp
 	txID := stub.GetTxID()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return "", err
+	}
 
 	// Create log hash entry
 	logHash := LogHash{
 		LogID:     logID,
 		Hash:      hash,
 		TxID:      txID,
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Timestamp: timestamp,
 		Metadata:  metadata,
 	}
 
@@ -117,7 +151,21 @@ func (s *LogHashContract) CommitLogHash(stub shim.ChaincodeStubInterface, args [
 		return "", fmt.Errorf("failed to put log hash to world state: %v", err)
 	}
 
-	fmt.Printf("Log hash committed with key: %s, value: %s\n", logID, string(logHashJSON))
+	// Index the log under its hash and, when provided, the hash of its payload
+	// alone, so it can be found without knowing its logID
+	indexed := []string{hash}
+	if contentHash := metadata["content_hash"]; contentHash != "" && contentHash != hash {
+		indexed = append(indexed, contentHash)
+	}
+	for _, h := range indexed {
+		indexKey, err := stub.CreateCompositeKey(hashIndexPrefix, []string{h, logID})
+		if err != nil {
+			return "", fmt.Errorf("failed to create hash index key: %v", err)
+		}
+		if err := stub.PutState(indexKey, []byte{0x00}); err != nil {
+			return "", fmt.Errorf("failed to put hash index to world state: %v", err)
+		}
+	}
 
 	// Emit event
 	eventPayload := fmt.Sprintf("LogHash committed: %s", logID)
@@ -136,9 +184,6 @@ func (s *LogHashContract) GetLogHash(stub shim.ChaincodeStubInterface, args []st
 	}
 
 	logID := args[0]
-	
-	// Debug logging
-	fmt.Printf("GetLogHash called with logID: %s\n", logID)
 
 	// Get log hash from world state
 	logHashJSON, err := stub.GetState(logID)
@@ -147,11 +192,9 @@ func (s *LogHashContract) GetLogHash(stub shim.ChaincodeStubInterface, args []st
 	}
 
 	if logHashJSON == nil {
-		fmt.Printf("Log hash %s does not exist in world state\n", logID)
 		return "", fmt.Errorf("log hash %s does not exist", logID)
 	}
 
-	fmt.Printf("Log hash %s found: %s\n", logID, string(logHashJSON))
 	return string(logHashJSON), nil
 }
 
@@ -186,6 +229,181 @@ func (s *LogHashContract) VerifyLogHash(stub shim.ChaincodeStubInterface, args [
 	return strconv.FormatBool(isValid), nil
 }
 
+// GetLogHashesByHash retrieves every log hash entry anchored with the given
+// hash or payload hash
+func (s *LogHashContract) GetLogHashesByHash(stub shim.ChaincodeStubInterface, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("Incorrect arguments. Expecting: hash")
+	}
+
+	hash := args[0]
+
+	iterator, err := stub.GetStateByPartialCompositeKey(hashIndexPrefix, []string{hash})
+	if err != nil {
+		return "", fmt.Errorf("failed to get hash index iterator: %v", err)
+	}
+	defer iterator.Close()
+
+	logHashes := []LogHash{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return "", fmt.Errorf("failed to get next index entry: %v", err)
+		}
+
+		_, attributes, err := stub.SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(attributes) != 2 {
+			return "", fmt.Errorf("invalid hash index key: %s", queryResponse.Key)
+		}
+		logID := attributes[1]
+
+		logHashJSON, err := stub.GetState(logID)
+		if err != nil {
+			return "", fmt.Errorf("failed to read log hash from world state: %v", err)
+		}
+		if logHashJSON == nil {
+			continue
+		}
+
+		var logHash LogHash
+		if err := json.Unmarshal(logHashJSON, &logHash); err != nil {
+			return "", fmt.Errorf("failed to unmarshal log hash: %v", err)
+		}
+
+		// A log committed again with a different hash leaves a stale index entry
+		if logHash.Hash != hash && logHash.Metadata["content_hash"] != hash {
+			continue
+		}
+		logHashes = append(logHashes, logHash)
+	}
+
+	logHashesJSON, err := json.Marshal(logHashes)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal log hashes: %v", err)
+	}
+
+	return string(logHashesJSON), nil
+}
+
+// CommitBatchRoot commits the Merkle root of a batch of log hashes to the blockchain
+func (s *LogHashContract) CommitBatchRoot(stub shim.ChaincodeStubInterface, args []string) (string, error) {
+	if len(args) != 4 {
+		return "", fmt.Errorf("Incorrect arguments. Expecting: batchID, root, leafCount, metadataJSON")
+	}
+
+	batchID := args[0]
+	root := args[1]
+	metadataJSON := args[3]
+
+	// Validate inputs
+	if batchID == "" || root == "" {
+		return "", fmt.Errorf("batchID and root cannot be empty")
+	}
+
+	// Validate root format (should be SHA256 hex string)
+	if len(root) != 64 {
+		return "", fmt.Errorf("invalid root format, expected SHA256 hex string")
+	}
+
+	leafCount, err := strconv.Atoi(args[2])
+	if err != nil || leafCount < 1 {
+		return "", fmt.Errorf("invalid leaf count: %s", args[2])
+	}
+
+	// Parse metadata
+	var metadata map[string]string
+	if metadataJSON != "" {
+		if err := json.Unmarshal([]byte(metadataJSON), &metadata); err != nil {
+			return "", fmt.Errorf("invalid metadata JSON: %v", err)
+		}
+	}
+
+	key, err := stub.CreateCompositeKey(batchKeyPrefix, []string{batchID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create batch key: %v", err)
+	}
+
+	// A batch root is anchored once and never replaced
+	existing, err := stub.GetState(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read batch root from world state: %v", err)
+	}
+	if existing != nil {
+		return "", fmt.Errorf("batch root %s already exists", batchID)
+	}
+
+	// Get transaction ID and timestamp
+	txID := stub.GetTxID()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return "", err
+	}
+
+	batchRoot := BatchRoot{
+		BatchID:   batchID,
+		Root:      root,
+		LeafCount: leafCount,
+		TxID:      txID,
+		Timestamp: timestamp,
+		Metadata:  metadata,
+	}
+
+	batchRootJSON, err := json.Marshal(batchRoot)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal batch root: %v", err)
+	}
+
+	// Store in world state
+	err = stub.PutState(key, batchRootJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put batch root to world state: %v", err)
+	}
+
+	// Emit event
+	eventPayload := fmt.Sprintf("BatchRoot committed: %s", batchID)
+	err = stub.SetEvent("BatchRootCommitted", []byte(eventPayload))
+	if err != nil {
+		return "", fmt.Errorf("failed to emit event: %v", err)
+	}
+
+	return txID, nil
+}
+
+// GetBatchRoot retrieves a batch Merkle root from the blockchain
+func (s *LogHashContract) GetBatchRoot(stub shim.ChaincodeStubInterface, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("Incorrect arguments. Expecting: batchID")
+	}
+
+	batchID := args[0]
+
+	key, err := stub.CreateCompositeKey(batchKeyPrefix, []string{batchID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create batch key: %v", err)
+	}
+
+	batchRootJSON, err := stub.GetState(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read batch root from world state: %v", err)
+	}
+
+	if batchRootJSON == nil {
+		return "", fmt.Errorf("batch root %s does not exist", batchID)
+	}
+
+	return string(batchRootJSON), nil
+}
+
+// txTimestamp returns the proposal timestamp of the transaction. Unlike the
+// peer's clock it is the same on every endorser, so their write sets agree.
+func txTimestamp(stub shim.ChaincodeStubInterface) (string, error) {
+	ts, err := stub.GetTxTimestamp()
+	if err != nil {
+		return "", fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC().Format(time.RFC3339), nil
+}
+
 // ComputeHash computes SHA256 hash of provided data
 func (s *LogHashContract) ComputeHash(stub shim.ChaincodeStubInterface, args []string) (string, error) {
 	if len(args) != 1 {
@@ -24,6 +24,24 @@ type LogHash struct {
 	Metadata  map[string]string `json:"metadata"`
 }
 
+// BatchRoot represents a Merkle root anchoring a batch of log hashes
+type BatchRoot struct {
+	BatchID   string            `json:"batchID"`
+	Root      string            `json:"root"`
+	LeafCount int               `json:"leafCount"`
+	TxID      string            `json:"txID"`
+	Timestamp string            `json:"timestamp"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
+// batchKeyPrefix is the composite key object type for batch roots, keeping
+// them out of the logID key space
+const batchKeyPrefix = "batch"
+
+// hashIndexPrefix is the composite key object type of the reverse index from
+// a hash to the logIDs anchored with it
+const hashIndexPrefix = "hash~logID"
+
 // CommitLogHash commits a log hash to the blockchain
 func (s *LogHashContract) CommitLogHash(ctx contractapi.TransactionContextInterface, logID string, hash string, metadataJSON string) error {
 	// Validate inputs
@@ -44,15 +62,28 @@ func (s *LogHashContract) CommitLogHash(ctx contractapi.TransactionContextInterf
 		}
 	}
 
-	// Get transaction ID
+	// A log hash is committed once and never replaced
+	existing, err := ctx.GetStub().GetState(logID)
+	if err != nil {
+		return fmt.Errorf("failed to read log hash from world state: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("log hash %s already exists", logID)
+	}
+
+	// Get transaction ID and timestamp
 	txID := ctx.GetStub().GetTxID()
+	timestamp, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
 
 	// Create log hash entry
 	logHash := LogHash{
 		LogID:     logID,
 		Hash:      hash,
 		TxID:      txID,
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Timestamp: timestamp,
 		Metadata:  metadata,
 	}
 
@@ -68,6 +99,22 @@ func (s *LogHashContract) CommitLogHash(ctx contractapi.TransactionContextInterf
 		return fmt.Errorf("failed to put log hash to world state: %v", err)
 	}
 
+	// Index the log under its hash and, when provided, the hash of its payload
+	// alone, so it can be found without knowing its logID
+	indexed := []string{hash}
+	if contentHash := metadata["content_hash"]; contentHash != "" && contentHash != hash {
+		indexed = append(indexed, contentHash)
+	}
+	for _, h := range indexed {
+		indexKey, err := ctx.GetStub().CreateCompositeKey(hashIndexPrefix, []string{h, logID})
+		if err != nil {
+			return fmt.Errorf("failed to create hash index key: %v", err)
+		}
+		if err := ctx.GetStub().PutState(indexKey, []byte{0x00}); err != nil {
+			return fmt.Errorf("failed to put hash index to world state: %v", err)
+		}
+	}
+
 	// Emit event
 	eventPayload := fmt.Sprintf("LogHash committed: %s", logID)
 	err = ctx.GetStub().SetEvent("LogHashCommitted", []byte(eventPayload))
@@ -112,6 +159,50 @@ func (s *LogHashContract) VerifyLogHash(ctx contractapi.TransactionContextInterf
 	return logHash.Hash == providedHash, nil
 }
 
+// GetLogHashesByHash retrieves every log hash entry anchored with the given
+// hash or payload hash
+func (s *LogHashContract) GetLogHashesByHash(ctx contractapi.TransactionContextInterface, hash string) ([]*LogHash, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(hashIndexPrefix, []string{hash})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hash index iterator: %v", err)
+	}
+	defer iterator.Close()
+
+	logHashes := []*LogHash{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get next index entry: %v", err)
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(attributes) != 2 {
+			return nil, fmt.Errorf("invalid hash index key: %s", queryResponse.Key)
+		}
+
+		logHashJSON, err := ctx.GetStub().GetState(attributes[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read log hash from world state: %v", err)
+		}
+		if logHashJSON == nil {
+			continue
+		}
+
+		var logHash LogHash
+		if err := json.Unmarshal(logHashJSON, &logHash); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal log hash: %v", err)
+		}
+
+		// A log committed again with a different hash leaves a stale index entry
+		if logHash.Hash != hash && logHash.Metadata["content_hash"] != hash {
+			continue
+		}
+		logHashes = append(logHashes, &logHash)
+	}
+
+	return logHashes, nil
+}
+
 // GetAllLogHashes retrieves all log hashes (for debugging/admin purposes)
 func (s *LogHashContract) GetAllLogHashes(ctx contractapi.TransactionContextInterface) ([]*LogHash, error) {
 	// Create range query for all log hashes
@@ -169,6 +260,116 @@ func (s *LogHashContract) GetLogHashHistory(ctx contractapi.TransactionContextIn
 	return history, nil
 }
 
+// CommitBatchRoot commits the Merkle root of a batch of log hashes to the blockchain
+func (s *LogHashContract) CommitBatchRoot(ctx contractapi.TransactionContextInterface, batchID string, root string, leafCountArg string, metadataJSON string) (string, error) {
+	// Validate inputs
+	if batchID == "" || root == "" {
+		return "", fmt.Errorf("batchID and root cannot be empty")
+	}
+
+	// Validate root format (should be SHA256 hex string)
+	if len(root) != 64 {
+		return "", fmt.Errorf("invalid root format, expected SHA256 hex string")
+	}
+
+	leafCount, err := strconv.Atoi(leafCountArg)
+	if err != nil || leafCount < 1 {
+		return "", fmt.Errorf("invalid leaf count: %s", leafCountArg)
+	}
+
+	// Parse metadata
+	var metadata map[string]string
+	if metadataJSON != "" {
+		if err := json.Unmarshal([]byte(metadataJSON), &metadata); err != nil {
+			return "", fmt.Errorf("invalid metadata JSON: %v", err)
+		}
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(batchKeyPrefix, []string{batchID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create batch key: %v", err)
+	}
+
+	// A batch root is anchored once and never replaced
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read batch root from world state: %v", err)
+	}
+	if existing != nil {
+		return "", fmt.Errorf("batch root %s already exists", batchID)
+	}
+
+	// Get transaction ID and timestamp
+	txID := ctx.GetStub().GetTxID()
+	timestamp, err := txTimestamp(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	batchRoot := BatchRoot{
+		BatchID:   batchID,
+		Root:      root,
+		LeafCount: leafCount,
+		TxID:      txID,
+		Timestamp: timestamp,
+		Metadata:  metadata,
+	}
+
+	batchRootJSON, err := json.Marshal(batchRoot)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal batch root: %v", err)
+	}
+
+	// Store in world state
+	err = ctx.GetStub().PutState(key, batchRootJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put batch root to world state: %v", err)
+	}
+
+	// Emit event
+	eventPayload := fmt.Sprintf("BatchRoot committed: %s", batchID)
+	err = ctx.GetStub().SetEvent("BatchRootCommitted", []byte(eventPayload))
+	if err != nil {
+		return "", fmt.Errorf("failed to emit event: %v", err)
+	}
+
+	return txID, nil
+}
+
+// GetBatchRoot retrieves a batch Merkle root from the blockchain
+func (s *LogHashContract) GetBatchRoot(ctx contractapi.TransactionContextInterface, batchID string) (*BatchRoot, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(batchKeyPrefix, []string{batchID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch key: %v", err)
+	}
+
+	batchRootJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch root from world state: %v", err)
+	}
+
+	if batchRootJSON == nil {
+		return nil, fmt.Errorf("batch root %s does not exist", batchID)
+	}
+
+	var batchRoot BatchRoot
+	if err := json.Unmarshal(batchRootJSON, &batchRoot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal batch root: %v", err)
+	}
+
+	return &batchRoot, nil
+}
+
+// txTimestamp returns the proposal timestamp of the transaction. Unlike the
+// peer's clock it is the same on every endorser, so their write sets agree.
+func txTimestamp(ctx contractapi.TransactionContextInterface) (string, error) {
+	ts, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return "", fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC().Format(time.RFC3339), nil
+}
+
 // ComputeHash computes SHA256 hash of provided data
 func (s *LogHashContract) ComputeHash(ctx contractapi.TransactionContextInterface, data string) (string, error) {
 	hash := sha256.Sum256([]byte(data))
@@ -152,20 +152,26 @@ deploy_chaincode() {
     # Wait for peer to be ready
     sleep 10
     
+    # Bump CHAINCODE_VERSION whenever the chaincode changes; a network that
+    # already runs loghash is upgraded to the next definition sequence
+    CHAINCODE_VERSION=${CHAINCODE_VERSION:-1.2}
+    CURRENT_SEQUENCE=$(docker exec banking-audit-peer peer lifecycle chaincode querycommitted --channelID audit-channel --name loghash 2>/dev/null | grep -o "Sequence: [0-9]*" | cut -d' ' -f2)
+    SEQUENCE=$(( ${CURRENT_SEQUENCE:-0} + 1 ))
+    
     # Package chaincode
-    docker exec banking-audit-peer peer lifecycle chaincode package loghash.tar.gz --path /opt/gopath/src/github.com/hyperledger/fabric/peer/chaincode --lang golang --label loghash_1.0
+    docker exec banking-audit-peer peer lifecycle chaincode package loghash.tar.gz --path /opt/gopath/src/github.com/hyperledger/fabric/peer/chaincode --lang golang --label loghash_${CHAINCODE_VERSION}
     
     # Install chaincode
     docker exec banking-audit-peer peer lifecycle chaincode install loghash.tar.gz
     
     # Get package ID
-    PACKAGE_ID=$(docker exec banking-audit-peer peer lifecycle chaincode queryinstalled | grep -o "Package ID: [^,]*" | cut -d' ' -f3)
+    PACKAGE_ID=$(docker exec banking-audit-peer peer lifecycle chaincode queryinstalled | grep "Label: loghash_${CHAINCODE_VERSION}$" | grep -o "Package ID: [^,]*" | cut -d' ' -f3)
     
     # Approve chaincode
-    docker exec banking-audit-peer peer lifecycle chaincode approveformyorg -o orderer.bankingaudit.com:7050 --channelID audit-channel --name loghash --version 1.0 --package-id $PACKAGE_ID --sequence 1
+    docker exec banking-audit-peer peer lifecycle chaincode approveformyorg -o orderer.bankingaudit.com:7050 --channelID audit-channel --name loghash --version ${CHAINCODE_VERSION} --package-id $PACKAGE_ID --sequence ${SEQUENCE}
     
     # Commit chaincode
-    docker exec banking-audit-peer peer lifecycle chaincode commit -o orderer.bankingaudit.com:7050 --channelID audit-channel --name loghash --version 1.0 --sequence 1
+    docker exec banking-audit-peer peer lifecycle chaincode commit -o orderer.bankingaudit.com:7050 --channelID audit-channel --name loghash --version ${CHAINCODE_VERSION} --sequence ${SEQUENCE}
     
     print_status "Chaincode deployed successfully!"
 }